@@ -1,174 +1,403 @@
 package blocktree
 
 import (
-	"reflect"
+	"context"
+	"errors"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 )
 
-type blockServer struct {
-	api *Api
-	mu  sync.Mutex
+var (
+	_ Remote = (*Api)(nil)
+)
+
+// Remote is the server an Agent synchronizes with.
+type Remote interface {
+	// ApplyEach applies every transaction of the agent on its own and returns a result per transaction,
+	// the moves that create cycles are skipped.
+	ApplyEach(transactions ...*Transaction) ([]*TransactionResult, *SyncBlocks)
+	// GetNextTransactions returns the transactions after the given transaction ID,
+	// the transactions removed by a compaction get ErrSnapshotRequired.
+	GetNextTransactions(spaceID SpaceID, txID TransactionID) ([]*Transaction, error)
+	// Snapshot returns the tree of the space after its latest transaction.
+	Snapshot(spaceID SpaceID) (*Snapshot, error)
+}
+
+// Agent is an offline-first client of a space.
+// it keeps a local replica of the space, the local transactions are applied to the replica at once
+// and queued until the remote acknowledges them. on sync the agent pulls the remote transactions
+// after the last acknowledged one and rebases the pending transactions on top of them.
+//
+// the remote log decides the order of the transactions. the pending transactions are sent without
+// a clock, the remote stamps them in arrival order. a pending transaction is rebased with
+// the conflicts resolved the same way on every agent:
+//   - a move into a parent deleted on the remote is dropped, the block stays where it is
+//   - an insert of a block that already exists on the remote is dropped
+//   - a move that creates a cycle on the rebased replica is skipped
+//   - a transaction that fails on the rebased replica is dropped
+//   - a transaction the remote rejects for good, e.g. with a conflict or a schema violation, is dropped
+//     and its result is kept for Rejected
+//
+// a transaction without ops left is dropped as a whole. a transaction that fails on the remote
+// for another reason stays queued with the pending transactions after it, they may depend on it.
+type Agent struct {
+	mu      sync.Mutex
+	userID  uuid.UUID
+	spaceID SpaceID
+	remote  Remote
+	// base is the replica of the acknowledged remote transactions,
+	// baseApi applies the remote transactions to it with the remote clocks
+	base    *MemStore
+	baseApi *Api
+	// store is the base replica with the pending transactions applied on top
+	store *MemStore
+	api   *Api
+	// acked is the last remote transaction applied to the base replica
+	acked   TransactionID
+	pending []*Transaction
+	// rejected are the results of the pending transactions the remote rejected
+	rejected []*TransactionResult
+	// loaded is set once the base replica is loaded from a remote snapshot
+	loaded bool
 }
 
-// newBlockServer creates a new block server with a space.
-func newBlockServer(spaceID SpaceID) (*blockServer, error) {
-	store := NewMemStore()
-	api := NewApi(store)
-	err := api.CreateSpace(spaceID, spaceID.String())
+// NewAgent creates an agent of the user for the space.
+// the replica starts as an empty space, the first sync loads the space from the remote.
+func NewAgent(userID uuid.UUID, spaceID SpaceID, remote Remote) (*Agent, error) {
+	base := NewMemStore()
+	err := base.CreateSpace(&Space{ID: spaceID, Name: spaceID.String()})
 	if err != nil {
 		return nil, err
 	}
-	return &blockServer{
-		api: api,
-	}, nil
-}
 
-// sync synchronizes the agent with the server.
-func (s *blockServer) sync(agent *blockAgent) {
-	if len(agent.applied) == 0 {
-		return
+	agent := &Agent{
+		userID:  userID,
+		spaceID: spaceID,
+		remote:  remote,
+		base:    base,
+		baseApi: NewApi(base),
+		acked:   uuid.Nil,
+	}
+	err = agent.rebase()
+	if err != nil {
+		return nil, err
 	}
-	s.mu.Lock()
-	defer s.mu.Unlock()
 
-	// find last transaction applied by the agent
-	lastTx := agent.applied[len(agent.applied)-1]
-	page := 0
-	pageSize := 10
-	changes := NewSyncBlocks()
-	for {
-		transactions, err := s.api.store.GetNextTransactions(&agent.spaceID, lastTx.ID, page, pageSize)
-		if err != nil {
-			return
+	return agent, nil
+}
+
+// Apply applies the transactions to the local replica and queues them for the remote.
+// the transactions are applied one by one, the first failing transaction stops the apply.
+func (a *Agent) Apply(transactions ...*Transaction) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, tx := range transactions {
+		if tx.SpaceID != a.spaceID {
+			return fmt.Errorf("transaction %v is not in space %v", tx.ID, a.spaceID)
+		}
+		if tx.UserID == uuid.Nil {
+			tx.UserID = a.userID
 		}
 
-		// if no more transactions, break
-		if len(transactions) == 0 {
-			break
+		err := a.applyLocal(tx)
+		if err != nil {
+			return err
 		}
 
-		// merge changes from all transactions
-		for _, tx := range transactions {
-			changes.extend(tx.changes)
+		a.pending = append(a.pending, tx)
+	}
+
+	return nil
+}
+
+// Sync pulls the remote transactions, rebases the pending transactions and pushes them to the remote.
+// the pending transactions stay queued when the remote is not reachable, the error of the first
+// transaction that is kept queued is returned.
+func (a *Agent) Sync() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	err := a.pull()
+	if err != nil {
+		return err
+	}
+
+	if len(a.pending) == 0 {
+		return nil
+	}
+
+	// the pull brings back the applied transactions, the failed ones are dropped or kept for the next sync
+	results, _ := a.remote.ApplyEach(a.pending...)
+	var failed error
+	pending := make([]*Transaction, 0, len(a.pending))
+	for i, result := range results {
+		switch {
+		case result.Success():
+		case failed == nil && result.Rejected():
+			logrus.Warnf("dropped transaction %v of agent %v, rejected by the remote: %v", result.TransactionID, a.userID, result.Err)
+			a.rejected = append(a.rejected, result)
+		default:
+			if failed == nil {
+				failed = result.Err
+			}
+			pending = append(pending, a.pending[i])
 		}
+	}
+	a.pending = pending
 
-		page++
+	err = a.pull()
+	if err != nil {
+		return err
 	}
-	// merge block changes from all transactions applied by the agent
+
+	return failed
 }
 
-// apply applies transactions to the server.
-func (s *blockServer) apply(tx ...*Transaction) (*SyncBlocks, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// Start syncs the agent with the remote every interval until the context is done.
+func (a *Agent) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
 
-	return s.api.Apply(tx...)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := a.Sync()
+				if err != nil {
+					logrus.Warnf("failed to sync agent %v of space %v: %v", a.userID, a.spaceID, err)
+				}
+			}
+		}
+	}()
 }
 
-type blockAgent struct {
-	id       uuid.UUID
-	spaceID  uuid.UUID
-	server   *blockServer
-	api      *Api
-	blocks   map[string]*Block
-	children map[string][]string
-	applied  []*Transaction
+// Store returns the local replica with the pending transactions applied.
+func (a *Agent) Store() *MemStore {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.store
 }
 
-func newBlockAgent(id uuid.UUID, spaceID SpaceID, store Store, server *blockServer) *blockAgent {
-	api := NewApi(NewMemStore())
-	err := api.CreateSpace(spaceID, spaceID.String())
+// Acked returns the last remote transaction applied to the replica.
+func (a *Agent) Acked() TransactionID {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.acked
+}
+
+// Pending returns the transactions not acknowledged by the remote yet.
+func (a *Agent) Pending() []*Transaction {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pending := make([]*Transaction, len(a.pending))
+	copy(pending, a.pending)
+	return pending
+}
+
+// Rejected returns the results of the pending transactions the remote rejected since the last call,
+// with the ones that fail on top of the remote transactions when they are rebased, e.g. on a conflict
+// with their precondition. the rejected transactions are dropped from the replica.
+func (a *Agent) Rejected() []*TransactionResult {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	rejected := a.rejected
+	a.rejected = nil
+	return rejected
+}
+
+// GetBlock returns the block with the given ID from the local replica.
+func (a *Agent) GetBlock(blockID BlockID) (*Block, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.api.GetBlock(a.spaceID, blockID)
+}
+
+// GetChildrenBlocks returns the children blocks of the block from the local replica.
+func (a *Agent) GetChildrenBlocks(blockID BlockID) ([]*Block, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.api.GetChildrenBlocks(a.spaceID, blockID)
+}
+
+// GetDescendantBlocks returns the descendant blocks of the block from the local replica.
+func (a *Agent) GetDescendantBlocks(blockID BlockID) ([]*Block, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.api.GetDescendantBlocks(a.spaceID, blockID)
+}
+
+// pull applies the remote transactions after the acknowledged one to the base replica
+// and rebases the pending transactions on top.
+func (a *Agent) pull() error {
+	if !a.loaded {
+		err := a.load()
+		if err != nil {
+			return err
+		}
+	}
+
+	txs, err := a.remote.GetNextTransactions(a.spaceID, a.acked)
+	if errors.Is(err, ErrSnapshotRequired) {
+		err = a.load()
+		if err != nil {
+			return err
+		}
+		txs, err = a.remote.GetNextTransactions(a.spaceID, a.acked)
+	}
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	return &blockAgent{
-		id:       id,
-		spaceID:  spaceID,
-		api:      api,
-		server:   server,
-		blocks:   make(map[string]*Block),
-		children: make(map[string][]string),
-		applied:  make([]*Transaction, 0),
+	if len(txs) > 0 {
+		_, err = a.baseApi.Apply(txs...)
+		if err != nil {
+			return err
+		}
+		a.acked = txs[len(txs)-1].ID
+
+		acked := NewSet[TransactionID]()
+		for _, tx := range txs {
+			acked.Add(tx.ID)
+		}
+		pending := make([]*Transaction, 0, len(a.pending))
+		for _, tx := range a.pending {
+			if !acked.Contains(tx.ID) {
+				pending = append(pending, tx)
+			}
+		}
+		a.pending = pending
 	}
+
+	return a.rebase()
 }
 
-func (a *blockAgent) apply(tx ...*Transaction) error {
-	_, err := a.api.Apply(tx...)
+// load replaces the base replica with the remote snapshot of the space.
+func (a *Agent) load() error {
+	snapshot, err := a.remote.Snapshot(a.spaceID)
 	if err != nil {
 		return err
 	}
 
-	a.applied = append(a.applied, tx...)
+	base := NewMemStore()
+	base.restoreSnapshot(snapshot)
+	a.base = base
+	a.baseApi = NewApi(base)
+	a.acked = snapshot.TransactionID
+	a.loaded = true
 
 	return nil
 }
 
-// sync synchronizes the server with the agent.
-func (a *blockAgent) sync(server *blockServer) error {
-	applied := a.applied
-	for _, tx := range a.applied {
-		_, err := server.apply(tx)
-		if err != nil {
-			return err
+// rebase rebuilds the local replica from the base replica and applies the pending transactions on top.
+func (a *Agent) rebase() error {
+	latest, err := a.base.GetLatestTransaction(&a.spaceID)
+	if err != nil {
+		return err
+	}
+
+	a.store = NewMemStore()
+	a.store.restoreSnapshot(a.base.snapshot(a.spaceID, latest))
+	a.api = NewApi(a.store)
+
+	pending := make([]*Transaction, 0, len(a.pending))
+	for _, tx := range a.pending {
+		resolved := a.resolve(tx)
+		if resolved == nil {
+			logrus.Debugf("dropped transaction %v of agent %v, no ops left after rebase", tx.ID, a.userID)
+			continue
 		}
 
-		applied = applied[1:]
+		err := a.applyLocal(resolved)
+		if err != nil {
+			logrus.Debugf("dropped transaction %v of agent %v, cannot rebase: %v", tx.ID, a.userID, err)
+			a.rejected = append(a.rejected, newTransactionResult(tx, nil, err))
+			continue
+		}
+		pending = append(pending, resolved)
 	}
-
-	a.applied = applied
+	a.pending = pending
 
 	return nil
 }
 
-func (a *blockAgent) start() {
-}
+// resolve returns the transaction without the ops that conflict with the base replica, nil if no op is left.
+func (a *Agent) resolve(tx *Transaction) *Transaction {
+	ops := make([]Op, 0, len(tx.Ops))
+	for _, op := range tx.Ops {
+		switch op.Type {
+		case OpTypeInsert:
+			if _, err := a.base.GetBlock(&a.spaceID, op.BlockID); err == nil {
+				continue
+			}
+		case OpTypeMove:
+			if a.movesIntoDeleted(&op) {
+				continue
+			}
+		}
+		ops = append(ops, op)
+	}
 
-func (a *blockAgent) stop() {
-}
+	if len(ops) == 0 {
+		return nil
+	}
 
-func (a *blockAgent) equalState(other *blockAgent) bool {
-	if a.spaceID != other.spaceID {
-		return false
+	return &Transaction{
+		ID:           tx.ID,
+		SpaceID:      tx.SpaceID,
+		UserID:       tx.UserID,
+		Time:         tx.Time,
+		Ops:          ops,
+		Precondition: tx.Precondition,
 	}
+}
 
-	// compare blocks
-	if len(a.blocks) != len(other.blocks) {
+// movesIntoDeleted returns true if the new parent of the moved block is deleted or erased in the base replica.
+// a parent that is not in the base replica yet is inserted by a pending transaction.
+func (a *Agent) movesIntoDeleted(op *Op) bool {
+	if op.At == nil {
 		return false
 	}
 
-	for id, block := range a.blocks {
-		otherBlock, ok := other.blocks[id]
-		if !ok || !reflect.DeepEqual(block, otherBlock) {
+	parentID := op.At.BlockID
+	if op.At.Position == PositionBefore || op.At.Position == PositionAfter {
+		parent, err := a.base.GetParentBlock(&a.spaceID, op.At.BlockID)
+		if err != nil {
 			return false
 		}
+		parentID = parent.ID
 	}
 
-	// compare children
-	if len(a.children) != len(other.children) {
-		return false
-	}
-
-	for id, children := range a.children {
-		otherChildren, ok := other.children[id]
-		if !ok || !reflect.DeepEqual(children, otherChildren) {
+	// a block under a deleted ancestor is deleted with it
+	for parentID != a.spaceID {
+		parent, err := a.base.GetBlock(&a.spaceID, parentID)
+		if err != nil {
 			return false
 		}
+		if parent.Deleted || parent.Erased {
+			return true
+		}
+		parentID = parent.ParentID
 	}
 
-	return true
+	return false
 }
 
-// agents updates the local state separately and tries to reach consensus
-// by comparing the local state with the state of other agents.
-func simulateAgents(agents []*blockAgent, ops *Set[OpType]) {
-	//for _, agent := range agents {
-	//	agent.start()
-	//}
-	//
-	//for _, agent := range agents {
-	//	agent.stop()
-	//}
+// applyLocal applies the transaction to the local replica.
+func (a *Agent) applyLocal(tx *Transaction) error {
+	_, err := a.api.Apply(tx)
+	return err
 }
@@ -1,6 +1,11 @@
 package blocktree
 
 import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -8,100 +13,453 @@ import (
 	"github.com/google/uuid"
 )
 
-func TestAgentsInsert(t *testing.T) {
-	// create a block server
-	server, _ := newBlockServer(s1)
+var errOffline = errors.New("remote is offline")
 
-	aid1 := uuid.New()
-	aid2 := uuid.New()
+// offlineRemote is a remote that can be taken offline
+type offlineRemote struct {
+	Remote
+	offline bool
+}
 
-	// create two agents
-	agents := []*blockAgent{
-		newBlockAgent(aid1, s1, NewMemStore(), server),
-		newBlockAgent(aid2, s1, NewMemStore(), server),
+func (r *offlineRemote) ApplyEach(transactions ...*Transaction) ([]*TransactionResult, *SyncBlocks) {
+	if r.offline {
+		results := make([]*TransactionResult, 0, len(transactions))
+		for _, tx := range transactions {
+			results = append(results, newTransactionResult(tx, nil, errOffline))
+		}
+		return results, NewSyncBlocks()
 	}
+	return r.Remote.ApplyEach(transactions...)
+}
 
-	// start agents and simulate insert operations
-	simulateAgents(agents, NewSet[OpType](OpTypeInsert))
+func (r *offlineRemote) GetNextTransactions(spaceID SpaceID, txID TransactionID) ([]*Transaction, error) {
+	if r.offline {
+		return nil, errOffline
+	}
+	return r.Remote.GetNextTransactions(spaceID, txID)
+}
 
-	// check if all agents have the same block tree
-	for i := 0; i < len(agents); i++ {
-		for j := 0; j < len(agents); j++ {
-			assert.Equal(t, agents[i].equalState(agents[j]), true)
-		}
+func (r *offlineRemote) Snapshot(spaceID SpaceID) (*Snapshot, error) {
+	if r.offline {
+		return nil, errOffline
 	}
+	return r.Remote.Snapshot(spaceID)
 }
 
-func TestSyncAgentWithServer(t *testing.T) {
-	var block *Block
-	var err error
-	// create a block server
-	server, _ := newBlockServer(s1)
+func newTestServer(t *testing.T) (*Api, *MemStore) {
+	store := NewMemStore()
+	api := NewApi(store)
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+	return api, store
+}
 
-	aid1 := uuid.New()
+func newTestAgent(t *testing.T, remote Remote) *Agent {
+	agent, err := NewAgent(uuid.New(), s1, remote)
+	assert.NoError(t, err)
+	return agent
+}
 
-	a1 := newBlockAgent(aid1, s1, NewMemStore(), server)
+func agentChildIDs(t *testing.T, agent *Agent, parentID uuid.UUID) []uuid.UUID {
+	blocks, err := agent.GetChildrenBlocks(parentID)
+	assert.NoError(t, err)
+	return blockIDs(blocks)
+}
 
-	a1tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionStart))
+func TestAgent_Sync(t *testing.T) {
+	server, store := newTestServer(t)
+	a1 := newTestAgent(t, server)
+	a2 := newTestAgent(t, server)
 
-	err = a1.apply(a1tx1)
+	tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionStart))
+	err := a1.Apply(tx1)
 	assert.NoError(t, err)
 
-	block, err = a1.api.GetBlock(s1, b1)
+	block, err := a1.GetBlock(b1)
 	assert.NoError(t, err)
-	assert.Equal(t, block.ID, b1)
+	assert.Equal(t, b1, block.ID)
+	assert.Len(t, a1.Pending(), 1)
 
-	//a1.api.store.(*MemStore).Print(&s1)
+	err = a1.Sync()
+	assert.NoError(t, err)
+	assert.Empty(t, a1.Pending())
+	assert.Equal(t, tx1.ID, a1.Acked())
+	assert.True(t, a1.Store().Equals(store))
 
-	err = a1.sync(server)
+	err = a2.Sync()
 	assert.NoError(t, err)
-	assert.True(t, a1.api.store.(*MemStore).Equals(server.api.store.(*MemStore)))
+	assert.Equal(t, []uuid.UUID{b1}, agentChildIDs(t, a2, s1))
+	assert.True(t, a2.Store().Equals(store))
 }
 
-func TestSyncAgentWithServerWithConflict(t *testing.T) {
-	var block *Block
-	var err error
-	// create a block server
-	server, _ := newBlockServer(s1)
+func TestAgent_Offline(t *testing.T) {
+	server, store := newTestServer(t)
+	remote := &offlineRemote{Remote: server, offline: true}
+	a1 := newTestAgent(t, remote)
 
-	aid1 := uuid.New()
-	a1 := newBlockAgent(aid1, s1, NewMemStore(), server)
+	tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionEnd))
+	tx2 := createTx(s1, insertOp(b2, "p2", b1, PositionEnd))
+	err := a1.Apply(tx1, tx2)
+	assert.NoError(t, err)
 
-	a1tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionStart))
+	err = a1.Sync()
+	assert.ErrorIs(t, err, errOffline)
+	assert.Len(t, a1.Pending(), 2)
+	assert.Equal(t, []uuid.UUID{b2}, agentChildIDs(t, a1, b1))
 
-	err = a1.apply(a1tx1)
+	remote.offline = false
+	err = a1.Sync()
 	assert.NoError(t, err)
+	assert.Empty(t, a1.Pending())
+	assert.Equal(t, tx2.ID, a1.Acked())
+	assert.True(t, a1.Store().Equals(store))
+}
 
-	block, err = a1.api.GetBlock(s1, b1)
+func TestAgent_Rejected(t *testing.T) {
+	server, store := newTestServer(t)
+	schemas := NewSchemaRegistry()
+	err := schemas.Register(&BlockSchema{Type: "space", Children: []string{"p1"}})
+	assert.NoError(t, err)
+	server.SetSchemas(schemas)
+	a1 := newTestAgent(t, server)
+
+	// the replica has no schemas, the remote rejects the second transaction for good
+	tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionEnd))
+	tx2 := createTx(s1, insertOp(b2, "p2", s1, PositionEnd))
+	tx3 := createTx(s1, insertOp(b3, "p1", s1, PositionEnd))
+	err = a1.Apply(tx1, tx2, tx3)
 	assert.NoError(t, err)
-	assert.Equal(t, block.ID, b1)
 
-	//a1.api.store.(*MemStore).Print(&s1)
+	err = a1.Sync()
+	assert.NoError(t, err)
+	assert.Empty(t, a1.Pending())
+	assert.Equal(t, tx3.ID, a1.Acked())
+	assert.Equal(t, []uuid.UUID{b1, b3}, agentChildIDs(t, a1, s1))
+	assert.True(t, a1.Store().Equals(store))
+
+	rejected := a1.Rejected()
+	if assert.Len(t, rejected, 1) {
+		assert.Equal(t, tx2.ID, rejected[0].TransactionID)
+		assert.Equal(t, ResultSchemaViolation, rejected[0].Code)
+	}
+	assert.Empty(t, a1.Rejected())
+}
 
-	// create a new agent
-	aid2 := uuid.New()
-	a2 := newBlockAgent(aid2, s1, NewMemStore(), server)
+func TestAgent_Rebase(t *testing.T) {
+	server, store := newTestServer(t)
+	a1 := newTestAgent(t, server)
+	a2 := newTestAgent(t, server)
 
-	a2tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionEnd))
+	// both agents insert at the end of the space while the other is not synced
+	err := a1.Apply(createTx(s1, insertOp(b1, "p1", s1, PositionEnd)))
+	assert.NoError(t, err)
+	err = a2.Apply(createTx(s1, insertOp(b2, "p2", s1, PositionEnd)), createTx(s1, insertOp(b3, "p3", b2, PositionEnd)))
+	assert.NoError(t, err)
 
-	err = a2.apply(a2tx1)
+	err = a1.Sync()
 	assert.NoError(t, err)
 
-	block, err = a2.api.GetBlock(s1, b1)
+	// the pending transactions of a2 are rebased after the transaction of a1
+	err = a2.Sync()
 	assert.NoError(t, err)
-	assert.Equal(t, block.ID, b1)
+	assert.Equal(t, []uuid.UUID{b1, b2}, agentChildIDs(t, a2, s1))
+	assert.Equal(t, []uuid.UUID{b3}, agentChildIDs(t, a2, b2))
 
-	//a2.api.store.(*MemStore).Print(&s1)
+	err = a1.Sync()
+	assert.NoError(t, err)
+	assert.True(t, a1.Store().Equals(store))
+	assert.True(t, a2.Store().Equals(store))
+}
 
-	err = a1.sync(server)
+func TestAgent_RebasePrecondition(t *testing.T) {
+	server, _ := newTestServer(t)
+	_, err := server.Apply(createTx(s1, insertOp(b1, "p1", s1, PositionEnd)))
 	assert.NoError(t, err)
+	a1 := newTestAgent(t, server)
+	a2 := newTestAgent(t, server)
+	assert.NoError(t, a1.Sync())
+	assert.NoError(t, a2.Sync())
 
-	assert.True(t, a1.api.store.(*MemStore).Equals(server.api.store.(*MemStore)))
+	versions, err := server.GetBlockVersions(s1, b1)
+	assert.NoError(t, err)
 
-	err = a2.sync(server)
+	// both agents update b1 from the same version, the rebased update keeps its precondition
+	err = a1.Apply(createTx(s1, updateOp(b1, []byte(`[{"op":"add","path":"/name","value":"a1"}]`))))
+	assert.NoError(t, err)
+	tx := createTx(s1, updateOp(b1, []byte(`[{"op":"add","path":"/name","value":"a2"}]`)))
+	tx.Precondition = &Precondition{Versions: versions}
+	err = a2.Apply(tx)
 	assert.NoError(t, err)
 
-	server.sync(a2)
+	assert.NoError(t, a1.Sync())
+	assert.NoError(t, a2.Sync())
+	assert.Empty(t, a2.Pending())
+	rejected := a2.Rejected()
+	if assert.Len(t, rejected, 1) {
+		assert.Equal(t, tx.ID, rejected[0].TransactionID)
+		assert.Equal(t, ResultConflict, rejected[0].Code)
+	}
+}
+
+func TestAgent_MoveIntoDeletedParent(t *testing.T) {
+	server, store := newTestServer(t)
+	_, err := server.Apply(createTx(s1,
+		insertOp(b1, "p1", s1, PositionEnd),
+		insertOp(b2, "p2", s1, PositionEnd),
+		insertOp(b3, "p3", s1, PositionEnd),
+	))
+	assert.NoError(t, err)
+
+	a1 := newTestAgent(t, server)
+	a2 := newTestAgent(t, server)
+	assert.NoError(t, a1.Sync())
+	assert.NoError(t, a2.Sync())
+
+	err = a1.Apply(createTx(s1, deleteOp(b1)))
+	assert.NoError(t, err)
+	err = a2.Apply(
+		createTx(s1, moveOp(b2, s1, b1, PositionEnd), updateOp(b2, []byte(`[{"op":"add","path":"/name","value":"John Doe"}]`))),
+		createTx(s1, moveOp(b3, s1, b1, PositionStart)),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{b3, b2}, agentChildIDs(t, a2, b1))
+
+	assert.NoError(t, a1.Sync())
+	assert.NoError(t, a2.Sync())
+
+	// the moves are dropped, the update of the same transaction is kept
+	assert.Equal(t, []uuid.UUID{b1, b2, b3}, agentChildIDs(t, a2, s1))
+	assert.Empty(t, agentChildIDs(t, a2, b1))
+	block, err := a2.GetBlock(b2)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"John Doe"}`, block.Props.String())
+
+	assert.NoError(t, a1.Sync())
+	assert.True(t, a1.Store().Equals(store))
+	assert.True(t, a2.Store().Equals(store))
+}
+
+func TestAgent_SnapshotRequired(t *testing.T) {
+	server, store := newTestServer(t)
+	a1 := newTestAgent(t, server)
+	assert.NoError(t, a1.Sync())
+
+	_, err := server.Apply(
+		createTx(s1, insertOp(b1, "p1", s1, PositionEnd)),
+		createTx(s1, insertOp(b2, "p2", s1, PositionEnd)),
+	)
+	assert.NoError(t, err)
+	_, err = server.Compact(s1)
+	assert.NoError(t, err)
+
+	err = a1.Apply(createTx(s1, insertOp(b3, "p3", s1, PositionStart)))
+	assert.NoError(t, err)
+
+	// the acked transaction of a1 is compacted, the agent loads the snapshot again
+	err = a1.Sync()
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{b3, b1, b2}, agentChildIDs(t, a1, s1))
+	assert.True(t, a1.Store().Equals(store))
+}
+
+// simulation runs random transactions of the agents and interleaves their syncs through the server.
+// every random choice comes from the seed, the same seed generates the same transactions.
+type simulation struct {
+	t       *testing.T
+	rng     *rand.Rand
+	server  *Api
+	store   *MemStore
+	remotes []*offlineRemote
+	agents  []*Agent
+}
+
+func newSimulation(t *testing.T, seed int64, agents int, jitter bool) *simulation {
+	server, store := newTestServer(t)
+	server.JitterIndices(jitter)
+	sim := &simulation{
+		t:      t,
+		rng:    rand.New(rand.NewSource(seed)),
+		server: server,
+		store:  store,
+	}
+
+	for i := 0; i < agents; i++ {
+		remote := &offlineRemote{Remote: server}
+		agent, err := NewAgent(sim.uuid(), s1, remote)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sim.remotes = append(sim.remotes, remote)
+		sim.agents = append(sim.agents, agent)
+	}
+
+	return sim
+}
+
+// simulateAgents runs the steps of the simulation, then syncs all the agents
+// and checks that every replica is the same as the server.
+func simulateAgents(t *testing.T, seed int64, agents, steps int, jitter bool) {
+	sim := newSimulation(t, seed, agents, jitter)
+
+	for step := 0; step < steps; step++ {
+		i := sim.rng.Intn(len(sim.agents))
+		switch n := sim.rng.Intn(10); {
+		case n < 6:
+			sim.apply(i)
+		case n < 9:
+			sim.sync(i)
+		default:
+			sim.remotes[i].offline = !sim.remotes[i].offline
+		}
+	}
+
+	// the first round pushes the pending transactions, the second round pulls them to every agent
+	for _, remote := range sim.remotes {
+		remote.offline = false
+	}
+	for round := 0; round < 2; round++ {
+		for i := range sim.agents {
+			sim.sync(i)
+		}
+	}
+
+	for i, agent := range sim.agents {
+		if !assert.Empty(t, agent.Pending(), "agent %d has pending transactions, seed %d", i, seed) {
+			return
+		}
+		if !assert.True(t, agent.Store().Equals(sim.store), "agent %d diverged from the server, seed %d", i, seed) {
+			agent.Store().Print(&s1)
+			sim.store.Print(&s1)
+			return
+		}
+	}
+}
+
+func (s *simulation) uuid() uuid.UUID {
+	id, err := uuid.NewRandomFromReader(s.rng)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return id
+}
 
-	assert.True(t, a2.api.store.(*MemStore).Equals(a1.api.store.(*MemStore)))
+func (s *simulation) sync(i int) {
+	err := s.agents[i].Sync()
+	if err != nil && !errors.Is(err, errOffline) {
+		s.t.Fatalf("agent %d failed to sync: %v", i, err)
+	}
+}
+
+// apply applies a transaction of one to three random ops to the agent.
+func (s *simulation) apply(i int) {
+	agent := s.agents[i]
+	blocks, err := agent.GetDescendantBlocks(s1)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+
+	tx := &Transaction{ID: s.uuid(), SpaceID: s1}
+	for n := s.rng.Intn(3); n >= 0; n-- {
+		op, ok := s.randomOp(blocks)
+		if ok {
+			tx.Ops = append(tx.Ops, op)
+		}
+	}
+	if len(tx.Ops) == 0 {
+		return
+	}
+
+	// the moves that create a cycle are skipped, the rest of the transaction is applied
+	err = agent.Apply(tx)
+	if err != nil {
+		s.t.Fatalf("agent %d failed to apply %v: %v", i, tx.Ops, err)
+	}
+}
+
+// randomOp returns a random op on the blocks, the first block is the space.
+// the ops of a transaction are generated from the blocks before the transaction.
+func (s *simulation) randomOp(blocks []*Block) (Op, bool) {
+	pick := func() *Block {
+		return blocks[s.rng.Intn(len(blocks))]
+	}
+	// pickChild returns a block other than the space, the linked blocks can not be moved or used as a sibling
+	pickChild := func() (*Block, bool) {
+		if len(blocks) < 2 {
+			return nil, false
+		}
+		block := blocks[1+s.rng.Intn(len(blocks)-1)]
+		return block, !block.Linked
+	}
+
+	switch s.rng.Intn(6) {
+	case 0:
+		if sibling, ok := pickChild(); ok && s.rng.Intn(2) == 0 {
+			pos := []PointerPosition{PositionBefore, PositionAfter}[s.rng.Intn(2)]
+			return insertOp(s.uuid(), "text", sibling.ID, pos), true
+		}
+		pos := []PointerPosition{PositionStart, PositionEnd}[s.rng.Intn(2)]
+		return insertOp(s.uuid(), "text", pick().ID, pos), true
+	case 1:
+		block, ok := pickChild()
+		if !ok {
+			return Op{}, false
+		}
+		target := pick()
+		if target.ID == block.ID || target.Linked {
+			return Op{}, false
+		}
+		pos := []PointerPosition{PositionStart, PositionEnd}[s.rng.Intn(2)]
+		if target.ID != s1 && s.rng.Intn(2) == 0 {
+			pos = []PointerPosition{PositionBefore, PositionAfter}[s.rng.Intn(2)]
+		}
+		return moveOp(block.ID, block.ParentID, target.ID, pos), true
+	case 2:
+		// the space can not have linked blocks
+		block, ok := pickChild()
+		if !ok {
+			return Op{}, false
+		}
+		return linkInsertOp(s.uuid(), "link", block.ID), true
+	case 3:
+		block, ok := pickChild()
+		if !ok {
+			return Op{}, false
+		}
+		return deleteOp(block.ID), true
+	case 4:
+		block, ok := pickChild()
+		if !ok {
+			return Op{}, false
+		}
+		return patchOp(block.ID, []byte(fmt.Sprintf(`[{"op":"add","path":"/text","value":"%d"}]`, s.rng.Int()))), true
+	default:
+		block, ok := pickChild()
+		if !ok {
+			return Op{}, false
+		}
+		return updateOp(block.ID, []byte(fmt.Sprintf(`[{"op":"add","path":"/name","value":"%d"}]`, s.rng.Int()))), true
+	}
+}
+
+// TestAgent_Converge runs the simulation with a fixed set of seeds,
+// BLOCKTREE_TEST_SEED runs the simulation with a single seed to reproduce a failure.
+func TestAgent_Converge(t *testing.T) {
+	seeds := []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if value := os.Getenv("BLOCKTREE_TEST_SEED"); value != "" {
+		seed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			t.Fatalf("invalid BLOCKTREE_TEST_SEED: %v", err)
+		}
+		seeds = []int64{seed}
+	}
+
+	for _, seed := range seeds {
+		t.Run(fmt.Sprintf("seed-%d", seed), func(t *testing.T) {
+			simulateAgents(t, seed, 4, 200, false)
+		})
+		// the jittered indices of the server are kept with its transactions, the replicas place the blocks the same way
+		t.Run(fmt.Sprintf("jitter/seed-%d", seed), func(t *testing.T) {
+			simulateAgents(t, seed, 4, 200, true)
+		})
+	}
 }
@@ -1,65 +1,166 @@
 package blocktree
 
 import (
-	"errors"
+	"context"
+	"fmt"
 	"sort"
+
+	"github.com/sirupsen/logrus"
 )
 
 type Api struct {
-	store     Store
-	publisher PublishSyncBlocks
+	store       Store
+	publisher   PublishSyncBlocks
+	subscriber  SubscribeSyncBlocks
+	history     *history
+	compaction  *compaction
+	moves       *moveLog
+	schemas     *SchemaRegistry
+	rebalancing *rebalancing
+	jitter      bool
 }
 
 func NewApi(store Store) *Api {
 	return &Api{
-		store:     store,
-		publisher: NewNullPublisher(),
+		store:       store,
+		publisher:   NewNullPublisher(),
+		history:     newHistory(),
+		compaction:  newCompaction(),
+		moves:       newMoveLog(),
+		rebalancing: newRebalancing(),
 	}
 }
 
 func NewApiWithPublisher(store Store, publisher PublishSyncBlocks) *Api {
 	return &Api{
-		store:     store,
-		publisher: publisher,
+		store:       store,
+		publisher:   publisher,
+		history:     newHistory(),
+		compaction:  newCompaction(),
+		moves:       newMoveLog(),
+		rebalancing: newRebalancing(),
+	}
+}
+
+// NewApiWithBroker creates an api that publishes the changes to the broker and serves subscriptions from it.
+func NewApiWithBroker(store Store, broker *Broker) *Api {
+	return &Api{
+		store:       store,
+		publisher:   broker,
+		subscriber:  broker,
+		history:     newHistory(),
+		compaction:  newCompaction(),
+		moves:       newMoveLog(),
+		rebalancing: newRebalancing(),
 	}
 }
 
+// NewApiWithPubSub creates an api that publishes the changes with the publisher and serves subscriptions from the subscriber.
+func NewApiWithPubSub(store Store, publisher PublishSyncBlocks, subscriber SubscribeSyncBlocks) *Api {
+	return &Api{
+		store:       store,
+		publisher:   publisher,
+		subscriber:  subscriber,
+		history:     newHistory(),
+		compaction:  newCompaction(),
+		moves:       newMoveLog(),
+		rebalancing: newRebalancing(),
+	}
+}
+
+// SetSchemas makes the api reject the transactions that break the block schemas, nil turns the check off.
+func (a *Api) SetSchemas(schemas *SchemaRegistry) {
+	a.schemas = schemas
+}
+
+// JitterIndices makes the api suffix the indices of the placed blocks with the end of the transaction id.
+// the blocks inserted at the same spot by concurrent transactions keep their order by the transaction ids
+// instead of getting the same index, the suffixed keys are longer. the suffix is logged with the transaction,
+// see Transaction.Jitter.
+func (a *Api) JitterIndices(on bool) {
+	a.jitter = on
+}
+
 // Apply applies the given transactions to the store.
+// the changes of each applied transaction are published as soon as the transaction is stored.
+// the transactions of a user are pushed to the undo stack of the user.
+// the first failing transaction stops the apply, the transactions before it stay applied.
+// a transaction with a precondition that does not hold fails with a ConflictError.
+// the moves that create a cycle are skipped without an error, the rest of the transaction is applied.
+// use ApplyEach to get the skipped ops in TransactionResult.Skipped.
 func (a *Api) Apply(transactions ...*Transaction) (*SyncBlocks, error) {
-	sb := NewSyncBlocks()
+	return a.apply(true, transactions...)
+}
 
-	// TODO: each transaction should be a db transaction
-	// translation failure should break the loop
-	// the client should retry the un-applied transactions
+// ApplyEach applies every transaction on its own and returns a result per transaction.
+// a failing transaction does not stop the transactions after it, the client retries the failed transactions.
+func (a *Api) ApplyEach(transactions ...*Transaction) ([]*TransactionResult, *SyncBlocks) {
+	sb := NewSyncBlocks()
+	results := make([]*TransactionResult, 0, len(transactions))
 	for _, tx := range transactions {
-		change, err := tx.prepare(a.store)
-		if err != nil {
-			if errors.Is(err, ErrDetectedCycle) || errors.Is(err, ErrCreatesCycle) {
-				continue
-			}
+		change, changes, err := a.applyOne(true, tx)
+		if changes != nil {
+			sb.extend(changes)
+		}
+		results = append(results, newTransactionResult(tx, change, err))
+	}
 
-			err2 := a.publisher.Publish(sb)
-			if err2 != nil {
-				return nil, errors.Join(err, ErrFailedToPublish)
-			}
+	return results, sb
+}
 
+func (a *Api) apply(record bool, transactions ...*Transaction) (*SyncBlocks, error) {
+	sb := NewSyncBlocks()
+	for _, tx := range transactions {
+		_, changes, err := a.applyOne(record, tx)
+		if err != nil {
 			return nil, err
 		}
+		sb.extend(changes)
+	}
+
+	return sb, nil
+}
 
-		err = a.store.Apply(tx, change)
+// applyOne applies the transaction and publishes its changes.
+// the publish failure is logged, the transaction is stored and the subscribers catch up from the log.
+func (a *Api) applyOne(record bool, tx *Transaction) (*storeChange, *SyncBlocks, error) {
+	// the moves that create a cycle are skipped, the transaction is stored with the rest of its ops
+	change, changes, err := a.applyOrdered(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// already applied transactions have no inverse ops
+	if record && len(change.blockChange.inverse) > 0 {
+		a.history.record(tx)
+	}
+
+	// the compaction failure leaves the log longer, the transaction is applied anyway
+	if len(change.blockChange.inverse) > 0 && a.compaction.applied(tx.SpaceID) {
+		_, err = a.Compact(tx.SpaceID)
 		if err != nil {
-			return nil, err
+			logrus.Errorf("failed to compact space %v: %v", tx.SpaceID, err)
 		}
+	}
 
-		sb.extend(change.intoSyncBlocks())
+	// the rebalancing failure leaves the keys long, the transaction is applied anyway
+	if due := a.rebalancing.placed(tx.SpaceID, change.blockChange); len(due) > 0 && !tx.reindexes() {
+		a.rebalance(tx.SpaceID, due)
 	}
 
-	err := a.publisher.Publish(sb)
+	// already applied transactions have nothing to publish
+	if changes.IsEmpty() {
+		return change, changes, nil
+	}
+
+	changes.spaceID = tx.SpaceID
+	changes.transactionID = tx.ID
+	err = a.publisher.Publish(changes)
 	if err != nil {
-		return nil, ErrFailedToPublish
+		logrus.Errorf("%v of transaction %v: %v", ErrFailedToPublish, tx.ID, err)
 	}
 
-	return sb, nil
+	return change, changes, nil
 }
 
 // CreateSpace creates a new space with the given ID and name.
@@ -99,6 +200,11 @@ func (a *Api) GetChildrenBlocks(spaceID, blockID BlockID) ([]*Block, error) {
 	return blocks, err
 }
 
+// QueryChildrenBlocks returns a page of the children blocks of the block with the given ID in index order.
+func (a *Api) QueryChildrenBlocks(spaceID, blockID BlockID, query *BlockQuery) (*BlockPage, error) {
+	return a.store.QueryChildrenBlocks(&spaceID, blockID, query)
+}
+
 // GetLinkedBlocks returns the linked block of the block with the given ID
 func (a *Api) GetLinkedBlocks(spaceID, blockID BlockID) ([]*Block, error) {
 	return a.store.GetLinkedBlocks(&spaceID, blockID)
@@ -115,11 +221,146 @@ func (a *Api) GetDescendantBlocks(spaceID, blockID BlockID) ([]*Block, error) {
 	return a.store.GetDescendantBlocks(&spaceID, blockID)
 }
 
+// QueryDescendantBlocks returns a page of the block and its descendant blocks in depth first order.
+// The next page starts at the Next cursor of the page, the query depth limits how far down the blocks go.
+func (a *Api) QueryDescendantBlocks(spaceID, blockID BlockID, query *BlockQuery) (*BlockPage, error) {
+	return a.store.QueryDescendantBlocks(&spaceID, blockID, query)
+}
+
 // GetUpdates returns the updates since the given transaction ID.
 func (a *Api) GetUpdates(spaceID SpaceID, txID TransactionID) (*BlockUpdates, error) {
+	txs, err := a.GetNextTransactions(spaceID, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(txs) == 0 {
+		var seq uint64
+		if tx, err := a.store.GetTransaction(&spaceID, txID); err == nil {
+			seq = tx.Seq
+		}
+		return a.blockUpdates(spaceID, txID, seq, NewSyncBlocks())
+	}
+
+	return a.transactionUpdates(spaceID, txs)
+}
+
+// GetUpdatesSince returns the updates of the transactions with a seq after the given seq.
+// the updates of a client that is up to date carry the latest transaction of the space.
+func (a *Api) GetUpdatesSince(spaceID SpaceID, seq uint64) (*BlockUpdates, error) {
+	txs, err := a.GetNextTransactionsSince(spaceID, seq)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(txs) == 0 {
+		latest, err := a.store.GetLatestTransaction(&spaceID)
+		if err != nil {
+			return nil, err
+		}
+		return a.blockUpdates(spaceID, latest.ID, latest.Seq, NewSyncBlocks())
+	}
+
+	return a.transactionUpdates(spaceID, txs)
+}
+
+// transactionUpdates returns the updates of the transactions, the last transaction is the cursor of the updates.
+func (a *Api) transactionUpdates(spaceID SpaceID, txs []*Transaction) (*BlockUpdates, error) {
+	updates := NewSyncBlocks()
+	for _, tx := range txs {
+		updates.extend(tx.changes)
+	}
+
+	last := txs[len(txs)-1]
+	return a.blockUpdates(spaceID, last.ID, last.Seq, updates)
+}
+
+// Subscribe streams the space updates after the given transaction ID until the context is done.
+// the first update carries the changes already in the transaction log, the following updates
+// carry the changes of one transaction each. the channel is closed when the subscription ends,
+// the client can subscribe again after the last transaction it has seen.
+func (a *Api) Subscribe(ctx context.Context, spaceID SpaceID, txID TransactionID) (<-chan *BlockUpdates, error) {
+	if a.subscriber == nil {
+		return nil, ErrSubscribeNotSupported
+	}
+
+	// subscribe before reading the log, the live changes already in the log are skipped
+	changes, cancel := a.subscriber.Subscribe(spaceID)
+	txs, err := a.GetNextTransactions(spaceID, txID)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	seen := NewSet[TransactionID]()
+	backlog := NewSyncBlocks()
+	for _, tx := range txs {
+		seen.Add(tx.ID)
+		backlog.extend(tx.changes)
+	}
+
+	ch := make(chan *BlockUpdates)
+	go func() {
+		defer close(ch)
+		defer cancel()
+
+		send := func(txID TransactionID, seq uint64, sb *SyncBlocks) bool {
+			updates, err := a.blockUpdates(spaceID, txID, seq, sb)
+			if err != nil {
+				logrus.Errorf("failed to load updates of space %v: %v", spaceID, err)
+				return false
+			}
+
+			select {
+			case ch <- updates:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		if len(txs) > 0 && !send(txs[len(txs)-1].ID, txs[len(txs)-1].Seq, backlog) {
+			return
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sb, ok := <-changes:
+				if !ok {
+					return
+				}
+				if seen.Contains(sb.transactionID) {
+					continue
+				}
+				// the published changes do not carry the seq of the transaction
+				tx, err := a.store.GetTransaction(&spaceID, sb.transactionID)
+				if err != nil {
+					logrus.Errorf("failed to load transaction %v of space %v: %v", sb.transactionID, spaceID, err)
+					return
+				}
+				if !send(tx.ID, tx.Seq, sb) {
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// GetNextTransactions returns all the transactions after the given transaction ID.
+// the transactions removed by a compaction get ErrSnapshotRequired.
+func (a *Api) GetNextTransactions(spaceID SpaceID, txID TransactionID) ([]*Transaction, error) {
+	err := a.checkCompacted(spaceID, txID)
+	if err != nil {
+		return nil, err
+	}
+
 	txs := make([]*Transaction, 0)
-	for i := 0; ; i++ {
-		nextTxs, err := a.store.GetNextTransactions(&spaceID, txID, i, 100)
+	for {
+		nextTxs, err := a.store.GetNextTransactions(&spaceID, txID, len(txs), 100)
 		if err != nil {
 			return nil, err
 		}
@@ -129,13 +370,40 @@ func (a *Api) GetUpdates(spaceID SpaceID, txID TransactionID) (*BlockUpdates, er
 		txs = append(txs, nextTxs...)
 	}
 
-	updates := NewSyncBlocks()
-	for _, tx := range txs {
-		updates.extend(tx.changes)
+	return txs, nil
+}
+
+// GetNextTransactionsSince returns all the transactions with a seq after the given seq.
+// the transactions removed by a compaction get ErrSnapshotRequired.
+func (a *Api) GetNextTransactionsSince(spaceID SpaceID, seq uint64) ([]*Transaction, error) {
+	snapshot, err := a.store.GetSnapshot(&spaceID)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot != nil && seq < snapshot.Seq {
+		return nil, fmt.Errorf("%w: seq %v", ErrSnapshotRequired, seq)
+	}
+
+	txs := make([]*Transaction, 0)
+	for {
+		nextTxs, err := a.store.GetTransactionsSince(&spaceID, seq, 100)
+		if err != nil {
+			return nil, err
+		}
+		if len(nextTxs) == 0 {
+			break
+		}
+		txs = append(txs, nextTxs...)
+		seq = nextTxs[len(nextTxs)-1].Seq
 	}
 
-	parenIDs := updates.children.ToSlice()
-	dirtyIDs := updates.dirty().ToSlice()
+	return txs, nil
+}
+
+// blockUpdates loads the current children and blocks touched by the changes.
+func (a *Api) blockUpdates(spaceID SpaceID, txID TransactionID, seq uint64, changes *SyncBlocks) (*BlockUpdates, error) {
+	parenIDs := changes.children.ToSlice()
+	dirtyIDs := changes.dirty().ToSlice()
 
 	childrenMap := make(map[BlockID][]BlockID)
 	for _, parentID := range parenIDs {
@@ -156,12 +424,18 @@ func (a *Api) GetUpdates(spaceID SpaceID, txID TransactionID) (*BlockUpdates, er
 	}
 
 	return &BlockUpdates{
-		Children: childrenMap,
-		Blocks:   blockMap,
+		TransactionID: txID,
+		Seq:           seq,
+		Children:      childrenMap,
+		Blocks:        blockMap,
 	}, nil
 }
 
 type BlockUpdates struct {
+	// TransactionID is the last transaction included in the updates
+	TransactionID TransactionID
+	// Seq is the seq of the last transaction, the client continues with GetUpdatesSince
+	Seq      uint64
 	Children map[BlockID][]BlockID
 	Blocks   map[BlockID]*Block
 }
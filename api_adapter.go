@@ -16,6 +16,7 @@ func BlockToProtoV1(b *Block) *v1.Block {
 		Object:   b.Type,
 		BlockId:  b.ID.String(),
 		ParentId: b.ParentID.String(),
+		Version:  b.Version,
 	}
 
 	if b.Json != nil {
@@ -39,6 +40,11 @@ func BlockToProtoV1(b *Block) *v1.Block {
 		block.Erased = &erased
 	}
 
+	if b.Index != nil {
+		index := b.Index.Key()
+		block.Index = &index
+	}
+
 	return block
 }
 
@@ -63,6 +69,7 @@ func BlockViewToProtoV1(b *BlockView) *v1.Block {
 		ParentId: b.ParentID.String(),
 		Children: children,
 		Linked:   links,
+		Version:  b.Version,
 	}
 
 	if b.Json != nil {
@@ -105,12 +112,89 @@ func transactionFromProtoV1(txv1 *v1.Transaction) (*Transaction, error) {
 		SpaceID: spaceID,
 		UserID:  userID,
 		Time:    time.Now(), // always use current time
+		Clock:   txv1.Clock,
 		Ops:     ops,
 	}
 
+	if txv1.Precondition != nil {
+		tx.Precondition, err = preconditionFromProtoV1(txv1.Precondition)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return tx, nil
 }
 
+func transactionResultToProtoV1(result *TransactionResult) *v1.ApplyTransactionResult {
+	res := &v1.ApplyTransactionResult{
+		TransactionId: result.TransactionID.String(),
+		SpaceId:       result.SpaceID.String(),
+		Success:       result.Success(),
+		Code:          resultCodeToProtoV1(result.Code),
+	}
+	if result.Err != nil {
+		res.Message = result.Err.Error()
+	}
+	for _, i := range result.Skipped {
+		res.SkippedOps = append(res.SkippedOps, uint32(i))
+	}
+
+	return res
+}
+
+func resultCodeToProtoV1(code ResultCode) v1.ApplyResultCode {
+	switch code {
+	case ResultOK:
+		return v1.ApplyResultCode_APPLY_RESULT_CODE_OK
+	case ResultNotFound:
+		return v1.ApplyResultCode_APPLY_RESULT_CODE_NOT_FOUND
+	case ResultCycle:
+		return v1.ApplyResultCode_APPLY_RESULT_CODE_CYCLE
+	case ResultInvalidPosition:
+		return v1.ApplyResultCode_APPLY_RESULT_CODE_INVALID_POSITION
+	case ResultDuplicate:
+		return v1.ApplyResultCode_APPLY_RESULT_CODE_DUPLICATE
+	case ResultConflict:
+		return v1.ApplyResultCode_APPLY_RESULT_CODE_CONFLICT
+	case ResultSnapshotRequired:
+		return v1.ApplyResultCode_APPLY_RESULT_CODE_SNAPSHOT_REQUIRED
+	case ResultInvalidOp:
+		return v1.ApplyResultCode_APPLY_RESULT_CODE_INVALID_OP
+	case ResultSchemaViolation:
+		return v1.ApplyResultCode_APPLY_RESULT_CODE_SCHEMA_VIOLATION
+	case ResultInternal:
+		return v1.ApplyResultCode_APPLY_RESULT_CODE_INTERNAL
+	default:
+		return v1.ApplyResultCode_APPLY_RESULT_CODE_UNKNOWN
+	}
+}
+
+func preconditionFromProtoV1(prev1 *v1.Precondition) (*Precondition, error) {
+	pre := &Precondition{
+		Seq:      prev1.Seq,
+		Versions: make(map[BlockID]uint64, len(prev1.Versions)),
+	}
+
+	if prev1.BlockId != "" {
+		blockID, err := uuid.Parse(prev1.BlockId)
+		if err != nil {
+			return nil, err
+		}
+		pre.BlockID = blockID
+	}
+
+	for id, version := range prev1.Versions {
+		blockID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, err
+		}
+		pre.Versions[blockID] = version
+	}
+
+	return pre, nil
+}
+
 func OpFromProtoV1(v1op *v1.Op) (*Op, error) {
 	blockID, err := uuid.Parse(v1op.BlockId)
 	if err != nil {
@@ -135,6 +219,8 @@ func OpFromProtoV1(v1op *v1.Op) (*Op, error) {
 		opType = "erase"
 	case v1.OpType_OP_TYPE_PATCH:
 		opType = "patch"
+	case v1.OpType_OP_TYPE_TEXT:
+		opType = "text"
 	}
 
 	if opType == "" {
@@ -168,6 +254,8 @@ func OpFromProtoV1(v1op *v1.Op) (*Op, error) {
 			pos = "before"
 		case v1.PointerPosition_POINTER_POSITION_AFTER:
 			pos = "after"
+		case v1.PointerPosition_POINTER_POSITION_INSIDE:
+			pos = "inside"
 		}
 
 		if pos == "" {
@@ -196,5 +284,13 @@ func OpFromProtoV1(v1op *v1.Op) (*Op, error) {
 		op.Patch = []byte(*v1op.Patch)
 	}
 
+	if v1op.Index != nil {
+		index, err := FracIndexFromKey(*v1op.Index)
+		if err != nil {
+			return nil, err
+		}
+		op.Index = index.Bytes()
+	}
+
 	return op, nil
 }
@@ -2,6 +2,7 @@ package blocktree
 
 import (
 	"context"
+	"fmt"
 
 	v1 "github.com/emrgen/blocktree/apis/v1"
 	"github.com/google/uuid"
@@ -23,40 +24,40 @@ func newGrpcApi(api *Api) *grpcApi {
 	}
 }
 
-// ApplyTransactions applies a list of transactions to the blocktree store
-func (a *grpcApi) ApplyTransactions(ctx context.Context, req *v1.TransactionsRequest) (*v1.TransactionsResponse, error) {
+// Apply applies a list of transactions to the blocktree store, each transaction gets its own result
+func (a *grpcApi) Apply(ctx context.Context, req *v1.TransactionsRequest) (*v1.TransactionsResponse, error) {
 	txs := req.GetTransactions()
 	transactions := make([]*Transaction, 0, len(txs))
-	for _, tx := range txs {
-		//logrus.Info("Parsing transaction: ", tx)
+	for i, tx := range txs {
 		transaction, err := transactionFromProtoV1(tx)
 		if err != nil {
-			return nil, err
+			return nil, invalidArgument(fmt.Sprintf("transactions[%d]", i), err)
 		}
-		//logrus.Info("Parsed transaction: ", transaction)
 		transactions = append(transactions, transaction)
 	}
 
+	results, _ := a.api.ApplyEach(transactions...)
+
 	res := &v1.TransactionsResponse{
-		Transactions: make([]*v1.ApplyTransactionResult, len(transactions)),
+		Transactions: make([]*v1.ApplyTransactionResult, 0, len(results)),
 	}
-
-	_, err := a.api.Apply(transactions...)
-	if err != nil {
-		return nil, err
+	for _, result := range results {
+		res.Transactions = append(res.Transactions, transactionResultToProtoV1(result))
 	}
 
-	//logrus.Info("Applied transactions: ", transactions)
 	return res, nil
 }
 
 // CreateSpace creates a new space in the blocktree store
 func (a *grpcApi) CreateSpace(ctx context.Context, req *v1.CreateSpaceRequest) (*v1.CreateSpaceResponse, error) {
-	spaceID, _ := uuid.Parse(req.GetSpaceId())
-	err := a.api.CreateSpace(spaceID, req.GetName())
+	spaceID, err := parseID("space_id", req.GetSpaceId())
 	if err != nil {
 		return nil, err
 	}
+	err = a.api.CreateSpace(spaceID, req.GetName())
+	if err != nil {
+		return nil, grpcError(err)
+	}
 
 	return &v1.CreateSpaceResponse{
 		SpaceId: spaceID.String(),
@@ -64,17 +65,19 @@ func (a *grpcApi) CreateSpace(ctx context.Context, req *v1.CreateSpaceRequest) (
 }
 
 func (a *grpcApi) GetBlock(ctx context.Context, req *v1.GetBlockRequest) (*v1.GetBlockResponse, error) {
-	blockID, _ := uuid.Parse(req.GetBlockId())
+	blockID, err := parseID("block_id", req.GetBlockId())
+	if err != nil {
+		return nil, err
+	}
 	var spaceID *uuid.UUID
-	var err error
 	if req.GetSpaceId() == "" {
 		// get space id from block id
 		spaceID, err = a.api.GetBlockSpaceID(blockID)
 		if err != nil {
-			return nil, err
+			return nil, grpcError(err)
 		}
 	} else {
-		sid, err := uuid.Parse(req.GetSpaceId())
+		sid, err := parseID("space_id", req.GetSpaceId())
 		if err != nil {
 			return nil, err
 		}
@@ -83,7 +86,7 @@ func (a *grpcApi) GetBlock(ctx context.Context, req *v1.GetBlockRequest) (*v1.Ge
 
 	block, err := a.api.GetBlock(*spaceID, blockID)
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
 	}
 
 	logrus.Infof("block %v", block)
@@ -97,7 +100,7 @@ func (a *grpcApi) GetChildren(ctx context.Context, req *v1.GetBlockChildrenReque
 	var err error
 	var spaceID *uuid.UUID
 
-	blockID, err := uuid.Parse(req.GetBlockId())
+	blockID, err := parseID("block_id", req.GetBlockId())
 	if err != nil {
 		return nil, err
 	}
@@ -106,36 +109,45 @@ func (a *grpcApi) GetChildren(ctx context.Context, req *v1.GetBlockChildrenReque
 		// get space id from block id
 		spaceID, err = a.api.GetBlockSpaceID(blockID)
 		if err != nil {
-			return nil, err
+			return nil, grpcError(err)
 		}
 	} else {
-		sid, err := uuid.Parse(req.GetSpaceId())
+		sid, err := parseID("space_id", req.GetSpaceId())
 		if err != nil {
 			return nil, err
 		}
 		spaceID = &sid
 	}
 
-	blocks, err := a.api.GetChildrenBlocks(*spaceID, blockID)
+	start, err := parsePageToken(req.GetPageToken())
 	if err != nil {
 		return nil, err
 	}
 
-	v1blocks := make([]*v1.Block, len(blocks))
-	for i, block := range blocks {
+	page, err := a.api.QueryChildrenBlocks(*spaceID, blockID, &BlockQuery{
+		Limit: int(req.GetLimit()),
+		Start: start,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	v1blocks := make([]*v1.Block, len(page.Blocks))
+	for i, block := range page.Blocks {
 		logrus.Infof("block %v index: %s", block.ID.String(), block.Index.String())
 		v1blocks[i] = BlockToProtoV1(block)
 	}
 
 	return &v1.GetBlockChildrenResponse{
-		Blocks: v1blocks,
+		Blocks:        v1blocks,
+		NextPageToken: page.Next.PageToken(),
 	}, nil
 }
 
 func (a *grpcApi) GetDescendants(ctx context.Context, req *v1.GetBlockDescendantsRequest) (*v1.GetBlockDescendantsResponse, error) {
 	logrus.Infof("Getting descendant blocks for block: %s", req.GetBlockId())
 	var err error
-	blockID, err := uuid.Parse(req.GetBlockId())
+	blockID, err := parseID("block_id", req.GetBlockId())
 	if err != nil {
 		return nil, err
 	}
@@ -145,51 +157,107 @@ func (a *grpcApi) GetDescendants(ctx context.Context, req *v1.GetBlockDescendant
 		// get space id from block id
 		sid, err := a.api.GetBlockSpaceID(blockID)
 		if err != nil {
-			return nil, err
+			return nil, grpcError(err)
 		}
 		spaceID = *sid
 	} else {
-		sid, err := uuid.Parse(req.GetSpaceId())
+		sid, err := parseID("space_id", req.GetSpaceId())
 		if err != nil {
 			return nil, err
 		}
 		spaceID = sid
 	}
 
-	blocks, err := a.api.GetDescendantBlocks(spaceID, blockID)
+	start, err := parsePageToken(req.GetPageToken())
 	if err != nil {
 		return nil, err
 	}
 
-	view, err := blockViewFromBlocks(blockID, blocks)
+	page, err := a.api.QueryDescendantBlocks(spaceID, blockID, &BlockQuery{
+		MaxDepth: int(req.GetMaxDepth()),
+		Limit:    int(req.GetLimit()),
+		Start:    start,
+	})
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
 	}
 
-	return &v1.GetBlockDescendantsResponse{
-		Block: BlockViewToProtoV1(view),
-	}, nil
+	res := &v1.GetBlockDescendantsResponse{
+		Blocks:        make([]*v1.Block, len(page.Blocks)),
+		NextPageToken: page.Next.PageToken(),
+	}
+	for i, block := range page.Blocks {
+		res.Blocks[i] = BlockToProtoV1(block)
+	}
+
+	// the later pages start below the root, their blocks are only returned as a list
+	if start == "" {
+		view, err := blockViewFromBlocks(blockID, page.Blocks)
+		if err != nil {
+			return nil, grpcError(err)
+		}
+		res.Block = BlockViewToProtoV1(view)
+	}
+
+	return res, nil
 }
 
+// GetPage returns the blocks of a page down to its sub-pages, the sub-pages are returned as stubs
 func (a *grpcApi) GetPage(ctx context.Context, req *v1.GetBlockPageRequest) (*v1.GetBlockPageResponse, error) {
-	//TODO implement me
-	panic("implement me")
+	pageID, err := parseID("block_id", req.GetBlockId())
+	if err != nil {
+		return nil, err
+	}
+
+	var spaceID uuid.UUID
+	if req.SpaceId == nil {
+		// get space id from block id
+		sid, err := a.api.GetBlockSpaceID(pageID)
+		if err != nil {
+			return nil, grpcError(err)
+		}
+		spaceID = *sid
+	} else {
+		spaceID, err = parseID("space_id", req.GetSpaceId())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	page, err := a.api.GetPage(spaceID, pageID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	res := &v1.GetBlockPageResponse{
+		Blocks:   make([]*v1.Block, 0, len(page.Blocks)),
+		SubPages: make([]*v1.Block, 0, len(page.SubPages)),
+		Block:    BlockViewToProtoV1(page.View),
+	}
+	for _, block := range page.Blocks {
+		res.Blocks = append(res.Blocks, BlockToProtoV1(block))
+	}
+	for _, block := range page.SubPages {
+		res.SubPages = append(res.SubPages, BlockToProtoV1(block))
+	}
+
+	return res, nil
 }
 
 func (a *grpcApi) GetBackLinks(ctx context.Context, req *v1.GetBackLinksRequest) (*v1.GetBackLinksResponse, error) {
 	var err error
-	blockID, err := uuid.Parse(req.GetBlockId())
+	blockID, err := parseID("block_id", req.GetBlockId())
 	if err != nil {
 		return nil, err
 	}
-	spaceID, err := uuid.Parse(req.GetSpaceId())
+	spaceID, err := parseID("space_id", req.GetSpaceId())
 	if err != nil {
 		return nil, err
 	}
 
 	links, err := a.api.GetBackLinks(blockID, spaceID)
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
 	}
 
 	blocks := make([]*v1.Block, 0)
@@ -204,28 +272,116 @@ func (a *grpcApi) GetBackLinks(ctx context.Context, req *v1.GetBackLinksRequest)
 
 func (a *grpcApi) GetUpdates(ctx context.Context, req *v1.GetUpdatesRequest) (*v1.GetUpdatesResponse, error) {
 	var err error
-	spaceID, err := uuid.Parse(req.GetSpaceId())
+	spaceID, err := parseID("space_id", req.GetSpaceId())
 	if err != nil {
 		return nil, err
 	}
 
-	txID, err := uuid.Parse(req.GetTransactionId())
+	var updates *BlockUpdates
+	if req.GetTransactionId() == "" {
+		updates, err = a.api.GetUpdatesSince(spaceID, req.GetSeq())
+	} else {
+		var txID TransactionID
+		txID, err = parseID("transaction_id", req.GetTransactionId())
+		if err != nil {
+			return nil, err
+		}
+		updates, err = a.api.GetUpdates(spaceID, txID)
+	}
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
+	}
+
+	children, blocks := blockUpdatesToProtoV1(updates)
+
+	return &v1.GetUpdatesResponse{
+		Updates:       children,
+		Blocks:        blocks,
+		TransactionId: updates.TransactionID.String(),
+		Seq:           updates.Seq,
+	}, nil
+}
+
+// Subscribe streams the space updates to the client until the client goes away
+func (a *grpcApi) Subscribe(req *v1.SubscribeRequest, stream v1.Blocktree_SubscribeServer) error {
+	spaceID, err := parseID("space_id", req.GetSpaceId())
+	if err != nil {
+		return err
+	}
+
+	txID, err := parseID("after_transaction_id", req.GetAfterTransactionId())
+	if err != nil {
+		return err
+	}
+
+	updates, err := a.api.Subscribe(stream.Context(), spaceID, txID)
+	if err != nil {
+		return grpcError(err)
+	}
+
+	for update := range updates {
+		err = stream.Send(subscribeResponseFromUpdates(update))
+		if err != nil {
+			return err
+		}
 	}
-	updates, err := a.api.GetUpdates(spaceID, txID)
+
+	return stream.Context().Err()
+}
+
+// GetSnapshot returns the current tree of the space
+func (a *grpcApi) GetSnapshot(ctx context.Context, req *v1.GetSnapshotRequest) (*v1.GetSnapshotResponse, error) {
+	spaceID, err := parseID("space_id", req.GetSpaceId())
 	if err != nil {
 		return nil, err
 	}
 
-	res := &v1.GetUpdatesResponse{
-		Updates: make(map[string]*v1.ChildIds),
-		Blocks:  make([]*v1.Block, 0),
+	snapshot, err := a.api.Snapshot(spaceID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	blocks := make([]*v1.Block, 0, len(snapshot.Blocks))
+	for _, block := range snapshot.Blocks {
+		blocks = append(blocks, BlockToProtoV1(block))
+	}
+
+	backLinks := make(map[string]*v1.ChildIds)
+	for id, parentIDs := range snapshot.BackLinks {
+		ids := &v1.ChildIds{
+			BlockIds: make([]string, 0, len(parentIDs)),
+		}
+		for _, parentID := range parentIDs {
+			ids.BlockIds = append(ids.BlockIds, parentID.String())
+		}
+		backLinks[id.String()] = ids
 	}
 
+	return &v1.GetSnapshotResponse{
+		TransactionId: snapshot.TransactionID.String(),
+		BackLinks:     backLinks,
+		Blocks:        blocks,
+	}, nil
+}
+
+func subscribeResponseFromUpdates(updates *BlockUpdates) *v1.SubscribeResponse {
+	children, blocks := blockUpdatesToProtoV1(updates)
+
+	return &v1.SubscribeResponse{
+		TransactionId: updates.TransactionID.String(),
+		Updates:       children,
+		Blocks:        blocks,
+	}
+}
+
+// blockUpdatesToProtoV1 converts the updates to the proto children map and blocks
+func blockUpdatesToProtoV1(updates *BlockUpdates) (map[string]*v1.ChildIds, []*v1.Block) {
+	children := make(map[string]*v1.ChildIds)
+	blocks := make([]*v1.Block, 0)
+
 	// convert updates to proto format
 	for _, block := range updates.Blocks {
-		res.Blocks = append(res.Blocks, BlockToProtoV1(block))
+		blocks = append(blocks, BlockToProtoV1(block))
 	}
 
 	for parentID, childrenIDs := range updates.Children {
@@ -237,8 +393,8 @@ func (a *grpcApi) GetUpdates(ctx context.Context, req *v1.GetUpdatesRequest) (*v
 			childIds.BlockIds = append(childIds.BlockIds, id.String())
 		}
 
-		res.Updates[parentID.String()] = childIds
+		children[parentID.String()] = childIds
 	}
 
-	return res, nil
+	return children, blocks
 }
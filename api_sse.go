@@ -0,0 +1,113 @@
+package blocktree
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// eventsPath is the server-sent events endpoint of the space updates
+const eventsPath = "GET /v1/spaces/{space_id}/events"
+
+// sseKeepAlive is the interval of the comments that keep idle connections open
+const sseKeepAlive = 30 * time.Second
+
+// sseApi streams the space updates to http clients as server-sent events.
+// the stream starts after the transaction in the "after" query parameter, a reconnecting
+// EventSource sends the Last-Event-ID header instead, the event id is the last transaction of the event.
+type sseApi struct {
+	api       *Api
+	marshaler protojson.MarshalOptions
+}
+
+func newSseApi(api *Api) *sseApi {
+	return &sseApi{
+		api: api,
+		marshaler: protojson.MarshalOptions{
+			UseProtoNames:   true,
+			EmitUnpopulated: true,
+		},
+	}
+}
+
+func (s *sseApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	spaceID, err := uuid.Parse(r.PathValue("space_id"))
+	if err != nil {
+		http.Error(w, "invalid space id", http.StatusBadRequest)
+		return
+	}
+
+	txID := uuid.Nil
+	after := r.URL.Query().Get("after")
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		after = lastEventID
+	}
+	if after != "" {
+		txID, err = uuid.Parse(after)
+		if err != nil {
+			http.Error(w, "invalid transaction id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	updates, err := s.api.Subscribe(r.Context(), spaceID, txID)
+	if err != nil {
+		http.Error(w, err.Error(), httpStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(sseKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+
+			data, err := s.marshaler.Marshal(subscribeResponseFromUpdates(update))
+			if err != nil {
+				return
+			}
+			_, err = fmt.Fprintf(w, "id: %s\nevent: updates\ndata: %s\n\n", update.TransactionID, data)
+			if err != nil {
+				return
+			}
+		case <-ticker.C:
+			_, err := fmt.Fprint(w, ": keep-alive\n\n")
+			if err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// httpStatus returns the http status of the error, the status code the gRPC api returns for the error
+// is converted the way the gateway converts it. the compacted transactions are gone.
+func httpStatus(err error) int {
+	if errors.Is(err, ErrSnapshotRequired) {
+		return http.StatusGone
+	}
+
+	return runtime.HTTPStatusFromCode(infoOf(err).code)
+}
@@ -0,0 +1,95 @@
+package blocktree
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSseApi_Events(t *testing.T) {
+	api := NewApiWithBroker(NewMemStore(), NewBroker())
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionEnd))
+	_, err = api.Apply(tx1)
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.Handle(eventsPath, newSseApi(api))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/v1/spaces/"+s1.String()+"/events", nil)
+	assert.NoError(t, err)
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(res.Body)
+	readEvent := func() []string {
+		lines := make([]string, 0)
+		for {
+			line, err := reader.ReadString('\n')
+			assert.NoError(t, err)
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				return lines
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	event := readEvent()
+	assert.Equal(t, 3, len(event))
+	assert.Equal(t, "id: "+tx1.ID.String(), event[0])
+	assert.Equal(t, "event: updates", event[1])
+	assert.Contains(t, event[2], `"transaction_id":"`+tx1.ID.String()+`"`)
+
+	tx2 := createTx(s1, insertOp(b2, "p2", s1, PositionEnd))
+	_, err = api.Apply(tx2)
+	assert.NoError(t, err)
+
+	event = readEvent()
+	assert.Equal(t, "id: "+tx2.ID.String(), event[0])
+	assert.Contains(t, event[2], b2.String())
+}
+
+func TestSseApi_InvalidRequest(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle(eventsPath, newSseApi(NewApiWithBroker(NewMemStore(), NewBroker())))
+
+	res := httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/v1/spaces/not-a-uuid/events", nil))
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+
+	res = httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/v1/spaces/"+s1.String()+"/events?after=nope", nil))
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+
+	// the typed errors get the status of their gRPC code
+	res = httptest.NewRecorder()
+	mux.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/v1/spaces/"+s1.String()+"/events", nil))
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
+
+func TestHttpStatus(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, httpStatus(ErrSpaceNotFound{ID: s1}))
+	assert.Equal(t, http.StatusNotFound, httpStatus(ErrTransactionNotFound{ID: b1}))
+	assert.Equal(t, http.StatusBadRequest, httpStatus(fmt.Errorf("%w: transaction has no ops", ErrInvalidOp)))
+	assert.Equal(t, http.StatusGone, httpStatus(fmt.Errorf("%w: seq 1", ErrSnapshotRequired)))
+	assert.Equal(t, http.StatusNotImplemented, httpStatus(ErrSubscribeNotSupported))
+	assert.Equal(t, http.StatusInternalServerError, httpStatus(errors.New("disk is full")))
+}
@@ -1,9 +1,12 @@
 package blocktree
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -443,6 +446,71 @@ func TestApi_TransactionChanges(t *testing.T) {
 	//fmt.Printf("updates: %v\n", updates)
 }
 
+func TestApi_GetUpdatesSince(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionEnd))
+	tx2 := createTx(s1, insertOp(b2, "p2", s1, PositionEnd))
+	tx3 := createTx(s1, moveOp(b1, s1, b2, PositionStart))
+	_, err = api.Apply(tx1, tx2, tx3)
+	assert.NoError(t, err)
+
+	txs, err := api.GetNextTransactionsSince(s1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []TransactionID{tx2.ID, tx3.ID}, transactionIDs(txs))
+	assert.Equal(t, uint64(3), txs[1].Seq)
+
+	updates, err := api.GetUpdatesSince(s1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, tx3.ID, updates.TransactionID)
+	assert.Equal(t, uint64(3), updates.Seq)
+	assert.Equal(t, []BlockID{b1}, updates.Children[b2])
+	assert.Equal(t, []BlockID{b2}, updates.Children[s1])
+
+	// the seq cursor matches the transaction cursor
+	byID, err := api.GetUpdates(s1, tx1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, updates.Seq, byID.Seq)
+	assert.Equal(t, updates.Children, byID.Children)
+
+	// an up to date client gets the latest transaction back
+	updates, err = api.GetUpdatesSince(s1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, tx3.ID, updates.TransactionID)
+	assert.Empty(t, updates.Children)
+}
+
+func TestApi_HLC(t *testing.T) {
+	store := NewMemStore()
+	api := NewApi(store)
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	// a transaction stamped by another replica keeps its timestamp, the next ones are stamped after it
+	ahead := NewHLC(time.Now().Add(time.Hour), 3)
+	tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionEnd))
+	tx1.HLC = ahead
+	tx2 := createTx(s1, insertOp(b2, "p2", s1, PositionEnd))
+	_, err = api.Apply(tx1, tx2)
+	assert.NoError(t, err)
+	assert.Zero(t, tx2.HLC)
+
+	txs, err := store.GetTransactionsSince(&s1, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, ahead, txs[0].HLC)
+	assert.Equal(t, ahead+1, txs[1].HLC)
+
+	// a new api continues after the logged timestamps
+	tx3 := createTx(s1, insertOp(b3, "p3", s1, PositionEnd))
+	_, err = NewApi(store).Apply(tx3)
+	assert.NoError(t, err)
+	latest, err := store.GetLatestTransaction(&s1)
+	assert.NoError(t, err)
+	assert.Equal(t, ahead+2, latest.HLC)
+}
+
 func TestApi_AddBackLink(t *testing.T) {
 	var err error
 	var tx *Transaction
@@ -522,3 +590,80 @@ func TestApi_IdempotentTransaction(t *testing.T) {
 
 	//api.store.(*MemStore).Print(&s1)
 }
+
+// failingPublisher fails every publish, like a bus that is down
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(*SyncBlocks) error {
+	return fmt.Errorf("bus is down")
+}
+
+func TestApi_PublishFailure(t *testing.T) {
+	api := NewApiWithPublisher(NewMemStore(), failingPublisher{})
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	// the stored transactions are not failed by the bus, the rest of the batch is applied
+	changes, err := api.Apply(
+		createTx(s1, insertOp(b1, "p1", s1, PositionEnd)),
+		createTx(s1, insertOp(b2, "p2", s1, PositionEnd)),
+	)
+	assert.NoError(t, err)
+	assert.Contains(t, changes.dirty().ToSlice(), b2)
+
+	children, err := api.GetChildrenBlocks(s1, s1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(children))
+}
+
+func TestSubscribe(t *testing.T) {
+	api := NewApiWithBroker(NewMemStore(), NewBroker())
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionEnd))
+	_, err = api.Apply(tx1)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates, err := api.Subscribe(ctx, s1, uuid.Nil)
+	assert.NoError(t, err)
+
+	// the changes in the log come first
+	update := <-updates
+	assert.Equal(t, tx1.ID, update.TransactionID)
+	assert.Equal(t, []BlockID{b1}, update.Children[s1])
+	assert.Contains(t, update.Blocks, b1)
+
+	tx2 := createTx(s1, insertOp(b2, "p2", b1, PositionEnd))
+	_, err = api.Apply(tx2)
+	assert.NoError(t, err)
+
+	update = <-updates
+	assert.Equal(t, tx2.ID, update.TransactionID)
+	assert.Equal(t, []BlockID{b2}, update.Children[b1])
+	assert.Contains(t, update.Blocks, b2)
+
+	cancel()
+	for range updates {
+	}
+
+	// nothing is sent until the next change after the given transaction
+	updates, err = api.Subscribe(context.Background(), s1, tx2.ID)
+	assert.NoError(t, err)
+
+	tx3 := createTx(s1, updateOp(b2, []byte(`[{"op":"add","path":"/text","value":"hello"}]`)))
+	_, err = api.Apply(tx3)
+	assert.NoError(t, err)
+
+	update = <-updates
+	assert.Equal(t, tx3.ID, update.TransactionID)
+	assert.Equal(t, 0, len(update.Children))
+	assert.Contains(t, update.Blocks, b2)
+}
+
+func TestSubscribeNotSupported(t *testing.T) {
+	api := NewApi(NewMemStore())
+	_, err := api.Subscribe(context.Background(), s1, uuid.Nil)
+	assert.ErrorIs(t, err, ErrSubscribeNotSupported)
+}
@@ -39,6 +39,8 @@ const (
 	OpType_OP_TYPE_RESTORE  OpType = 8
 	OpType_OP_TYPE_LINK     OpType = 9
 	OpType_OP_TYPE_UNLINK   OpType = 10
+	// text edits a collaborative text in the json of the block, the patch is a text patch instead of a json patch
+	OpType_OP_TYPE_TEXT OpType = 11
 )
 
 // Enum value maps for OpType.
@@ -55,6 +57,7 @@ var (
 		8:  "OP_TYPE_RESTORE",
 		9:  "OP_TYPE_LINK",
 		10: "OP_TYPE_UNLINK",
+		11: "OP_TYPE_TEXT",
 	}
 	OpType_value = map[string]int32{
 		"OP_TYPE_UNKNOWN":  0,
@@ -68,6 +71,7 @@ var (
 		"OP_TYPE_RESTORE":  8,
 		"OP_TYPE_LINK":     9,
 		"OP_TYPE_UNLINK":   10,
+		"OP_TYPE_TEXT":     11,
 	}
 )
 
@@ -106,6 +110,8 @@ const (
 	PointerPosition_POINTER_POSITION_AFTER   PointerPosition = 2
 	PointerPosition_POINTER_POSITION_START   PointerPosition = 3
 	PointerPosition_POINTER_POSITION_END     PointerPosition = 4
+	// inside places a linked block, or a moved block at the exact index of the op
+	PointerPosition_POINTER_POSITION_INSIDE PointerPosition = 5
 )
 
 // Enum value maps for PointerPosition.
@@ -116,6 +122,7 @@ var (
 		2: "POINTER_POSITION_AFTER",
 		3: "POINTER_POSITION_START",
 		4: "POINTER_POSITION_END",
+		5: "POINTER_POSITION_INSIDE",
 	}
 	PointerPosition_value = map[string]int32{
 		"POINTER_POSITION_UNKNOWN": 0,
@@ -123,6 +130,7 @@ var (
 		"POINTER_POSITION_AFTER":   2,
 		"POINTER_POSITION_START":   3,
 		"POINTER_POSITION_END":     4,
+		"POINTER_POSITION_INSIDE":  5,
 	}
 )
 
@@ -153,6 +161,80 @@ func (PointerPosition) EnumDescriptor() ([]byte, []int) {
 	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{1}
 }
 
+type ApplyResultCode int32
+
+const (
+	ApplyResultCode_APPLY_RESULT_CODE_UNKNOWN   ApplyResultCode = 0
+	ApplyResultCode_APPLY_RESULT_CODE_OK        ApplyResultCode = 1
+	ApplyResultCode_APPLY_RESULT_CODE_NOT_FOUND ApplyResultCode = 2
+	// the transaction is applied with the moves that create a cycle skipped, or failed with a cycle
+	ApplyResultCode_APPLY_RESULT_CODE_CYCLE             ApplyResultCode = 3
+	ApplyResultCode_APPLY_RESULT_CODE_INVALID_POSITION  ApplyResultCode = 4
+	ApplyResultCode_APPLY_RESULT_CODE_DUPLICATE         ApplyResultCode = 5
+	ApplyResultCode_APPLY_RESULT_CODE_CONFLICT          ApplyResultCode = 6
+	ApplyResultCode_APPLY_RESULT_CODE_SNAPSHOT_REQUIRED ApplyResultCode = 7
+	ApplyResultCode_APPLY_RESULT_CODE_INTERNAL          ApplyResultCode = 8
+	ApplyResultCode_APPLY_RESULT_CODE_INVALID_OP        ApplyResultCode = 9
+	ApplyResultCode_APPLY_RESULT_CODE_SCHEMA_VIOLATION  ApplyResultCode = 10
+)
+
+// Enum value maps for ApplyResultCode.
+var (
+	ApplyResultCode_name = map[int32]string{
+		0:  "APPLY_RESULT_CODE_UNKNOWN",
+		1:  "APPLY_RESULT_CODE_OK",
+		2:  "APPLY_RESULT_CODE_NOT_FOUND",
+		3:  "APPLY_RESULT_CODE_CYCLE",
+		4:  "APPLY_RESULT_CODE_INVALID_POSITION",
+		5:  "APPLY_RESULT_CODE_DUPLICATE",
+		6:  "APPLY_RESULT_CODE_CONFLICT",
+		7:  "APPLY_RESULT_CODE_SNAPSHOT_REQUIRED",
+		8:  "APPLY_RESULT_CODE_INTERNAL",
+		9:  "APPLY_RESULT_CODE_INVALID_OP",
+		10: "APPLY_RESULT_CODE_SCHEMA_VIOLATION",
+	}
+	ApplyResultCode_value = map[string]int32{
+		"APPLY_RESULT_CODE_UNKNOWN":           0,
+		"APPLY_RESULT_CODE_OK":                1,
+		"APPLY_RESULT_CODE_NOT_FOUND":         2,
+		"APPLY_RESULT_CODE_CYCLE":             3,
+		"APPLY_RESULT_CODE_INVALID_POSITION":  4,
+		"APPLY_RESULT_CODE_DUPLICATE":         5,
+		"APPLY_RESULT_CODE_CONFLICT":          6,
+		"APPLY_RESULT_CODE_SNAPSHOT_REQUIRED": 7,
+		"APPLY_RESULT_CODE_INTERNAL":          8,
+		"APPLY_RESULT_CODE_INVALID_OP":        9,
+		"APPLY_RESULT_CODE_SCHEMA_VIOLATION":  10,
+	}
+)
+
+func (x ApplyResultCode) Enum() *ApplyResultCode {
+	p := new(ApplyResultCode)
+	*p = x
+	return p
+}
+
+func (x ApplyResultCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ApplyResultCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_apis_v1_blocktree_proto_enumTypes[2].Descriptor()
+}
+
+func (ApplyResultCode) Type() protoreflect.EnumType {
+	return &file_apis_v1_blocktree_proto_enumTypes[2]
+}
+
+func (x ApplyResultCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ApplyResultCode.Descriptor instead.
+func (ApplyResultCode) EnumDescriptor() ([]byte, []int) {
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{2}
+}
+
 type Pointer struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -277,6 +359,9 @@ type Op struct {
 	Linked   *bool    `protobuf:"varint,7,opt,name=linked,proto3,oneof" json:"linked,omitempty"`
 	Props    *string  `protobuf:"bytes,8,opt,name=props,proto3,oneof" json:"props,omitempty"`
 	Patch    *string  `protobuf:"bytes,9,opt,name=patch,proto3,oneof" json:"patch,omitempty"`
+	// index is the key of the exact index of a block moved inside its parent, see Block.index.
+	// the rebalance moves only change the index
+	Index *string `protobuf:"bytes,11,opt,name=index,proto3,oneof" json:"index,omitempty"`
 }
 
 func (x *Op) Reset() {
@@ -374,6 +459,13 @@ func (x *Op) GetPatch() string {
 	return ""
 }
 
+func (x *Op) GetIndex() string {
+	if x != nil && x.Index != nil {
+		return *x.Index
+	}
+	return ""
+}
+
 type Transaction struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -382,7 +474,12 @@ type Transaction struct {
 	TransactionId string `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
 	SpaceId       string `protobuf:"bytes,2,opt,name=space_id,json=spaceId,proto3" json:"space_id,omitempty"`
 	UserId        string `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Ops           []*Op  `protobuf:"bytes,4,rep,name=ops,proto3" json:"ops,omitempty"` //  google.protobuf.Timestamp time = 5;
+	Ops           []*Op  `protobuf:"bytes,4,rep,name=ops,proto3" json:"ops,omitempty"`
+	//  google.protobuf.Timestamp time = 5;
+	// clock is the Lamport clock of the transaction, zero lets the server assign the next clock of the space
+	Clock uint64 `protobuf:"varint,6,opt,name=clock,proto3" json:"clock,omitempty"`
+	// precondition is the state of the space the transaction is based on
+	Precondition *Precondition `protobuf:"bytes,7,opt,name=precondition,proto3,oneof" json:"precondition,omitempty"`
 }
 
 func (x *Transaction) Reset() {
@@ -445,6 +542,88 @@ func (x *Transaction) GetOps() []*Op {
 	return nil
 }
 
+func (x *Transaction) GetClock() uint64 {
+	if x != nil {
+		return x.Clock
+	}
+	return 0
+}
+
+func (x *Transaction) GetPrecondition() *Precondition {
+	if x != nil {
+		return x.Precondition
+	}
+	return nil
+}
+
+// Precondition applies the transaction only if the blocks did not change after the given versions,
+// the version of a block is the seq of the last transaction that changed it
+type Precondition struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// seq is the seq of the space the transaction is based on
+	Seq *uint64 `protobuf:"varint,1,opt,name=seq,proto3,oneof" json:"seq,omitempty"`
+	// block_id limits the seq check to the descendants of the block
+	BlockId string `protobuf:"bytes,2,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	// versions maps the block ids to the versions the transaction is based on
+	Versions map[string]uint64 `protobuf:"bytes,3,rep,name=versions,proto3" json:"versions,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+}
+
+func (x *Precondition) Reset() {
+	*x = Precondition{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_apis_v1_blocktree_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Precondition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Precondition) ProtoMessage() {}
+
+func (x *Precondition) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_v1_blocktree_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Precondition.ProtoReflect.Descriptor instead.
+func (*Precondition) Descriptor() ([]byte, []int) {
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Precondition) GetSeq() uint64 {
+	if x != nil && x.Seq != nil {
+		return *x.Seq
+	}
+	return 0
+}
+
+func (x *Precondition) GetBlockId() string {
+	if x != nil {
+		return x.BlockId
+	}
+	return ""
+}
+
+func (x *Precondition) GetVersions() map[string]uint64 {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
 type TransactionsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -456,7 +635,7 @@ type TransactionsRequest struct {
 func (x *TransactionsRequest) Reset() {
 	*x = TransactionsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[4]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -469,7 +648,7 @@ func (x *TransactionsRequest) String() string {
 func (*TransactionsRequest) ProtoMessage() {}
 
 func (x *TransactionsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[4]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -482,7 +661,7 @@ func (x *TransactionsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TransactionsRequest.ProtoReflect.Descriptor instead.
 func (*TransactionsRequest) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{4}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *TransactionsRequest) GetTransactions() []*Transaction {
@@ -497,16 +676,19 @@ type ApplyTransactionResult struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	TransactionId string `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
-	SpaceId       string `protobuf:"bytes,2,opt,name=space_id,json=spaceId,proto3" json:"space_id,omitempty"`
-	Success       bool   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	TransactionId string          `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	SpaceId       string          `protobuf:"bytes,2,opt,name=space_id,json=spaceId,proto3" json:"space_id,omitempty"`
+	Success       bool            `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string          `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	Code          ApplyResultCode `protobuf:"varint,5,opt,name=code,proto3,enum=apis.v1.ApplyResultCode" json:"code,omitempty"`
+	// skipped_ops are the indexes of the move ops skipped because they create a cycle
+	SkippedOps []uint32 `protobuf:"varint,6,rep,packed,name=skipped_ops,json=skippedOps,proto3" json:"skipped_ops,omitempty"`
 }
 
 func (x *ApplyTransactionResult) Reset() {
 	*x = ApplyTransactionResult{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[5]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -519,7 +701,7 @@ func (x *ApplyTransactionResult) String() string {
 func (*ApplyTransactionResult) ProtoMessage() {}
 
 func (x *ApplyTransactionResult) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[5]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -532,7 +714,7 @@ func (x *ApplyTransactionResult) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ApplyTransactionResult.ProtoReflect.Descriptor instead.
 func (*ApplyTransactionResult) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{5}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *ApplyTransactionResult) GetTransactionId() string {
@@ -563,6 +745,20 @@ func (x *ApplyTransactionResult) GetMessage() string {
 	return ""
 }
 
+func (x *ApplyTransactionResult) GetCode() ApplyResultCode {
+	if x != nil {
+		return x.Code
+	}
+	return ApplyResultCode_APPLY_RESULT_CODE_UNKNOWN
+}
+
+func (x *ApplyTransactionResult) GetSkippedOps() []uint32 {
+	if x != nil {
+		return x.SkippedOps
+	}
+	return nil
+}
+
 type TransactionsResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -574,7 +770,7 @@ type TransactionsResponse struct {
 func (x *TransactionsResponse) Reset() {
 	*x = TransactionsResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[6]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -587,7 +783,7 @@ func (x *TransactionsResponse) String() string {
 func (*TransactionsResponse) ProtoMessage() {}
 
 func (x *TransactionsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[6]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -600,7 +796,7 @@ func (x *TransactionsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TransactionsResponse.ProtoReflect.Descriptor instead.
 func (*TransactionsResponse) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{6}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *TransactionsResponse) GetTransactions() []*ApplyTransactionResult {
@@ -622,7 +818,7 @@ type CreateSpaceRequest struct {
 func (x *CreateSpaceRequest) Reset() {
 	*x = CreateSpaceRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[7]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -635,7 +831,7 @@ func (x *CreateSpaceRequest) String() string {
 func (*CreateSpaceRequest) ProtoMessage() {}
 
 func (x *CreateSpaceRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[7]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -648,7 +844,7 @@ func (x *CreateSpaceRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateSpaceRequest.ProtoReflect.Descriptor instead.
 func (*CreateSpaceRequest) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{7}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *CreateSpaceRequest) GetSpaceId() string {
@@ -676,7 +872,7 @@ type CreateSpaceResponse struct {
 func (x *CreateSpaceResponse) Reset() {
 	*x = CreateSpaceResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[8]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -689,7 +885,7 @@ func (x *CreateSpaceResponse) String() string {
 func (*CreateSpaceResponse) ProtoMessage() {}
 
 func (x *CreateSpaceResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[8]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -702,7 +898,7 @@ func (x *CreateSpaceResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateSpaceResponse.ProtoReflect.Descriptor instead.
 func (*CreateSpaceResponse) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{8}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *CreateSpaceResponse) GetSpaceId() string {
@@ -726,12 +922,16 @@ type Block struct {
 	Props    *string  `protobuf:"bytes,7,opt,name=props,proto3,oneof" json:"props,omitempty"`
 	Deleted  *bool    `protobuf:"varint,8,opt,name=deleted,proto3,oneof" json:"deleted,omitempty"`
 	Erased   *bool    `protobuf:"varint,9,opt,name=erased,proto3,oneof" json:"erased,omitempty"`
+	// index is the key of the block among its siblings, the siblings compare by the keys as byte strings and then by block_id
+	Index *string `protobuf:"bytes,10,opt,name=index,proto3,oneof" json:"index,omitempty"`
+	// version is the seq of the last transaction that changed the block or its children, see Precondition.versions
+	Version uint64 `protobuf:"varint,11,opt,name=version,proto3" json:"version,omitempty"`
 }
 
 func (x *Block) Reset() {
 	*x = Block{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[9]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -744,7 +944,7 @@ func (x *Block) String() string {
 func (*Block) ProtoMessage() {}
 
 func (x *Block) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[9]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -757,7 +957,7 @@ func (x *Block) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Block.ProtoReflect.Descriptor instead.
 func (*Block) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{9}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Block) GetParentId() string {
@@ -823,6 +1023,20 @@ func (x *Block) GetErased() bool {
 	return false
 }
 
+func (x *Block) GetIndex() string {
+	if x != nil && x.Index != nil {
+		return *x.Index
+	}
+	return ""
+}
+
+func (x *Block) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 type GetBlockRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -835,7 +1049,7 @@ type GetBlockRequest struct {
 func (x *GetBlockRequest) Reset() {
 	*x = GetBlockRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[10]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -848,7 +1062,7 @@ func (x *GetBlockRequest) String() string {
 func (*GetBlockRequest) ProtoMessage() {}
 
 func (x *GetBlockRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[10]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -861,7 +1075,7 @@ func (x *GetBlockRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBlockRequest.ProtoReflect.Descriptor instead.
 func (*GetBlockRequest) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{10}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *GetBlockRequest) GetSpaceId() string {
@@ -889,7 +1103,7 @@ type GetBlockResponse struct {
 func (x *GetBlockResponse) Reset() {
 	*x = GetBlockResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[11]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -902,7 +1116,7 @@ func (x *GetBlockResponse) String() string {
 func (*GetBlockResponse) ProtoMessage() {}
 
 func (x *GetBlockResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[11]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -915,7 +1129,7 @@ func (x *GetBlockResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBlockResponse.ProtoReflect.Descriptor instead.
 func (*GetBlockResponse) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{11}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *GetBlockResponse) GetBlock() *Block {
@@ -932,12 +1146,16 @@ type GetBlockChildrenRequest struct {
 
 	SpaceId *string `protobuf:"bytes,1,opt,name=space_id,json=spaceId,proto3,oneof" json:"space_id,omitempty"`
 	BlockId string  `protobuf:"bytes,2,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	// limit is the max number of children in the response, 0 returns all the children
+	Limit uint32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	// page_token is the next_page_token of the previous response
+	PageToken string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 }
 
 func (x *GetBlockChildrenRequest) Reset() {
 	*x = GetBlockChildrenRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[12]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -950,7 +1168,7 @@ func (x *GetBlockChildrenRequest) String() string {
 func (*GetBlockChildrenRequest) ProtoMessage() {}
 
 func (x *GetBlockChildrenRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[12]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -963,7 +1181,7 @@ func (x *GetBlockChildrenRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBlockChildrenRequest.ProtoReflect.Descriptor instead.
 func (*GetBlockChildrenRequest) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{12}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *GetBlockChildrenRequest) GetSpaceId() string {
@@ -980,18 +1198,34 @@ func (x *GetBlockChildrenRequest) GetBlockId() string {
 	return ""
 }
 
+func (x *GetBlockChildrenRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetBlockChildrenRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
 type GetBlockChildrenResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	Blocks []*Block `protobuf:"bytes,1,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	// next_page_token is empty on the last page
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
 }
 
 func (x *GetBlockChildrenResponse) Reset() {
 	*x = GetBlockChildrenResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[13]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1004,7 +1238,7 @@ func (x *GetBlockChildrenResponse) String() string {
 func (*GetBlockChildrenResponse) ProtoMessage() {}
 
 func (x *GetBlockChildrenResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[13]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1017,7 +1251,7 @@ func (x *GetBlockChildrenResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBlockChildrenResponse.ProtoReflect.Descriptor instead.
 func (*GetBlockChildrenResponse) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{13}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *GetBlockChildrenResponse) GetBlocks() []*Block {
@@ -1027,6 +1261,13 @@ func (x *GetBlockChildrenResponse) GetBlocks() []*Block {
 	return nil
 }
 
+func (x *GetBlockChildrenResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
 type GetBlockDescendantsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1034,12 +1275,18 @@ type GetBlockDescendantsRequest struct {
 
 	SpaceId *string `protobuf:"bytes,1,opt,name=space_id,json=spaceId,proto3,oneof" json:"space_id,omitempty"`
 	BlockId string  `protobuf:"bytes,2,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	// max_depth is the depth of the deepest descendants, the children are at depth 1 and 0 is unlimited
+	MaxDepth uint32 `protobuf:"varint,3,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`
+	// limit is the max number of blocks in the response, 0 returns all the blocks
+	Limit uint32 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	// page_token is the next_page_token of the previous response
+	PageToken string `protobuf:"bytes,5,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 }
 
 func (x *GetBlockDescendantsRequest) Reset() {
 	*x = GetBlockDescendantsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[14]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1052,7 +1299,7 @@ func (x *GetBlockDescendantsRequest) String() string {
 func (*GetBlockDescendantsRequest) ProtoMessage() {}
 
 func (x *GetBlockDescendantsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[14]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1065,7 +1312,7 @@ func (x *GetBlockDescendantsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBlockDescendantsRequest.ProtoReflect.Descriptor instead.
 func (*GetBlockDescendantsRequest) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{14}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *GetBlockDescendantsRequest) GetSpaceId() string {
@@ -1082,18 +1329,44 @@ func (x *GetBlockDescendantsRequest) GetBlockId() string {
 	return ""
 }
 
+func (x *GetBlockDescendantsRequest) GetMaxDepth() uint32 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+func (x *GetBlockDescendantsRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetBlockDescendantsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
 type GetBlockDescendantsResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
+	// block is the tree of the blocks, only the first page holds the root block
 	Block *Block `protobuf:"bytes,1,opt,name=block,proto3" json:"block,omitempty"`
+	// blocks are the blocks of the page in depth first order
+	Blocks []*Block `protobuf:"bytes,2,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	// next_page_token is empty on the last page
+	NextPageToken string `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
 }
 
 func (x *GetBlockDescendantsResponse) Reset() {
 	*x = GetBlockDescendantsResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[15]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1106,7 +1379,7 @@ func (x *GetBlockDescendantsResponse) String() string {
 func (*GetBlockDescendantsResponse) ProtoMessage() {}
 
 func (x *GetBlockDescendantsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[15]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1119,7 +1392,7 @@ func (x *GetBlockDescendantsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBlockDescendantsResponse.ProtoReflect.Descriptor instead.
 func (*GetBlockDescendantsResponse) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{15}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *GetBlockDescendantsResponse) GetBlock() *Block {
@@ -1129,18 +1402,33 @@ func (x *GetBlockDescendantsResponse) GetBlock() *Block {
 	return nil
 }
 
+func (x *GetBlockDescendantsResponse) GetBlocks() []*Block {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+func (x *GetBlockDescendantsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
 type GetBlockPageRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	BlockId string `protobuf:"bytes,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	BlockId string  `protobuf:"bytes,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	SpaceId *string `protobuf:"bytes,2,opt,name=space_id,json=spaceId,proto3,oneof" json:"space_id,omitempty"`
 }
 
 func (x *GetBlockPageRequest) Reset() {
 	*x = GetBlockPageRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[16]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1153,7 +1441,7 @@ func (x *GetBlockPageRequest) String() string {
 func (*GetBlockPageRequest) ProtoMessage() {}
 
 func (x *GetBlockPageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[16]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1166,7 +1454,7 @@ func (x *GetBlockPageRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBlockPageRequest.ProtoReflect.Descriptor instead.
 func (*GetBlockPageRequest) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{16}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *GetBlockPageRequest) GetBlockId() string {
@@ -1176,18 +1464,30 @@ func (x *GetBlockPageRequest) GetBlockId() string {
 	return ""
 }
 
+func (x *GetBlockPageRequest) GetSpaceId() string {
+	if x != nil && x.SpaceId != nil {
+		return *x.SpaceId
+	}
+	return ""
+}
+
 type GetBlockPageResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
+	// blocks are the page block and its content in tree order, the sub-pages are left out
 	Blocks []*Block `protobuf:"bytes,1,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	// sub_pages are the stubs of the nested pages, without their children and json content
+	SubPages []*Block `protobuf:"bytes,2,rep,name=sub_pages,json=subPages,proto3" json:"sub_pages,omitempty"`
+	// block is the page as a tree with the sub-pages as leaves
+	Block *Block `protobuf:"bytes,3,opt,name=block,proto3" json:"block,omitempty"`
 }
 
 func (x *GetBlockPageResponse) Reset() {
 	*x = GetBlockPageResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[17]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1200,7 +1500,7 @@ func (x *GetBlockPageResponse) String() string {
 func (*GetBlockPageResponse) ProtoMessage() {}
 
 func (x *GetBlockPageResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[17]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1213,7 +1513,7 @@ func (x *GetBlockPageResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBlockPageResponse.ProtoReflect.Descriptor instead.
 func (*GetBlockPageResponse) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{17}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *GetBlockPageResponse) GetBlocks() []*Block {
@@ -1223,19 +1523,36 @@ func (x *GetBlockPageResponse) GetBlocks() []*Block {
 	return nil
 }
 
+func (x *GetBlockPageResponse) GetSubPages() []*Block {
+	if x != nil {
+		return x.SubPages
+	}
+	return nil
+}
+
+func (x *GetBlockPageResponse) GetBlock() *Block {
+	if x != nil {
+		return x.Block
+	}
+	return nil
+}
+
 type GetUpdatesRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	SpaceId       string `protobuf:"bytes,1,opt,name=space_id,json=spaceId,proto3" json:"space_id,omitempty"`
+	SpaceId string `protobuf:"bytes,1,opt,name=space_id,json=spaceId,proto3" json:"space_id,omitempty"`
+	// transaction_id is the last transaction the client has seen, the seq is used when it is empty
 	TransactionId string `protobuf:"bytes,2,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	// seq is the seq of the last transaction the client has seen
+	Seq uint64 `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`
 }
 
 func (x *GetUpdatesRequest) Reset() {
 	*x = GetUpdatesRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[18]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1248,7 +1565,7 @@ func (x *GetUpdatesRequest) String() string {
 func (*GetUpdatesRequest) ProtoMessage() {}
 
 func (x *GetUpdatesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[18]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1261,7 +1578,7 @@ func (x *GetUpdatesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetUpdatesRequest.ProtoReflect.Descriptor instead.
 func (*GetUpdatesRequest) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{18}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *GetUpdatesRequest) GetSpaceId() string {
@@ -1278,6 +1595,13 @@ func (x *GetUpdatesRequest) GetTransactionId() string {
 	return ""
 }
 
+func (x *GetUpdatesRequest) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
 type ChildIds struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1289,7 +1613,7 @@ type ChildIds struct {
 func (x *ChildIds) Reset() {
 	*x = ChildIds{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[19]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[20]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1302,7 +1626,7 @@ func (x *ChildIds) String() string {
 func (*ChildIds) ProtoMessage() {}
 
 func (x *ChildIds) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[19]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[20]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1315,7 +1639,7 @@ func (x *ChildIds) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ChildIds.ProtoReflect.Descriptor instead.
 func (*ChildIds) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{19}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *ChildIds) GetBlockIds() []string {
@@ -1332,12 +1656,15 @@ type GetUpdatesResponse struct {
 
 	Updates map[string]*ChildIds `protobuf:"bytes,1,rep,name=updates,proto3" json:"updates,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	Blocks  []*Block             `protobuf:"bytes,2,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	// transaction_id and seq are the last transaction in the updates, the cursor of the next request
+	TransactionId string `protobuf:"bytes,3,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Seq           uint64 `protobuf:"varint,4,opt,name=seq,proto3" json:"seq,omitempty"`
 }
 
 func (x *GetUpdatesResponse) Reset() {
 	*x = GetUpdatesResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[20]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[21]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1350,7 +1677,7 @@ func (x *GetUpdatesResponse) String() string {
 func (*GetUpdatesResponse) ProtoMessage() {}
 
 func (x *GetUpdatesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[20]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[21]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1363,7 +1690,7 @@ func (x *GetUpdatesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetUpdatesResponse.ProtoReflect.Descriptor instead.
 func (*GetUpdatesResponse) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{20}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *GetUpdatesResponse) GetUpdates() map[string]*ChildIds {
@@ -1380,6 +1707,20 @@ func (x *GetUpdatesResponse) GetBlocks() []*Block {
 	return nil
 }
 
+func (x *GetUpdatesResponse) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *GetUpdatesResponse) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
 type GetBackLinksRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1392,7 +1733,7 @@ type GetBackLinksRequest struct {
 func (x *GetBackLinksRequest) Reset() {
 	*x = GetBackLinksRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[21]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[22]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1405,7 +1746,7 @@ func (x *GetBackLinksRequest) String() string {
 func (*GetBackLinksRequest) ProtoMessage() {}
 
 func (x *GetBackLinksRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[21]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[22]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1418,7 +1759,7 @@ func (x *GetBackLinksRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBackLinksRequest.ProtoReflect.Descriptor instead.
 func (*GetBackLinksRequest) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{21}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *GetBackLinksRequest) GetSpaceId() string {
@@ -1446,7 +1787,7 @@ type GetBackLinksResponse struct {
 func (x *GetBackLinksResponse) Reset() {
 	*x = GetBackLinksResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_apis_v1_blocktree_proto_msgTypes[22]
+		mi := &file_apis_v1_blocktree_proto_msgTypes[23]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1459,7 +1800,7 @@ func (x *GetBackLinksResponse) String() string {
 func (*GetBackLinksResponse) ProtoMessage() {}
 
 func (x *GetBackLinksResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_apis_v1_blocktree_proto_msgTypes[22]
+	mi := &file_apis_v1_blocktree_proto_msgTypes[23]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1472,7 +1813,7 @@ func (x *GetBackLinksResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBackLinksResponse.ProtoReflect.Descriptor instead.
 func (*GetBackLinksResponse) Descriptor() ([]byte, []int) {
-	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{22}
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *GetBackLinksResponse) GetBlocks() []*Block {
@@ -1482,6 +1823,238 @@ func (x *GetBackLinksResponse) GetBlocks() []*Block {
 	return nil
 }
 
+type SubscribeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SpaceId string `protobuf:"bytes,1,opt,name=space_id,json=spaceId,proto3" json:"space_id,omitempty"`
+	// the stream starts with the updates after this transaction
+	AfterTransactionId string `protobuf:"bytes,2,opt,name=after_transaction_id,json=afterTransactionId,proto3" json:"after_transaction_id,omitempty"`
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_apis_v1_blocktree_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_v1_blocktree_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SubscribeRequest) GetSpaceId() string {
+	if x != nil {
+		return x.SpaceId
+	}
+	return ""
+}
+
+func (x *SubscribeRequest) GetAfterTransactionId() string {
+	if x != nil {
+		return x.AfterTransactionId
+	}
+	return ""
+}
+
+type SubscribeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the last transaction included in the updates
+	TransactionId string               `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Updates       map[string]*ChildIds `protobuf:"bytes,2,rep,name=updates,proto3" json:"updates,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Blocks        []*Block             `protobuf:"bytes,3,rep,name=blocks,proto3" json:"blocks,omitempty"`
+}
+
+func (x *SubscribeResponse) Reset() {
+	*x = SubscribeResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_apis_v1_blocktree_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubscribeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeResponse) ProtoMessage() {}
+
+func (x *SubscribeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_v1_blocktree_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeResponse.ProtoReflect.Descriptor instead.
+func (*SubscribeResponse) Descriptor() ([]byte, []int) {
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SubscribeResponse) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *SubscribeResponse) GetUpdates() map[string]*ChildIds {
+	if x != nil {
+		return x.Updates
+	}
+	return nil
+}
+
+func (x *SubscribeResponse) GetBlocks() []*Block {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+type GetSnapshotRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SpaceId string `protobuf:"bytes,1,opt,name=space_id,json=spaceId,proto3" json:"space_id,omitempty"`
+}
+
+func (x *GetSnapshotRequest) Reset() {
+	*x = GetSnapshotRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_apis_v1_blocktree_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSnapshotRequest) ProtoMessage() {}
+
+func (x *GetSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_v1_blocktree_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*GetSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *GetSnapshotRequest) GetSpaceId() string {
+	if x != nil {
+		return x.SpaceId
+	}
+	return ""
+}
+
+type GetSnapshotResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the updates after the snapshot start after this transaction
+	TransactionId string `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	// the ids of the blocks each linked block is linked at
+	BackLinks map[string]*ChildIds `protobuf:"bytes,2,rep,name=back_links,json=backLinks,proto3" json:"back_links,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Blocks    []*Block             `protobuf:"bytes,3,rep,name=blocks,proto3" json:"blocks,omitempty"`
+}
+
+func (x *GetSnapshotResponse) Reset() {
+	*x = GetSnapshotResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_apis_v1_blocktree_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetSnapshotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSnapshotResponse) ProtoMessage() {}
+
+func (x *GetSnapshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_apis_v1_blocktree_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSnapshotResponse.ProtoReflect.Descriptor instead.
+func (*GetSnapshotResponse) Descriptor() ([]byte, []int) {
+	return file_apis_v1_blocktree_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetSnapshotResponse) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *GetSnapshotResponse) GetBackLinks() map[string]*ChildIds {
+	if x != nil {
+		return x.BackLinks
+	}
+	return nil
+}
+
+func (x *GetSnapshotResponse) GetBlocks() []*Block {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
 var File_apis_v1_blocktree_proto protoreflect.FileDescriptor
 
 var file_apis_v1_blocktree_proto_rawDesc = []byte{
@@ -1507,7 +2080,7 @@ var file_apis_v1_blocktree_proto_rawDesc = []byte{
 	0x70, 0x50, 0x72, 0x6f, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20,
 	0x03, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
 	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22,
-	0xc6, 0x02, 0x0a, 0x02, 0x4f, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18,
+	0xf1, 0x02, 0x0a, 0x02, 0x4f, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18,
 	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x19, 0x0a, 0x08,
 	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
 	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x12, 0x20, 0x0a, 0x09, 0x70, 0x61, 0x72, 0x65, 0x6e,
@@ -1524,147 +2097,252 @@ var file_apis_v1_blocktree_proto_rawDesc = []byte{
 	0x6f, 0x70, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x48, 0x03, 0x52, 0x05, 0x70, 0x72, 0x6f,
 	0x70, 0x73, 0x88, 0x01, 0x01, 0x12, 0x19, 0x0a, 0x05, 0x70, 0x61, 0x74, 0x63, 0x68, 0x18, 0x09,
 	0x20, 0x01, 0x28, 0x09, 0x48, 0x04, 0x52, 0x05, 0x70, 0x61, 0x74, 0x63, 0x68, 0x88, 0x01, 0x01,
-	0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x42, 0x09,
-	0x0a, 0x07, 0x5f, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x6c, 0x69,
-	0x6e, 0x6b, 0x65, 0x64, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x70, 0x72, 0x6f, 0x70, 0x73, 0x42, 0x08,
-	0x0a, 0x06, 0x5f, 0x70, 0x61, 0x74, 0x63, 0x68, 0x22, 0xa5, 0x01, 0x0a, 0x0b, 0x54, 0x72, 0x61,
-	0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2f, 0x0a, 0x0e, 0x74, 0x72, 0x61, 0x6e,
-	0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x0d, 0x74, 0x72, 0x61, 0x6e,
-	0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x08, 0x73, 0x70, 0x61,
-	0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05,
-	0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x07, 0x73, 0x70, 0x61, 0x63, 0x65, 0x49, 0x64, 0x12, 0x21,
-	0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x42,
-	0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49,
-	0x64, 0x12, 0x1d, 0x0a, 0x03, 0x6f, 0x70, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b,
-	0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x70, 0x52, 0x03, 0x6f, 0x70, 0x73,
-	0x22, 0x4f, 0x0a, 0x13, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x38, 0x0a, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73,
-	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e,
-	0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74,
-	0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
-	0x73, 0x22, 0xa2, 0x01, 0x0a, 0x16, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x54, 0x72, 0x61, 0x6e, 0x73,
-	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x2f, 0x0a, 0x0e,
-	0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x0d,
-	0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x23, 0x0a,
-	0x08, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42,
-	0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x07, 0x73, 0x70, 0x61, 0x63, 0x65,
-	0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07,
-	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x5b, 0x0a, 0x14, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61,
-	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x43,
-	0x0a, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x41,
-	0x70, 0x70, 0x6c, 0x79, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52,
-	0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
-	0x6f, 0x6e, 0x73, 0x22, 0x4d, 0x0a, 0x12, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x70, 0x61,
-	0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x08, 0x73, 0x70, 0x61,
-	0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05,
-	0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x07, 0x73, 0x70, 0x61, 0x63, 0x65, 0x49, 0x64, 0x12, 0x12,
-	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
-	0x6d, 0x65, 0x22, 0x3a, 0x0a, 0x13, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x70, 0x61, 0x63,
-	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23, 0x0a, 0x08, 0x73, 0x70, 0x61,
-	0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05,
-	0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x07, 0x73, 0x70, 0x61, 0x63, 0x65, 0x49, 0x64, 0x22, 0xd9,
-	0x02, 0x0a, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x25, 0x0a, 0x09, 0x70, 0x61, 0x72, 0x65,
-	0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05,
-	0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x08, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12,
+	0x12, 0x19, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x48,
+	0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x88, 0x01, 0x01, 0x42, 0x0c, 0x0a, 0x0a, 0x5f,
+	0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x6f, 0x62,
+	0x6a, 0x65, 0x63, 0x74, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x65, 0x64, 0x42,
+	0x08, 0x0a, 0x06, 0x5f, 0x70, 0x72, 0x6f, 0x70, 0x73, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x70, 0x61,
+	0x74, 0x63, 0x68, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x4a, 0x04, 0x08,
+	0x0a, 0x10, 0x0b, 0x22, 0x8c, 0x02, 0x0a, 0x0b, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x2f, 0x0a, 0x0e, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05,
+	0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x0d, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x08, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01,
+	0x52, 0x07, 0x73, 0x70, 0x61, 0x63, 0x65, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x07, 0x75, 0x73, 0x65,
+	0x72, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72,
+	0x03, 0xb0, 0x01, 0x01, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x03,
+	0x6f, 0x70, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x61, 0x70, 0x69, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x70, 0x52, 0x03, 0x6f, 0x70, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x63,
+	0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x63, 0x6c, 0x6f, 0x63,
+	0x6b, 0x12, 0x3e, 0x0a, 0x0c, 0x70, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x50, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00,
+	0x52, 0x0c, 0x70, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01,
+	0x01, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x70, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x22, 0xd3, 0x01, 0x0a, 0x0c, 0x50, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x15, 0x0a, 0x03, 0x73, 0x65, 0x71, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x48, 0x00, 0x52, 0x03, 0x73, 0x65, 0x71, 0x88, 0x01, 0x01, 0x12, 0x26, 0x0a, 0x08, 0x62, 0x6c,
+	0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x0b, 0xfa, 0x42,
+	0x08, 0x72, 0x06, 0xd0, 0x01, 0x01, 0xb0, 0x01, 0x01, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x49, 0x64, 0x12, 0x3f, 0x0a, 0x08, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x50,
+	0x72, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x1a, 0x3b, 0x0a, 0x0d, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x42, 0x06, 0x0a, 0x04, 0x5f, 0x73, 0x65, 0x71, 0x22, 0x4f, 0x0a, 0x13, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x38, 0x0a, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0xf1, 0x01, 0x0a, 0x16, 0x41, 0x70,
+	0x70, 0x6c, 0x79, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x12, 0x2f, 0x0a, 0x0e, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42,
+	0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x0d, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x08, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01,
+	0x01, 0x52, 0x07, 0x73, 0x70, 0x61, 0x63, 0x65, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75,
+	0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63,
+	0x63, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2c,
+	0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x61,
+	0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x52, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x1f, 0x0a, 0x0b,
+	0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x5f, 0x6f, 0x70, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28,
+	0x0d, 0x52, 0x0a, 0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x4f, 0x70, 0x73, 0x22, 0x5b, 0x0a,
+	0x14, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x43, 0x0a, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x61, 0x70,
+	0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x0c, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x4d, 0x0a, 0x12, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x53, 0x70, 0x61, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x23, 0x0a, 0x08, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x07, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x3a, 0x0a, 0x13, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x53, 0x70, 0x61, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x23, 0x0a, 0x08, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x07, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x49, 0x64, 0x22, 0x98, 0x03, 0x0a, 0x05, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12,
+	0x25, 0x0a, 0x09, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x08, 0x70, 0x61,
+	0x72, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0,
+	0x01, 0x01, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6f,
+	0x62, 0x6a, 0x65, 0x63, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6f, 0x62, 0x6a,
+	0x65, 0x63, 0x74, 0x12, 0x2a, 0x0a, 0x08, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x18,
+	0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x08, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x12,
+	0x26, 0x0a, 0x06, 0x6c, 0x69, 0x6e, 0x6b, 0x65, 0x64, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x0e, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52,
+	0x06, 0x6c, 0x69, 0x6e, 0x6b, 0x65, 0x64, 0x12, 0x17, 0x0a, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x88, 0x01, 0x01,
+	0x12, 0x19, 0x0a, 0x05, 0x70, 0x72, 0x6f, 0x70, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x48,
+	0x01, 0x52, 0x05, 0x70, 0x72, 0x6f, 0x70, 0x73, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a, 0x07, 0x64,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x48, 0x02, 0x52, 0x07,
+	0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a, 0x06, 0x65, 0x72,
+	0x61, 0x73, 0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x48, 0x03, 0x52, 0x06, 0x65, 0x72,
+	0x61, 0x73, 0x65, 0x64, 0x88, 0x01, 0x01, 0x12, 0x19, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x48, 0x04, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x88,
+	0x01, 0x01, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0b, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x42, 0x07, 0x0a, 0x05,
+	0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x70, 0x72, 0x6f, 0x70, 0x73, 0x42,
+	0x0a, 0x0a, 0x08, 0x5f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x42, 0x09, 0x0a, 0x07, 0x5f,
+	0x65, 0x72, 0x61, 0x73, 0x65, 0x64, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x22, 0x6d, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x28, 0x0a, 0x08, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x48,
+	0x00, 0x52, 0x07, 0x73, 0x70, 0x61, 0x63, 0x65, 0x49, 0x64, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a,
+	0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42,
+	0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x49, 0x64, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x22,
+	0x38, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x52, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0xaa, 0x01, 0x0a, 0x17, 0x47, 0x65,
+	0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x28, 0x0a, 0x08, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01,
+	0x01, 0x48, 0x00, 0x52, 0x07, 0x73, 0x70, 0x61, 0x63, 0x65, 0x49, 0x64, 0x88, 0x01, 0x01, 0x12,
 	0x23, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
 	0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x07, 0x62, 0x6c, 0x6f,
-	0x63, 0x6b, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x12, 0x2a, 0x0a, 0x08,
-	0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e,
-	0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x08,
-	0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x12, 0x26, 0x0a, 0x06, 0x6c, 0x69, 0x6e, 0x6b,
-	0x65, 0x64, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e,
-	0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x06, 0x6c, 0x69, 0x6e, 0x6b, 0x65, 0x64,
-	0x12, 0x17, 0x0a, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00,
-	0x52, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x19, 0x0a, 0x05, 0x70, 0x72, 0x6f,
-	0x70, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x05, 0x70, 0x72, 0x6f, 0x70,
-	0x73, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18,
-	0x08, 0x20, 0x01, 0x28, 0x08, 0x48, 0x02, 0x52, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64,
-	0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a, 0x06, 0x65, 0x72, 0x61, 0x73, 0x65, 0x64, 0x18, 0x09, 0x20,
-	0x01, 0x28, 0x08, 0x48, 0x03, 0x52, 0x06, 0x65, 0x72, 0x61, 0x73, 0x65, 0x64, 0x88, 0x01, 0x01,
-	0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x70, 0x72,
-	0x6f, 0x70, 0x73, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x42,
-	0x09, 0x0a, 0x07, 0x5f, 0x65, 0x72, 0x61, 0x73, 0x65, 0x64, 0x22, 0x6d, 0x0a, 0x0f, 0x47, 0x65,
-	0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x28, 0x0a,
-	0x08, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42,
-	0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x48, 0x00, 0x52, 0x07, 0x73, 0x70, 0x61,
-	0x63, 0x65, 0x49, 0x64, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
-	0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03,
-	0xb0, 0x01, 0x01, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x42, 0x0b, 0x0a, 0x09,
-	0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x22, 0x38, 0x0a, 0x10, 0x47, 0x65, 0x74,
-	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a,
-	0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61,
-	0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x05, 0x62, 0x6c,
-	0x6f, 0x63, 0x6b, 0x22, 0x75, 0x0a, 0x17, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x43,
-	0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x28,
-	0x0a, 0x08, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x48, 0x00, 0x52, 0x07, 0x73, 0x70,
-	0x61, 0x63, 0x65, 0x49, 0x64, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63,
-	0x6b, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72,
-	0x03, 0xb0, 0x01, 0x01, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x42, 0x0b, 0x0a,
-	0x09, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x22, 0x42, 0x0a, 0x18, 0x47, 0x65,
-	0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x26, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73,
-	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31,
-	0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x22, 0x78,
-	0x0a, 0x1a, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x44, 0x65, 0x73, 0x63, 0x65, 0x6e,
-	0x64, 0x61, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x28, 0x0a, 0x08,
-	0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08,
-	0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x48, 0x00, 0x52, 0x07, 0x73, 0x70, 0x61, 0x63,
-	0x65, 0x49, 0x64, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f,
-	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0,
-	0x01, 0x01, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x42, 0x0b, 0x0a, 0x09, 0x5f,
-	0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x22, 0x43, 0x0a, 0x1b, 0x47, 0x65, 0x74, 0x42,
-	0x6c, 0x6f, 0x63, 0x6b, 0x44, 0x65, 0x73, 0x63, 0x65, 0x6e, 0x64, 0x61, 0x6e, 0x74, 0x73, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31,
-	0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x3a, 0x0a,
-	0x13, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x50, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01,
-	0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x22, 0x3e, 0x0a, 0x14, 0x47, 0x65, 0x74,
+	0x63, 0x6b, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61,
+	0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x22, 0x6a, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x26, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x65,
+	0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x22, 0xca, 0x01, 0x0a, 0x1a, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x44,
+	0x65, 0x73, 0x63, 0x65, 0x6e, 0x64, 0x61, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x28, 0x0a, 0x08, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x48, 0x00, 0x52,
+	0x07, 0x73, 0x70, 0x61, 0x63, 0x65, 0x49, 0x64, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x08, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa,
+	0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64,
+	0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x44, 0x65, 0x70, 0x74, 0x68, 0x12, 0x14, 0x0a,
+	0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65,
+	0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x22,
+	0x93, 0x01, 0x0a, 0x1b, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x44, 0x65, 0x73, 0x63,
+	0x65, 0x6e, 0x64, 0x61, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x24, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e,
+	0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x05,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x26, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x26, 0x0a,
+	0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65,
+	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x71, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63,
+	0x6b, 0x50, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x08,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08,
+	0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49,
+	0x64, 0x12, 0x28, 0x0a, 0x08, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x48, 0x00, 0x52,
+	0x07, 0x73, 0x70, 0x61, 0x63, 0x65, 0x49, 0x64, 0x88, 0x01, 0x01, 0x42, 0x0b, 0x0a, 0x09, 0x5f,
+	0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x22, 0x91, 0x01, 0x0a, 0x14, 0x47, 0x65, 0x74,
 	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x50, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
 	0x65, 0x12, 0x26, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
 	0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f, 0x63,
-	0x6b, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x22, 0x69, 0x0a, 0x11, 0x47, 0x65, 0x74,
-	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23,
-	0x0a, 0x08, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x07, 0x73, 0x70, 0x61, 0x63,
-	0x65, 0x49, 0x64, 0x12, 0x2f, 0x0a, 0x0e, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
-	0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05,
-	0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x0d, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
-	0x6f, 0x6e, 0x49, 0x64, 0x22, 0x27, 0x0a, 0x08, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x49, 0x64, 0x73,
-	0x12, 0x1b, 0x0a, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20,
-	0x03, 0x28, 0x09, 0x52, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x73, 0x22, 0xcf, 0x01,
-	0x0a, 0x12, 0x47, 0x65, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a, 0x07, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x18,
-	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e,
-	0x47, 0x65, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
-	0x07, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x12, 0x26, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63,
-	0x6b, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e,
-	0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73,
-	0x1a, 0x4d, 0x0a, 0x0c, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
-	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
-	0x65, 0x79, 0x12, 0x27, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x69, 0x6c,
-	0x64, 0x49, 0x64, 0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22,
-	0x5f, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x42, 0x61, 0x63, 0x6b, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x08, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f,
-	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0,
-	0x01, 0x01, 0x52, 0x07, 0x73, 0x70, 0x61, 0x63, 0x65, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x08, 0x62,
-	0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa,
-	0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64,
-	0x22, 0x3e, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x42, 0x61, 0x63, 0x6b, 0x4c, 0x69, 0x6e, 0x6b, 0x73,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x26, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63,
-	0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e,
-	0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73,
-	0x2a, 0xe2, 0x01, 0x0a, 0x06, 0x4f, 0x70, 0x54, 0x79, 0x70, 0x65, 0x12, 0x13, 0x0a, 0x0f, 0x4f,
+	0x6b, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x2b, 0x0a, 0x09, 0x73, 0x75, 0x62,
+	0x5f, 0x70, 0x61, 0x67, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61,
+	0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x08, 0x73, 0x75,
+	0x62, 0x50, 0x61, 0x67, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x7e, 0x0a, 0x11,
+	0x47, 0x65, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x23, 0x0a, 0x08, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x07, 0x73,
+	0x70, 0x61, 0x63, 0x65, 0x49, 0x64, 0x12, 0x32, 0x0a, 0x0e, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x0b,
+	0xfa, 0x42, 0x08, 0x72, 0x06, 0xd0, 0x01, 0x01, 0xb0, 0x01, 0x01, 0x52, 0x0d, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x65,
+	0x71, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x73, 0x65, 0x71, 0x22, 0x27, 0x0a, 0x08,
+	0x43, 0x68, 0x69, 0x6c, 0x64, 0x49, 0x64, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x49, 0x64, 0x73, 0x22, 0x88, 0x02, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a, 0x07,
+	0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e,
+	0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73,
+	0x12, 0x26, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x74, 0x72, 0x61, 0x6e,
+	0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0d, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12,
+	0x10, 0x0a, 0x03, 0x73, 0x65, 0x71, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x73, 0x65,
+	0x71, 0x1a, 0x4d, 0x0a, 0x0c, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x27, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x69,
+	0x6c, 0x64, 0x49, 0x64, 0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x22, 0x5f, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x42, 0x61, 0x63, 0x6b, 0x4c, 0x69, 0x6e, 0x6b, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x08, 0x73, 0x70, 0x61, 0x63, 0x65,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03,
+	0xb0, 0x01, 0x01, 0x52, 0x07, 0x73, 0x70, 0x61, 0x63, 0x65, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x08,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08,
+	0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49,
+	0x64, 0x22, 0x3e, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x42, 0x61, 0x63, 0x6b, 0x4c, 0x69, 0x6e, 0x6b,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x26, 0x0a, 0x06, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x73, 0x22, 0x73, 0x0a, 0x10, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x08, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01,
+	0x01, 0x52, 0x07, 0x73, 0x70, 0x61, 0x63, 0x65, 0x49, 0x64, 0x12, 0x3a, 0x0a, 0x14, 0x61, 0x66,
+	0x74, 0x65, 0x72, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0,
+	0x01, 0x01, 0x52, 0x12, 0x61, 0x66, 0x74, 0x65, 0x72, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x22, 0xfe, 0x01, 0x0a, 0x11, 0x53, 0x75, 0x62, 0x73, 0x63,
+	0x72, 0x69, 0x62, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2f, 0x0a, 0x0e,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x0d,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x41, 0x0a,
+	0x07, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x27,
+	0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69,
+	0x62, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73,
+	0x12, 0x26, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x1a, 0x4d, 0x0a, 0x0c, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x27, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x49, 0x64, 0x73, 0x52, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x39, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x53, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a,
+	0x08, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42,
+	0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x07, 0x73, 0x70, 0x61, 0x63, 0x65,
+	0x49, 0x64, 0x22, 0x8b, 0x02, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68,
+	0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2f, 0x0a, 0x0e, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x42, 0x08, 0xfa, 0x42, 0x05, 0x72, 0x03, 0xb0, 0x01, 0x01, 0x52, 0x0d, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x4a, 0x0a, 0x0a, 0x62,
+	0x61, 0x63, 0x6b, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x2b, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x6e, 0x61,
+	0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x42, 0x61,
+	0x63, 0x6b, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x09, 0x62, 0x61,
+	0x63, 0x6b, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x12, 0x26, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x1a,
+	0x4f, 0x0a, 0x0e, 0x42, 0x61, 0x63, 0x6b, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x27, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x69,
+	0x6c, 0x64, 0x49, 0x64, 0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x2a, 0xf4, 0x01, 0x0a, 0x06, 0x4f, 0x70, 0x54, 0x79, 0x70, 0x65, 0x12, 0x13, 0x0a, 0x0f, 0x4f,
 	0x50, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00,
 	0x12, 0x12, 0x0a, 0x0e, 0x4f, 0x50, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x49, 0x4e, 0x53, 0x45,
 	0x52, 0x54, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x4f, 0x50, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
@@ -1678,82 +2356,125 @@ var file_apis_v1_blocktree_proto_rawDesc = []byte{
 	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x52, 0x45, 0x53, 0x54, 0x4f, 0x52, 0x45, 0x10, 0x08, 0x12,
 	0x10, 0x0a, 0x0c, 0x4f, 0x50, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4c, 0x49, 0x4e, 0x4b, 0x10,
 	0x09, 0x12, 0x12, 0x0a, 0x0e, 0x4f, 0x50, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x4c,
-	0x49, 0x4e, 0x4b, 0x10, 0x0a, 0x2a, 0x9e, 0x01, 0x0a, 0x0f, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x65,
-	0x72, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x18, 0x50, 0x4f, 0x49,
-	0x4e, 0x54, 0x45, 0x52, 0x5f, 0x50, 0x4f, 0x53, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x55, 0x4e,
-	0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x1b, 0x0a, 0x17, 0x50, 0x4f, 0x49, 0x4e, 0x54,
-	0x45, 0x52, 0x5f, 0x50, 0x4f, 0x53, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x42, 0x45, 0x46, 0x4f,
-	0x52, 0x45, 0x10, 0x01, 0x12, 0x1a, 0x0a, 0x16, 0x50, 0x4f, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x5f,
-	0x50, 0x4f, 0x53, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x41, 0x46, 0x54, 0x45, 0x52, 0x10, 0x02,
-	0x12, 0x1a, 0x0a, 0x16, 0x50, 0x4f, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x5f, 0x50, 0x4f, 0x53, 0x49,
-	0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x53, 0x54, 0x41, 0x52, 0x54, 0x10, 0x03, 0x12, 0x18, 0x0a, 0x14,
-	0x50, 0x4f, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x5f, 0x50, 0x4f, 0x53, 0x49, 0x54, 0x49, 0x4f, 0x4e,
-	0x5f, 0x45, 0x4e, 0x44, 0x10, 0x04, 0x32, 0x88, 0x08, 0x0a, 0x09, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
-	0x74, 0x72, 0x65, 0x65, 0x12, 0x6b, 0x0a, 0x05, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x12, 0x1c, 0x2e,
-	0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74,
-	0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x61, 0x70,
-	0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f,
-	0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x25, 0x92, 0x41, 0x07, 0x2a,
-	0x05, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x15, 0x3a, 0x01, 0x2a, 0x22,
-	0x10, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
-	0x73, 0x12, 0x6f, 0x0a, 0x0b, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x70, 0x61, 0x63, 0x65,
-	0x12, 0x1b, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74,
-	0x65, 0x53, 0x70, 0x61, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e,
-	0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x70,
-	0x61, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x25, 0x92, 0x41, 0x0d,
-	0x2a, 0x0b, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x70, 0x61, 0x63, 0x65, 0x82, 0xd3, 0xe4,
-	0x93, 0x02, 0x0f, 0x3a, 0x01, 0x2a, 0x22, 0x0a, 0x2f, 0x76, 0x31, 0x2f, 0x73, 0x70, 0x61, 0x63,
-	0x65, 0x73, 0x12, 0x6b, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x18,
+	0x49, 0x4e, 0x4b, 0x10, 0x0a, 0x12, 0x10, 0x0a, 0x0c, 0x4f, 0x50, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x54, 0x45, 0x58, 0x54, 0x10, 0x0b, 0x2a, 0xbb, 0x01, 0x0a, 0x0f, 0x50, 0x6f, 0x69, 0x6e,
+	0x74, 0x65, 0x72, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x18, 0x50,
+	0x4f, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x5f, 0x50, 0x4f, 0x53, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f,
+	0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x1b, 0x0a, 0x17, 0x50, 0x4f, 0x49,
+	0x4e, 0x54, 0x45, 0x52, 0x5f, 0x50, 0x4f, 0x53, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x42, 0x45,
+	0x46, 0x4f, 0x52, 0x45, 0x10, 0x01, 0x12, 0x1a, 0x0a, 0x16, 0x50, 0x4f, 0x49, 0x4e, 0x54, 0x45,
+	0x52, 0x5f, 0x50, 0x4f, 0x53, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x41, 0x46, 0x54, 0x45, 0x52,
+	0x10, 0x02, 0x12, 0x1a, 0x0a, 0x16, 0x50, 0x4f, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x5f, 0x50, 0x4f,
+	0x53, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x53, 0x54, 0x41, 0x52, 0x54, 0x10, 0x03, 0x12, 0x18,
+	0x0a, 0x14, 0x50, 0x4f, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x5f, 0x50, 0x4f, 0x53, 0x49, 0x54, 0x49,
+	0x4f, 0x4e, 0x5f, 0x45, 0x4e, 0x44, 0x10, 0x04, 0x12, 0x1b, 0x0a, 0x17, 0x50, 0x4f, 0x49, 0x4e,
+	0x54, 0x45, 0x52, 0x5f, 0x50, 0x4f, 0x53, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x49, 0x4e, 0x53,
+	0x49, 0x44, 0x45, 0x10, 0x05, 0x2a, 0x84, 0x03, 0x0a, 0x0f, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x52,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x1d, 0x0a, 0x19, 0x41, 0x50, 0x50,
+	0x4c, 0x59, 0x5f, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x55,
+	0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x18, 0x0a, 0x14, 0x41, 0x50, 0x50, 0x4c,
+	0x59, 0x5f, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x4f, 0x4b,
+	0x10, 0x01, 0x12, 0x1f, 0x0a, 0x1b, 0x41, 0x50, 0x50, 0x4c, 0x59, 0x5f, 0x52, 0x45, 0x53, 0x55,
+	0x4c, 0x54, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x4e, 0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x55, 0x4e,
+	0x44, 0x10, 0x02, 0x12, 0x1b, 0x0a, 0x17, 0x41, 0x50, 0x50, 0x4c, 0x59, 0x5f, 0x52, 0x45, 0x53,
+	0x55, 0x4c, 0x54, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x43, 0x59, 0x43, 0x4c, 0x45, 0x10, 0x03,
+	0x12, 0x26, 0x0a, 0x22, 0x41, 0x50, 0x50, 0x4c, 0x59, 0x5f, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54,
+	0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f, 0x50, 0x4f,
+	0x53, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x04, 0x12, 0x1f, 0x0a, 0x1b, 0x41, 0x50, 0x50, 0x4c,
+	0x59, 0x5f, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x44, 0x55,
+	0x50, 0x4c, 0x49, 0x43, 0x41, 0x54, 0x45, 0x10, 0x05, 0x12, 0x1e, 0x0a, 0x1a, 0x41, 0x50, 0x50,
+	0x4c, 0x59, 0x5f, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x43,
+	0x4f, 0x4e, 0x46, 0x4c, 0x49, 0x43, 0x54, 0x10, 0x06, 0x12, 0x27, 0x0a, 0x23, 0x41, 0x50, 0x50,
+	0x4c, 0x59, 0x5f, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x53,
+	0x4e, 0x41, 0x50, 0x53, 0x48, 0x4f, 0x54, 0x5f, 0x52, 0x45, 0x51, 0x55, 0x49, 0x52, 0x45, 0x44,
+	0x10, 0x07, 0x12, 0x1e, 0x0a, 0x1a, 0x41, 0x50, 0x50, 0x4c, 0x59, 0x5f, 0x52, 0x45, 0x53, 0x55,
+	0x4c, 0x54, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x4e, 0x41, 0x4c,
+	0x10, 0x08, 0x12, 0x20, 0x0a, 0x1c, 0x41, 0x50, 0x50, 0x4c, 0x59, 0x5f, 0x52, 0x45, 0x53, 0x55,
+	0x4c, 0x54, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f,
+	0x4f, 0x50, 0x10, 0x09, 0x12, 0x26, 0x0a, 0x22, 0x41, 0x50, 0x50, 0x4c, 0x59, 0x5f, 0x52, 0x45,
+	0x53, 0x55, 0x4c, 0x54, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f, 0x53, 0x43, 0x48, 0x45, 0x4d, 0x41,
+	0x5f, 0x56, 0x49, 0x4f, 0x4c, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x0a, 0x32, 0x82, 0x0a, 0x0a,
+	0x09, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x74, 0x72, 0x65, 0x65, 0x12, 0x6b, 0x0a, 0x05, 0x41, 0x70,
+	0x70, 0x6c, 0x79, 0x12, 0x1c, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1d, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x25, 0x92, 0x41, 0x07, 0x2a, 0x05, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x82, 0xd3, 0xe4, 0x93,
+	0x02, 0x15, 0x3a, 0x01, 0x2a, 0x22, 0x10, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x72, 0x61, 0x6e, 0x73,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x6f, 0x0a, 0x0b, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x53, 0x70, 0x61, 0x63, 0x65, 0x12, 0x1b, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x70, 0x61, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x53, 0x70, 0x61, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x25, 0x92, 0x41, 0x0d, 0x2a, 0x0b, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x70,
+	0x61, 0x63, 0x65, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0f, 0x3a, 0x01, 0x2a, 0x22, 0x0a, 0x2f, 0x76,
+	0x31, 0x2f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x73, 0x12, 0x6b, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x18, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19,
 	0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63,
-	0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e,
-	0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x22, 0x2a, 0x92, 0x41, 0x0a, 0x2a, 0x08, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f,
-	0x63, 0x6b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x17, 0x12, 0x15, 0x2f, 0x76, 0x31, 0x2f, 0x62, 0x6c,
-	0x6f, 0x63, 0x6b, 0x73, 0x2f, 0x7b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x7d, 0x12,
-	0x8a, 0x01, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x12,
-	0x20, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f,
-	0x63, 0x6b, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x21, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42,
-	0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x36, 0x92, 0x41, 0x0d, 0x2a, 0x0b, 0x47, 0x65, 0x74, 0x43, 0x68,
-	0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x20, 0x12, 0x1e, 0x2f, 0x76,
-	0x31, 0x2f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2f, 0x7b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f,
-	0x69, 0x64, 0x7d, 0x2f, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x12, 0x99, 0x01, 0x0a,
-	0x0e, 0x47, 0x65, 0x74, 0x44, 0x65, 0x73, 0x63, 0x65, 0x6e, 0x64, 0x61, 0x6e, 0x74, 0x73, 0x12,
-	0x23, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f,
-	0x63, 0x6b, 0x44, 0x65, 0x73, 0x63, 0x65, 0x6e, 0x64, 0x61, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47,
-	0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x44, 0x65, 0x73, 0x63, 0x65, 0x6e, 0x64, 0x61, 0x6e,
-	0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x3c, 0x92, 0x41, 0x10, 0x2a,
-	0x0e, 0x47, 0x65, 0x74, 0x44, 0x65, 0x73, 0x63, 0x65, 0x6e, 0x64, 0x61, 0x6e, 0x74, 0x73, 0x82,
-	0xd3, 0xe4, 0x93, 0x02, 0x23, 0x12, 0x21, 0x2f, 0x76, 0x31, 0x2f, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
-	0x73, 0x2f, 0x7b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x64, 0x65, 0x73,
-	0x63, 0x65, 0x6e, 0x64, 0x61, 0x6e, 0x74, 0x73, 0x12, 0x76, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x50,
-	0x61, 0x67, 0x65, 0x12, 0x1c, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65,
-	0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x50, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x1d, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42,
-	0x6c, 0x6f, 0x63, 0x6b, 0x50, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x22, 0x2e, 0x92, 0x41, 0x09, 0x2a, 0x07, 0x47, 0x65, 0x74, 0x50, 0x61, 0x67, 0x65, 0x82, 0xd3,
-	0xe4, 0x93, 0x02, 0x1c, 0x12, 0x1a, 0x2f, 0x76, 0x31, 0x2f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73,
-	0x2f, 0x7b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x70, 0x61, 0x67, 0x65,
-	0x12, 0x86, 0x01, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x42, 0x61, 0x63, 0x6b, 0x4c, 0x69, 0x6e, 0x6b,
-	0x73, 0x12, 0x1c, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42,
-	0x61, 0x63, 0x6b, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x1d, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x61, 0x63,
-	0x6b, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x39,
-	0x92, 0x41, 0x0e, 0x2a, 0x0c, 0x47, 0x65, 0x74, 0x42, 0x61, 0x63, 0x6b, 0x4c, 0x69, 0x6e, 0x6b,
-	0x73, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x22, 0x12, 0x20, 0x2f, 0x76, 0x31, 0x2f, 0x62, 0x6c, 0x6f,
-	0x63, 0x6b, 0x73, 0x2f, 0x7b, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x62,
-	0x61, 0x63, 0x6b, 0x2d, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x12, 0x85, 0x01, 0x0a, 0x0a, 0x47, 0x65,
-	0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x12, 0x1a, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e,
-	0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47,
-	0x65, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x22, 0x3e, 0x92, 0x41, 0x0c, 0x2a, 0x0a, 0x47, 0x65, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74,
-	0x65, 0x73, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x29, 0x12, 0x27, 0x2f, 0x76, 0x31, 0x2f, 0x75, 0x70,
-	0x64, 0x61, 0x74, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x7d,
-	0x2f, 0x7b, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64,
-	0x7d, 0x42, 0x26, 0x5a, 0x24, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x2a, 0x92, 0x41, 0x0a, 0x2a, 0x08,
+	0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x17, 0x12, 0x15,
+	0x2f, 0x76, 0x31, 0x2f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2f, 0x7b, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x5f, 0x69, 0x64, 0x7d, 0x12, 0x8a, 0x01, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x43, 0x68, 0x69,
+	0x6c, 0x64, 0x72, 0x65, 0x6e, 0x12, 0x20, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72,
+	0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x36, 0x92, 0x41, 0x0d, 0x2a,
+	0x0b, 0x47, 0x65, 0x74, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x82, 0xd3, 0xe4, 0x93,
+	0x02, 0x20, 0x12, 0x1e, 0x2f, 0x76, 0x31, 0x2f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2f, 0x7b,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72,
+	0x65, 0x6e, 0x12, 0x99, 0x01, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x44, 0x65, 0x73, 0x63, 0x65, 0x6e,
+	0x64, 0x61, 0x6e, 0x74, 0x73, 0x12, 0x23, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x44, 0x65, 0x73, 0x63, 0x65, 0x6e, 0x64, 0x61,
+	0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x61, 0x70, 0x69,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x44, 0x65, 0x73,
+	0x63, 0x65, 0x6e, 0x64, 0x61, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x3c, 0x92, 0x41, 0x10, 0x2a, 0x0e, 0x47, 0x65, 0x74, 0x44, 0x65, 0x73, 0x63, 0x65, 0x6e,
+	0x64, 0x61, 0x6e, 0x74, 0x73, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x23, 0x12, 0x21, 0x2f, 0x76, 0x31,
+	0x2f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2f, 0x7b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69,
+	0x64, 0x7d, 0x2f, 0x64, 0x65, 0x73, 0x63, 0x65, 0x6e, 0x64, 0x61, 0x6e, 0x74, 0x73, 0x12, 0x76,
+	0x0a, 0x07, 0x47, 0x65, 0x74, 0x50, 0x61, 0x67, 0x65, 0x12, 0x1c, 0x2e, 0x61, 0x70, 0x69, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x50, 0x61, 0x67, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x50, 0x61, 0x67, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x2e, 0x92, 0x41, 0x09, 0x2a, 0x07, 0x47, 0x65, 0x74,
+	0x50, 0x61, 0x67, 0x65, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1c, 0x12, 0x1a, 0x2f, 0x76, 0x31, 0x2f,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2f, 0x7b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64,
+	0x7d, 0x2f, 0x70, 0x61, 0x67, 0x65, 0x12, 0x86, 0x01, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x42, 0x61,
+	0x63, 0x6b, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x12, 0x1c, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x61, 0x63, 0x6b, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x47, 0x65, 0x74, 0x42, 0x61, 0x63, 0x6b, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x39, 0x92, 0x41, 0x0e, 0x2a, 0x0c, 0x47, 0x65, 0x74, 0x42, 0x61,
+	0x63, 0x6b, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x22, 0x12, 0x20, 0x2f,
+	0x76, 0x31, 0x2f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2f, 0x7b, 0x73, 0x70, 0x61, 0x63, 0x65,
+	0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x62, 0x61, 0x63, 0x6b, 0x2d, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x12,
+	0xa6, 0x01, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x12, 0x1a,
+	0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x61, 0x70, 0x69,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x5f, 0x92, 0x41, 0x0c, 0x2a, 0x0a, 0x47, 0x65,
+	0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x4a, 0x5a, 0x1f,
+	0x12, 0x1d, 0x2f, 0x76, 0x31, 0x2f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x12,
+	0x27, 0x2f, 0x76, 0x31, 0x2f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x7b, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x7d, 0x12, 0x80, 0x01, 0x0a, 0x0b, 0x47, 0x65, 0x74,
+	0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x1b, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x47, 0x65, 0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x36, 0x92, 0x41, 0x0d, 0x2a, 0x0b, 0x47, 0x65, 0x74, 0x53, 0x6e, 0x61,
+	0x70, 0x73, 0x68, 0x6f, 0x74, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x20, 0x12, 0x1e, 0x2f, 0x76, 0x31,
+	0x2f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x73, 0x2f, 0x7b, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x69,
+	0x64, 0x7d, 0x2f, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x54, 0x0a, 0x09, 0x53,
+	0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x12, 0x19, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75,
+	0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x0e, 0x92, 0x41, 0x0b, 0x2a, 0x09, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x30,
+	0x01, 0x42, 0x26, 0x5a, 0x24, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
 	0x65, 0x6d, 0x72, 0x67, 0x65, 0x6e, 0x2f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x74, 0x72, 0x65, 0x65,
 	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
 	0x33,
@@ -1771,74 +2492,99 @@ func file_apis_v1_blocktree_proto_rawDescGZIP() []byte {
 	return file_apis_v1_blocktree_proto_rawDescData
 }
 
-var file_apis_v1_blocktree_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_apis_v1_blocktree_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_apis_v1_blocktree_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_apis_v1_blocktree_proto_msgTypes = make([]protoimpl.MessageInfo, 32)
 var file_apis_v1_blocktree_proto_goTypes = []interface{}{
 	(OpType)(0),                         // 0: apis.v1.OpType
 	(PointerPosition)(0),                // 1: apis.v1.PointerPosition
-	(*Pointer)(nil),                     // 2: apis.v1.Pointer
-	(*OpProp)(nil),                      // 3: apis.v1.OpProp
-	(*Op)(nil),                          // 4: apis.v1.Op
-	(*Transaction)(nil),                 // 5: apis.v1.Transaction
-	(*TransactionsRequest)(nil),         // 6: apis.v1.TransactionsRequest
-	(*ApplyTransactionResult)(nil),      // 7: apis.v1.ApplyTransactionResult
-	(*TransactionsResponse)(nil),        // 8: apis.v1.TransactionsResponse
-	(*CreateSpaceRequest)(nil),          // 9: apis.v1.CreateSpaceRequest
-	(*CreateSpaceResponse)(nil),         // 10: apis.v1.CreateSpaceResponse
-	(*Block)(nil),                       // 11: apis.v1.Block
-	(*GetBlockRequest)(nil),             // 12: apis.v1.GetBlockRequest
-	(*GetBlockResponse)(nil),            // 13: apis.v1.GetBlockResponse
-	(*GetBlockChildrenRequest)(nil),     // 14: apis.v1.GetBlockChildrenRequest
-	(*GetBlockChildrenResponse)(nil),    // 15: apis.v1.GetBlockChildrenResponse
-	(*GetBlockDescendantsRequest)(nil),  // 16: apis.v1.GetBlockDescendantsRequest
-	(*GetBlockDescendantsResponse)(nil), // 17: apis.v1.GetBlockDescendantsResponse
-	(*GetBlockPageRequest)(nil),         // 18: apis.v1.GetBlockPageRequest
-	(*GetBlockPageResponse)(nil),        // 19: apis.v1.GetBlockPageResponse
-	(*GetUpdatesRequest)(nil),           // 20: apis.v1.GetUpdatesRequest
-	(*ChildIds)(nil),                    // 21: apis.v1.ChildIds
-	(*GetUpdatesResponse)(nil),          // 22: apis.v1.GetUpdatesResponse
-	(*GetBackLinksRequest)(nil),         // 23: apis.v1.GetBackLinksRequest
-	(*GetBackLinksResponse)(nil),        // 24: apis.v1.GetBackLinksResponse
-	nil,                                 // 25: apis.v1.GetUpdatesResponse.UpdatesEntry
+	(ApplyResultCode)(0),                // 2: apis.v1.ApplyResultCode
+	(*Pointer)(nil),                     // 3: apis.v1.Pointer
+	(*OpProp)(nil),                      // 4: apis.v1.OpProp
+	(*Op)(nil),                          // 5: apis.v1.Op
+	(*Transaction)(nil),                 // 6: apis.v1.Transaction
+	(*Precondition)(nil),                // 7: apis.v1.Precondition
+	(*TransactionsRequest)(nil),         // 8: apis.v1.TransactionsRequest
+	(*ApplyTransactionResult)(nil),      // 9: apis.v1.ApplyTransactionResult
+	(*TransactionsResponse)(nil),        // 10: apis.v1.TransactionsResponse
+	(*CreateSpaceRequest)(nil),          // 11: apis.v1.CreateSpaceRequest
+	(*CreateSpaceResponse)(nil),         // 12: apis.v1.CreateSpaceResponse
+	(*Block)(nil),                       // 13: apis.v1.Block
+	(*GetBlockRequest)(nil),             // 14: apis.v1.GetBlockRequest
+	(*GetBlockResponse)(nil),            // 15: apis.v1.GetBlockResponse
+	(*GetBlockChildrenRequest)(nil),     // 16: apis.v1.GetBlockChildrenRequest
+	(*GetBlockChildrenResponse)(nil),    // 17: apis.v1.GetBlockChildrenResponse
+	(*GetBlockDescendantsRequest)(nil),  // 18: apis.v1.GetBlockDescendantsRequest
+	(*GetBlockDescendantsResponse)(nil), // 19: apis.v1.GetBlockDescendantsResponse
+	(*GetBlockPageRequest)(nil),         // 20: apis.v1.GetBlockPageRequest
+	(*GetBlockPageResponse)(nil),        // 21: apis.v1.GetBlockPageResponse
+	(*GetUpdatesRequest)(nil),           // 22: apis.v1.GetUpdatesRequest
+	(*ChildIds)(nil),                    // 23: apis.v1.ChildIds
+	(*GetUpdatesResponse)(nil),          // 24: apis.v1.GetUpdatesResponse
+	(*GetBackLinksRequest)(nil),         // 25: apis.v1.GetBackLinksRequest
+	(*GetBackLinksResponse)(nil),        // 26: apis.v1.GetBackLinksResponse
+	(*SubscribeRequest)(nil),            // 27: apis.v1.SubscribeRequest
+	(*SubscribeResponse)(nil),           // 28: apis.v1.SubscribeResponse
+	(*GetSnapshotRequest)(nil),          // 29: apis.v1.GetSnapshotRequest
+	(*GetSnapshotResponse)(nil),         // 30: apis.v1.GetSnapshotResponse
+	nil,                                 // 31: apis.v1.Precondition.VersionsEntry
+	nil,                                 // 32: apis.v1.GetUpdatesResponse.UpdatesEntry
+	nil,                                 // 33: apis.v1.SubscribeResponse.UpdatesEntry
+	nil,                                 // 34: apis.v1.GetSnapshotResponse.BackLinksEntry
 }
 var file_apis_v1_blocktree_proto_depIdxs = []int32{
 	1,  // 0: apis.v1.Pointer.position:type_name -> apis.v1.PointerPosition
 	0,  // 1: apis.v1.Op.type:type_name -> apis.v1.OpType
-	2,  // 2: apis.v1.Op.at:type_name -> apis.v1.Pointer
-	4,  // 3: apis.v1.Transaction.ops:type_name -> apis.v1.Op
-	5,  // 4: apis.v1.TransactionsRequest.transactions:type_name -> apis.v1.Transaction
-	7,  // 5: apis.v1.TransactionsResponse.transactions:type_name -> apis.v1.ApplyTransactionResult
-	11, // 6: apis.v1.Block.children:type_name -> apis.v1.Block
-	11, // 7: apis.v1.Block.linked:type_name -> apis.v1.Block
-	11, // 8: apis.v1.GetBlockResponse.block:type_name -> apis.v1.Block
-	11, // 9: apis.v1.GetBlockChildrenResponse.blocks:type_name -> apis.v1.Block
-	11, // 10: apis.v1.GetBlockDescendantsResponse.block:type_name -> apis.v1.Block
-	11, // 11: apis.v1.GetBlockPageResponse.blocks:type_name -> apis.v1.Block
-	25, // 12: apis.v1.GetUpdatesResponse.updates:type_name -> apis.v1.GetUpdatesResponse.UpdatesEntry
-	11, // 13: apis.v1.GetUpdatesResponse.blocks:type_name -> apis.v1.Block
-	11, // 14: apis.v1.GetBackLinksResponse.blocks:type_name -> apis.v1.Block
-	21, // 15: apis.v1.GetUpdatesResponse.UpdatesEntry.value:type_name -> apis.v1.ChildIds
-	6,  // 16: apis.v1.Blocktree.Apply:input_type -> apis.v1.TransactionsRequest
-	9,  // 17: apis.v1.Blocktree.CreateSpace:input_type -> apis.v1.CreateSpaceRequest
-	12, // 18: apis.v1.Blocktree.GetBlock:input_type -> apis.v1.GetBlockRequest
-	14, // 19: apis.v1.Blocktree.GetChildren:input_type -> apis.v1.GetBlockChildrenRequest
-	16, // 20: apis.v1.Blocktree.GetDescendants:input_type -> apis.v1.GetBlockDescendantsRequest
-	18, // 21: apis.v1.Blocktree.GetPage:input_type -> apis.v1.GetBlockPageRequest
-	23, // 22: apis.v1.Blocktree.GetBackLinks:input_type -> apis.v1.GetBackLinksRequest
-	20, // 23: apis.v1.Blocktree.GetUpdates:input_type -> apis.v1.GetUpdatesRequest
-	8,  // 24: apis.v1.Blocktree.Apply:output_type -> apis.v1.TransactionsResponse
-	10, // 25: apis.v1.Blocktree.CreateSpace:output_type -> apis.v1.CreateSpaceResponse
-	13, // 26: apis.v1.Blocktree.GetBlock:output_type -> apis.v1.GetBlockResponse
-	15, // 27: apis.v1.Blocktree.GetChildren:output_type -> apis.v1.GetBlockChildrenResponse
-	17, // 28: apis.v1.Blocktree.GetDescendants:output_type -> apis.v1.GetBlockDescendantsResponse
-	19, // 29: apis.v1.Blocktree.GetPage:output_type -> apis.v1.GetBlockPageResponse
-	24, // 30: apis.v1.Blocktree.GetBackLinks:output_type -> apis.v1.GetBackLinksResponse
-	22, // 31: apis.v1.Blocktree.GetUpdates:output_type -> apis.v1.GetUpdatesResponse
-	24, // [24:32] is the sub-list for method output_type
-	16, // [16:24] is the sub-list for method input_type
-	16, // [16:16] is the sub-list for extension type_name
-	16, // [16:16] is the sub-list for extension extendee
-	0,  // [0:16] is the sub-list for field type_name
+	3,  // 2: apis.v1.Op.at:type_name -> apis.v1.Pointer
+	5,  // 3: apis.v1.Transaction.ops:type_name -> apis.v1.Op
+	7,  // 4: apis.v1.Transaction.precondition:type_name -> apis.v1.Precondition
+	31, // 5: apis.v1.Precondition.versions:type_name -> apis.v1.Precondition.VersionsEntry
+	6,  // 6: apis.v1.TransactionsRequest.transactions:type_name -> apis.v1.Transaction
+	2,  // 7: apis.v1.ApplyTransactionResult.code:type_name -> apis.v1.ApplyResultCode
+	9,  // 8: apis.v1.TransactionsResponse.transactions:type_name -> apis.v1.ApplyTransactionResult
+	13, // 9: apis.v1.Block.children:type_name -> apis.v1.Block
+	13, // 10: apis.v1.Block.linked:type_name -> apis.v1.Block
+	13, // 11: apis.v1.GetBlockResponse.block:type_name -> apis.v1.Block
+	13, // 12: apis.v1.GetBlockChildrenResponse.blocks:type_name -> apis.v1.Block
+	13, // 13: apis.v1.GetBlockDescendantsResponse.block:type_name -> apis.v1.Block
+	13, // 14: apis.v1.GetBlockDescendantsResponse.blocks:type_name -> apis.v1.Block
+	13, // 15: apis.v1.GetBlockPageResponse.blocks:type_name -> apis.v1.Block
+	13, // 16: apis.v1.GetBlockPageResponse.sub_pages:type_name -> apis.v1.Block
+	13, // 17: apis.v1.GetBlockPageResponse.block:type_name -> apis.v1.Block
+	32, // 18: apis.v1.GetUpdatesResponse.updates:type_name -> apis.v1.GetUpdatesResponse.UpdatesEntry
+	13, // 19: apis.v1.GetUpdatesResponse.blocks:type_name -> apis.v1.Block
+	13, // 20: apis.v1.GetBackLinksResponse.blocks:type_name -> apis.v1.Block
+	33, // 21: apis.v1.SubscribeResponse.updates:type_name -> apis.v1.SubscribeResponse.UpdatesEntry
+	13, // 22: apis.v1.SubscribeResponse.blocks:type_name -> apis.v1.Block
+	34, // 23: apis.v1.GetSnapshotResponse.back_links:type_name -> apis.v1.GetSnapshotResponse.BackLinksEntry
+	13, // 24: apis.v1.GetSnapshotResponse.blocks:type_name -> apis.v1.Block
+	23, // 25: apis.v1.GetUpdatesResponse.UpdatesEntry.value:type_name -> apis.v1.ChildIds
+	23, // 26: apis.v1.SubscribeResponse.UpdatesEntry.value:type_name -> apis.v1.ChildIds
+	23, // 27: apis.v1.GetSnapshotResponse.BackLinksEntry.value:type_name -> apis.v1.ChildIds
+	8,  // 28: apis.v1.Blocktree.Apply:input_type -> apis.v1.TransactionsRequest
+	11, // 29: apis.v1.Blocktree.CreateSpace:input_type -> apis.v1.CreateSpaceRequest
+	14, // 30: apis.v1.Blocktree.GetBlock:input_type -> apis.v1.GetBlockRequest
+	16, // 31: apis.v1.Blocktree.GetChildren:input_type -> apis.v1.GetBlockChildrenRequest
+	18, // 32: apis.v1.Blocktree.GetDescendants:input_type -> apis.v1.GetBlockDescendantsRequest
+	20, // 33: apis.v1.Blocktree.GetPage:input_type -> apis.v1.GetBlockPageRequest
+	25, // 34: apis.v1.Blocktree.GetBackLinks:input_type -> apis.v1.GetBackLinksRequest
+	22, // 35: apis.v1.Blocktree.GetUpdates:input_type -> apis.v1.GetUpdatesRequest
+	29, // 36: apis.v1.Blocktree.GetSnapshot:input_type -> apis.v1.GetSnapshotRequest
+	27, // 37: apis.v1.Blocktree.Subscribe:input_type -> apis.v1.SubscribeRequest
+	10, // 38: apis.v1.Blocktree.Apply:output_type -> apis.v1.TransactionsResponse
+	12, // 39: apis.v1.Blocktree.CreateSpace:output_type -> apis.v1.CreateSpaceResponse
+	15, // 40: apis.v1.Blocktree.GetBlock:output_type -> apis.v1.GetBlockResponse
+	17, // 41: apis.v1.Blocktree.GetChildren:output_type -> apis.v1.GetBlockChildrenResponse
+	19, // 42: apis.v1.Blocktree.GetDescendants:output_type -> apis.v1.GetBlockDescendantsResponse
+	21, // 43: apis.v1.Blocktree.GetPage:output_type -> apis.v1.GetBlockPageResponse
+	26, // 44: apis.v1.Blocktree.GetBackLinks:output_type -> apis.v1.GetBackLinksResponse
+	24, // 45: apis.v1.Blocktree.GetUpdates:output_type -> apis.v1.GetUpdatesResponse
+	30, // 46: apis.v1.Blocktree.GetSnapshot:output_type -> apis.v1.GetSnapshotResponse
+	28, // 47: apis.v1.Blocktree.Subscribe:output_type -> apis.v1.SubscribeResponse
+	38, // [38:48] is the sub-list for method output_type
+	28, // [28:38] is the sub-list for method input_type
+	28, // [28:28] is the sub-list for extension type_name
+	28, // [28:28] is the sub-list for extension extendee
+	0,  // [0:28] is the sub-list for field type_name
 }
 
 func init() { file_apis_v1_blocktree_proto_init() }
@@ -1896,7 +2642,7 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TransactionsRequest); i {
+			switch v := v.(*Precondition); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1908,7 +2654,7 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ApplyTransactionResult); i {
+			switch v := v.(*TransactionsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1920,7 +2666,7 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TransactionsResponse); i {
+			switch v := v.(*ApplyTransactionResult); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1932,7 +2678,7 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateSpaceRequest); i {
+			switch v := v.(*TransactionsResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1944,7 +2690,7 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateSpaceResponse); i {
+			switch v := v.(*CreateSpaceRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1956,7 +2702,7 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Block); i {
+			switch v := v.(*CreateSpaceResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1968,7 +2714,7 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetBlockRequest); i {
+			switch v := v.(*Block); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1980,7 +2726,7 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetBlockResponse); i {
+			switch v := v.(*GetBlockRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1992,7 +2738,7 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetBlockChildrenRequest); i {
+			switch v := v.(*GetBlockResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2004,7 +2750,7 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetBlockChildrenResponse); i {
+			switch v := v.(*GetBlockChildrenRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2016,7 +2762,7 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetBlockDescendantsRequest); i {
+			switch v := v.(*GetBlockChildrenResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2028,7 +2774,7 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetBlockDescendantsResponse); i {
+			switch v := v.(*GetBlockDescendantsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2040,7 +2786,7 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetBlockPageRequest); i {
+			switch v := v.(*GetBlockDescendantsResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2052,7 +2798,7 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetBlockPageResponse); i {
+			switch v := v.(*GetBlockPageRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2064,7 +2810,7 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetUpdatesRequest); i {
+			switch v := v.(*GetBlockPageResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2076,7 +2822,7 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ChildIds); i {
+			switch v := v.(*GetUpdatesRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2088,7 +2834,7 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetUpdatesResponse); i {
+			switch v := v.(*ChildIds); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2100,7 +2846,7 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetBackLinksRequest); i {
+			switch v := v.(*GetUpdatesResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2112,6 +2858,18 @@ func file_apis_v1_blocktree_proto_init() {
 			}
 		}
 		file_apis_v1_blocktree_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetBackLinksRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_apis_v1_blocktree_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GetBackLinksResponse); i {
 			case 0:
 				return &v.state
@@ -2123,19 +2881,70 @@ func file_apis_v1_blocktree_proto_init() {
 				return nil
 			}
 		}
+		file_apis_v1_blocktree_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscribeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_apis_v1_blocktree_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscribeResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_apis_v1_blocktree_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetSnapshotRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_apis_v1_blocktree_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetSnapshotResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_apis_v1_blocktree_proto_msgTypes[2].OneofWrappers = []interface{}{}
-	file_apis_v1_blocktree_proto_msgTypes[9].OneofWrappers = []interface{}{}
+	file_apis_v1_blocktree_proto_msgTypes[3].OneofWrappers = []interface{}{}
+	file_apis_v1_blocktree_proto_msgTypes[4].OneofWrappers = []interface{}{}
 	file_apis_v1_blocktree_proto_msgTypes[10].OneofWrappers = []interface{}{}
-	file_apis_v1_blocktree_proto_msgTypes[12].OneofWrappers = []interface{}{}
-	file_apis_v1_blocktree_proto_msgTypes[14].OneofWrappers = []interface{}{}
+	file_apis_v1_blocktree_proto_msgTypes[11].OneofWrappers = []interface{}{}
+	file_apis_v1_blocktree_proto_msgTypes[13].OneofWrappers = []interface{}{}
+	file_apis_v1_blocktree_proto_msgTypes[15].OneofWrappers = []interface{}{}
+	file_apis_v1_blocktree_proto_msgTypes[17].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_apis_v1_blocktree_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   24,
+			NumEnums:      3,
+			NumMessages:   32,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -334,6 +334,10 @@ func (m *Op) validate(all bool) error {
 		// no validation rules for Patch
 	}
 
+	if m.Index != nil {
+		// no validation rules for Index
+	}
+
 	if len(errors) > 0 {
 		return OpMultiError(errors)
 	}
@@ -503,6 +507,41 @@ func (m *Transaction) validate(all bool) error {
 
 	}
 
+	// no validation rules for Clock
+
+	if m.Precondition != nil {
+
+		if all {
+			switch v := interface{}(m.GetPrecondition()).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, TransactionValidationError{
+						field:  "Precondition",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, TransactionValidationError{
+						field:  "Precondition",
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(m.GetPrecondition()).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return TransactionValidationError{
+					field:  "Precondition",
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
 	if len(errors) > 0 {
 		return TransactionMultiError(errors)
 	}
@@ -588,6 +627,134 @@ var _ interface {
 	ErrorName() string
 } = TransactionValidationError{}
 
+// Validate checks the field values on Precondition with the rules defined in the
+// proto definition for this message. If any rules are violated, the first
+// error encountered is returned, or nil if there are no violations.
+func (m *Precondition) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on Precondition with the rules defined in the
+// proto definition for this message. If any rules are violated, the result is
+// a list of violation errors wrapped in PreconditionMultiError, or nil if none found.
+func (m *Precondition) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *Precondition) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if m.GetBlockId() != "" {
+
+		if err := m._validateUuid(m.GetBlockId()); err != nil {
+			err = PreconditionValidationError{
+				field:  "BlockId",
+				reason: "value must be a valid UUID",
+				cause:  err,
+			}
+			if !all {
+				return err
+			}
+			errors = append(errors, err)
+		}
+
+	}
+
+	// no validation rules for Versions
+
+	if m.Seq != nil {
+		// no validation rules for Seq
+	}
+
+	if len(errors) > 0 {
+		return PreconditionMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *Precondition) _validateUuid(uuid string) error {
+	if matched := _blocktree_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// PreconditionMultiError is an error wrapping multiple validation errors returned
+// by Precondition.ValidateAll() if the designated constraints aren't met.
+type PreconditionMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m PreconditionMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m PreconditionMultiError) AllErrors() []error { return m }
+
+// PreconditionValidationError is the validation error returned by Precondition.Validate
+// if the designated constraints aren't met.
+type PreconditionValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e PreconditionValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e PreconditionValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e PreconditionValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e PreconditionValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e PreconditionValidationError) ErrorName() string { return "PreconditionValidationError" }
+
+// Error satisfies the builtin error interface
+func (e PreconditionValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sPrecondition.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = PreconditionValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = PreconditionValidationError{}
+
 // Validate checks the field values on TransactionsRequest with the rules
 // defined in the proto definition for this message. If any rules are
 // violated, the first error encountered is returned, or nil if there are no violations.
@@ -774,6 +941,8 @@ func (m *ApplyTransactionResult) validate(all bool) error {
 
 	// no validation rules for Message
 
+	// no validation rules for Code
+
 	if len(errors) > 0 {
 		return ApplyTransactionResultMultiError(errors)
 	}
@@ -1359,6 +1528,8 @@ func (m *Block) validate(all bool) error {
 
 	}
 
+	// no validation rules for Version
+
 	if m.Json != nil {
 		// no validation rules for Json
 	}
@@ -1375,6 +1546,10 @@ func (m *Block) validate(all bool) error {
 		// no validation rules for Erased
 	}
 
+	if m.Index != nil {
+		// no validation rules for Index
+	}
+
 	if len(errors) > 0 {
 		return BlockMultiError(errors)
 	}
@@ -1759,6 +1934,10 @@ func (m *GetBlockChildrenRequest) validate(all bool) error {
 		errors = append(errors, err)
 	}
 
+	// no validation rules for Limit
+
+	// no validation rules for PageToken
+
 	if m.SpaceId != nil {
 
 		if err := m._validateUuid(m.GetSpaceId()); err != nil {
@@ -1919,6 +2098,8 @@ func (m *GetBlockChildrenResponse) validate(all bool) error {
 
 	}
 
+	// no validation rules for NextPageToken
+
 	if len(errors) > 0 {
 		return GetBlockChildrenResponseMultiError(errors)
 	}
@@ -2033,6 +2214,12 @@ func (m *GetBlockDescendantsRequest) validate(all bool) error {
 		errors = append(errors, err)
 	}
 
+	// no validation rules for MaxDepth
+
+	// no validation rules for Limit
+
+	// no validation rules for PageToken
+
 	if m.SpaceId != nil {
 
 		if err := m._validateUuid(m.GetSpaceId()); err != nil {
@@ -2188,6 +2375,42 @@ func (m *GetBlockDescendantsResponse) validate(all bool) error {
 		}
 	}
 
+	for idx, item := range m.GetBlocks() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, GetBlockDescendantsResponseValidationError{
+						field:  fmt.Sprintf("Blocks[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, GetBlockDescendantsResponseValidationError{
+						field:  fmt.Sprintf("Blocks[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return GetBlockDescendantsResponseValidationError{
+					field:  fmt.Sprintf("Blocks[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	// no validation rules for NextPageToken
+
 	if len(errors) > 0 {
 		return GetBlockDescendantsResponseMultiError(errors)
 	}
@@ -2303,6 +2526,22 @@ func (m *GetBlockPageRequest) validate(all bool) error {
 		errors = append(errors, err)
 	}
 
+	if m.SpaceId != nil {
+
+		if err := m._validateUuid(m.GetSpaceId()); err != nil {
+			err = GetBlockPageRequestValidationError{
+				field:  "SpaceId",
+				reason: "value must be a valid UUID",
+				cause:  err,
+			}
+			if !all {
+				return err
+			}
+			errors = append(errors, err)
+		}
+
+	}
+
 	if len(errors) > 0 {
 		return GetBlockPageRequestMultiError(errors)
 	}
@@ -2447,6 +2686,69 @@ func (m *GetBlockPageResponse) validate(all bool) error {
 
 	}
 
+	for idx, item := range m.GetSubPages() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, GetBlockPageResponseValidationError{
+						field:  fmt.Sprintf("SubPages[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, GetBlockPageResponseValidationError{
+						field:  fmt.Sprintf("SubPages[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return GetBlockPageResponseValidationError{
+					field:  fmt.Sprintf("SubPages[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if all {
+		switch v := interface{}(m.GetBlock()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, GetBlockPageResponseValidationError{
+					field:  "Block",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, GetBlockPageResponseValidationError{
+					field:  "Block",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetBlock()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return GetBlockPageResponseValidationError{
+				field:  "Block",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
 	if len(errors) > 0 {
 		return GetBlockPageResponseMultiError(errors)
 	}
@@ -2561,23 +2863,29 @@ func (m *GetUpdatesRequest) validate(all bool) error {
 		errors = append(errors, err)
 	}
 
-	if err := m._validateUuid(m.GetTransactionId()); err != nil {
-		err = GetUpdatesRequestValidationError{
-			field:  "TransactionId",
-			reason: "value must be a valid UUID",
-			cause:  err,
-		}
-		if !all {
-			return err
-		}
-		errors = append(errors, err)
-	}
-
-	if len(errors) > 0 {
-		return GetUpdatesRequestMultiError(errors)
-	}
+	if m.GetTransactionId() != "" {
 
-	return nil
+		if err := m._validateUuid(m.GetTransactionId()); err != nil {
+			err = GetUpdatesRequestValidationError{
+				field:  "TransactionId",
+				reason: "value must be a valid UUID",
+				cause:  err,
+			}
+			if !all {
+				return err
+			}
+			errors = append(errors, err)
+		}
+
+	}
+
+	// no validation rules for Seq
+
+	if len(errors) > 0 {
+		return GetUpdatesRequestMultiError(errors)
+	}
+
+	return nil
 }
 
 func (m *GetUpdatesRequest) _validateUuid(uuid string) error {
@@ -2862,6 +3170,10 @@ func (m *GetUpdatesResponse) validate(all bool) error {
 
 	}
 
+	// no validation rules for TransactionId
+
+	// no validation rules for Seq
+
 	if len(errors) > 0 {
 		return GetUpdatesResponseMultiError(errors)
 	}
@@ -3211,3 +3523,662 @@ var _ interface {
 	Cause() error
 	ErrorName() string
 } = GetBackLinksResponseValidationError{}
+
+// Validate checks the field values on SubscribeRequest with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *SubscribeRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on SubscribeRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// SubscribeRequestMultiError, or nil if none found.
+func (m *SubscribeRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *SubscribeRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if err := m._validateUuid(m.GetSpaceId()); err != nil {
+		err = SubscribeRequestValidationError{
+			field:  "SpaceId",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if err := m._validateUuid(m.GetAfterTransactionId()); err != nil {
+		err = SubscribeRequestValidationError{
+			field:  "AfterTransactionId",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return SubscribeRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *SubscribeRequest) _validateUuid(uuid string) error {
+	if matched := _blocktree_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// SubscribeRequestMultiError is an error wrapping multiple validation errors
+// returned by SubscribeRequest.ValidateAll() if the designated constraints
+// aren't met.
+type SubscribeRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m SubscribeRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m SubscribeRequestMultiError) AllErrors() []error { return m }
+
+// SubscribeRequestValidationError is the validation error returned by
+// SubscribeRequest.Validate if the designated constraints aren't met.
+type SubscribeRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e SubscribeRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e SubscribeRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e SubscribeRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e SubscribeRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e SubscribeRequestValidationError) ErrorName() string {
+	return "SubscribeRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e SubscribeRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sSubscribeRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = SubscribeRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = SubscribeRequestValidationError{}
+
+// Validate checks the field values on SubscribeResponse with the rules defined
+// in the proto definition for this message. If any rules are violated, the
+// first error encountered is returned, or nil if there are no violations.
+func (m *SubscribeResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on SubscribeResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// SubscribeResponseMultiError, or nil if none found.
+func (m *SubscribeResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *SubscribeResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if err := m._validateUuid(m.GetTransactionId()); err != nil {
+		err = SubscribeResponseValidationError{
+			field:  "TransactionId",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	{
+		sorted_keys := make([]string, len(m.GetUpdates()))
+		i := 0
+		for key := range m.GetUpdates() {
+			sorted_keys[i] = key
+			i++
+		}
+		sort.Slice(sorted_keys, func(i, j int) bool { return sorted_keys[i] < sorted_keys[j] })
+		for _, key := range sorted_keys {
+			val := m.GetUpdates()[key]
+			_ = val
+
+			// no validation rules for Updates[key]
+
+			if all {
+				switch v := interface{}(val).(type) {
+				case interface{ ValidateAll() error }:
+					if err := v.ValidateAll(); err != nil {
+						errors = append(errors, SubscribeResponseValidationError{
+							field:  fmt.Sprintf("Updates[%v]", key),
+							reason: "embedded message failed validation",
+							cause:  err,
+						})
+					}
+				case interface{ Validate() error }:
+					if err := v.Validate(); err != nil {
+						errors = append(errors, SubscribeResponseValidationError{
+							field:  fmt.Sprintf("Updates[%v]", key),
+							reason: "embedded message failed validation",
+							cause:  err,
+						})
+					}
+				}
+			} else if v, ok := interface{}(val).(interface{ Validate() error }); ok {
+				if err := v.Validate(); err != nil {
+					return SubscribeResponseValidationError{
+						field:  fmt.Sprintf("Updates[%v]", key),
+						reason: "embedded message failed validation",
+						cause:  err,
+					}
+				}
+			}
+
+		}
+	}
+
+	for idx, item := range m.GetBlocks() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, SubscribeResponseValidationError{
+						field:  fmt.Sprintf("Blocks[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, SubscribeResponseValidationError{
+						field:  fmt.Sprintf("Blocks[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return SubscribeResponseValidationError{
+					field:  fmt.Sprintf("Blocks[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return SubscribeResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *SubscribeResponse) _validateUuid(uuid string) error {
+	if matched := _blocktree_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// SubscribeResponseMultiError is an error wrapping multiple validation errors
+// returned by SubscribeResponse.ValidateAll() if the designated constraints
+// aren't met.
+type SubscribeResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m SubscribeResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m SubscribeResponseMultiError) AllErrors() []error { return m }
+
+// SubscribeResponseValidationError is the validation error returned by
+// SubscribeResponse.Validate if the designated constraints aren't met.
+type SubscribeResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e SubscribeResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e SubscribeResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e SubscribeResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e SubscribeResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e SubscribeResponseValidationError) ErrorName() string {
+	return "SubscribeResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e SubscribeResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sSubscribeResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = SubscribeResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = SubscribeResponseValidationError{}
+// Validate checks the field values on GetSnapshotRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *GetSnapshotRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on GetSnapshotRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// GetSnapshotRequestMultiError, or nil if none found.
+func (m *GetSnapshotRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *GetSnapshotRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if err := m._validateUuid(m.GetSpaceId()); err != nil {
+		err = GetSnapshotRequestValidationError{
+			field:  "SpaceId",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return GetSnapshotRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *GetSnapshotRequest) _validateUuid(uuid string) error {
+	if matched := _blocktree_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// GetSnapshotRequestMultiError is an error wrapping multiple validation errors
+// returned by GetSnapshotRequest.ValidateAll() if the designated constraints
+// aren't met.
+type GetSnapshotRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m GetSnapshotRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m GetSnapshotRequestMultiError) AllErrors() []error { return m }
+
+// GetSnapshotRequestValidationError is the validation error returned by
+// GetSnapshotRequest.Validate if the designated constraints aren't met.
+type GetSnapshotRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e GetSnapshotRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e GetSnapshotRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e GetSnapshotRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e GetSnapshotRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e GetSnapshotRequestValidationError) ErrorName() string {
+	return "GetSnapshotRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e GetSnapshotRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sGetSnapshotRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = GetSnapshotRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = GetSnapshotRequestValidationError{}
+
+// Validate checks the field values on GetSnapshotResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *GetSnapshotResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on GetSnapshotResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// GetSnapshotResponseMultiError, or nil if none found.
+func (m *GetSnapshotResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *GetSnapshotResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if err := m._validateUuid(m.GetTransactionId()); err != nil {
+		err = GetSnapshotResponseValidationError{
+			field:  "TransactionId",
+			reason: "value must be a valid UUID",
+			cause:  err,
+		}
+		if !all {
+			return err
+		}
+		errors = append(errors, err)
+	}
+
+	{
+		sorted_keys := make([]string, len(m.GetBackLinks()))
+		i := 0
+		for key := range m.GetBackLinks() {
+			sorted_keys[i] = key
+			i++
+		}
+		sort.Slice(sorted_keys, func(i, j int) bool { return sorted_keys[i] < sorted_keys[j] })
+		for _, key := range sorted_keys {
+			val := m.GetBackLinks()[key]
+			_ = val
+
+			// no validation rules for BackLinks[key]
+
+			if all {
+				switch v := interface{}(val).(type) {
+				case interface{ ValidateAll() error }:
+					if err := v.ValidateAll(); err != nil {
+						errors = append(errors, GetSnapshotResponseValidationError{
+							field:  fmt.Sprintf("BackLinks[%v]", key),
+							reason: "embedded message failed validation",
+							cause:  err,
+						})
+					}
+				case interface{ Validate() error }:
+					if err := v.Validate(); err != nil {
+						errors = append(errors, GetSnapshotResponseValidationError{
+							field:  fmt.Sprintf("BackLinks[%v]", key),
+							reason: "embedded message failed validation",
+							cause:  err,
+						})
+					}
+				}
+			} else if v, ok := interface{}(val).(interface{ Validate() error }); ok {
+				if err := v.Validate(); err != nil {
+					return GetSnapshotResponseValidationError{
+						field:  fmt.Sprintf("BackLinks[%v]", key),
+						reason: "embedded message failed validation",
+						cause:  err,
+					}
+				}
+			}
+
+		}
+	}
+
+	for idx, item := range m.GetBlocks() {
+		_, _ = idx, item
+
+		if all {
+			switch v := interface{}(item).(type) {
+			case interface{ ValidateAll() error }:
+				if err := v.ValidateAll(); err != nil {
+					errors = append(errors, GetSnapshotResponseValidationError{
+						field:  fmt.Sprintf("Blocks[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			case interface{ Validate() error }:
+				if err := v.Validate(); err != nil {
+					errors = append(errors, GetSnapshotResponseValidationError{
+						field:  fmt.Sprintf("Blocks[%v]", idx),
+						reason: "embedded message failed validation",
+						cause:  err,
+					})
+				}
+			}
+		} else if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return GetSnapshotResponseValidationError{
+					field:  fmt.Sprintf("Blocks[%v]", idx),
+					reason: "embedded message failed validation",
+					cause:  err,
+				}
+			}
+		}
+
+	}
+
+	if len(errors) > 0 {
+		return GetSnapshotResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+func (m *GetSnapshotResponse) _validateUuid(uuid string) error {
+	if matched := _blocktree_uuidPattern.MatchString(uuid); !matched {
+		return errors.New("invalid uuid format")
+	}
+
+	return nil
+}
+
+// GetSnapshotResponseMultiError is an error wrapping multiple validation
+// errors returned by GetSnapshotResponse.ValidateAll() if the designated
+// constraints aren't met.
+type GetSnapshotResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m GetSnapshotResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m GetSnapshotResponseMultiError) AllErrors() []error { return m }
+
+// GetSnapshotResponseValidationError is the validation error returned by
+// GetSnapshotResponse.Validate if the designated constraints aren't met.
+type GetSnapshotResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e GetSnapshotResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e GetSnapshotResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e GetSnapshotResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e GetSnapshotResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e GetSnapshotResponseValidationError) ErrorName() string {
+	return "GetSnapshotResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e GetSnapshotResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sGetSnapshotResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = GetSnapshotResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = GetSnapshotResponseValidationError{}
\ No newline at end of file
@@ -27,6 +27,8 @@ const (
 	Blocktree_GetPage_FullMethodName        = "/apis.v1.Blocktree/GetPage"
 	Blocktree_GetBackLinks_FullMethodName   = "/apis.v1.Blocktree/GetBackLinks"
 	Blocktree_GetUpdates_FullMethodName     = "/apis.v1.Blocktree/GetUpdates"
+	Blocktree_GetSnapshot_FullMethodName    = "/apis.v1.Blocktree/GetSnapshot"
+	Blocktree_Subscribe_FullMethodName      = "/apis.v1.Blocktree/Subscribe"
 )
 
 // BlocktreeClient is the client API for Blocktree service.
@@ -41,6 +43,11 @@ type BlocktreeClient interface {
 	GetPage(ctx context.Context, in *GetBlockPageRequest, opts ...grpc.CallOption) (*GetBlockPageResponse, error)
 	GetBackLinks(ctx context.Context, in *GetBackLinksRequest, opts ...grpc.CallOption) (*GetBackLinksResponse, error)
 	GetUpdates(ctx context.Context, in *GetUpdatesRequest, opts ...grpc.CallOption) (*GetUpdatesResponse, error)
+	// GetSnapshot returns the current tree of the space, the clients re-download it when the
+	// transaction they ask updates for is compacted and GetUpdates fails with FAILED_PRECONDITION
+	GetSnapshot(ctx context.Context, in *GetSnapshotRequest, opts ...grpc.CallOption) (*GetSnapshotResponse, error)
+	// Subscribe streams the space updates, the http clients use the /v1/spaces/{space_id}/events SSE endpoint
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Blocktree_SubscribeClient, error)
 }
 
 type blocktreeClient struct {
@@ -123,6 +130,47 @@ func (c *blocktreeClient) GetUpdates(ctx context.Context, in *GetUpdatesRequest,
 	return out, nil
 }
 
+func (c *blocktreeClient) GetSnapshot(ctx context.Context, in *GetSnapshotRequest, opts ...grpc.CallOption) (*GetSnapshotResponse, error) {
+	out := new(GetSnapshotResponse)
+	err := c.cc.Invoke(ctx, Blocktree_GetSnapshot_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blocktreeClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Blocktree_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Blocktree_ServiceDesc.Streams[0], Blocktree_Subscribe_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &blocktreeSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Blocktree_SubscribeClient interface {
+	Recv() (*SubscribeResponse, error)
+	grpc.ClientStream
+}
+
+type blocktreeSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *blocktreeSubscribeClient) Recv() (*SubscribeResponse, error) {
+	m := new(SubscribeResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // BlocktreeServer is the server API for Blocktree service.
 // All implementations must embed UnimplementedBlocktreeServer
 // for forward compatibility
@@ -135,6 +183,11 @@ type BlocktreeServer interface {
 	GetPage(context.Context, *GetBlockPageRequest) (*GetBlockPageResponse, error)
 	GetBackLinks(context.Context, *GetBackLinksRequest) (*GetBackLinksResponse, error)
 	GetUpdates(context.Context, *GetUpdatesRequest) (*GetUpdatesResponse, error)
+	// GetSnapshot returns the current tree of the space, the clients re-download it when the
+	// transaction they ask updates for is compacted and GetUpdates fails with FAILED_PRECONDITION
+	GetSnapshot(context.Context, *GetSnapshotRequest) (*GetSnapshotResponse, error)
+	// Subscribe streams the space updates, the http clients use the /v1/spaces/{space_id}/events SSE endpoint
+	Subscribe(*SubscribeRequest, Blocktree_SubscribeServer) error
 	mustEmbedUnimplementedBlocktreeServer()
 }
 
@@ -166,6 +219,12 @@ func (UnimplementedBlocktreeServer) GetBackLinks(context.Context, *GetBackLinksR
 func (UnimplementedBlocktreeServer) GetUpdates(context.Context, *GetUpdatesRequest) (*GetUpdatesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUpdates not implemented")
 }
+func (UnimplementedBlocktreeServer) GetSnapshot(context.Context, *GetSnapshotRequest) (*GetSnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSnapshot not implemented")
+}
+func (UnimplementedBlocktreeServer) Subscribe(*SubscribeRequest, Blocktree_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
 func (UnimplementedBlocktreeServer) mustEmbedUnimplementedBlocktreeServer() {}
 
 // UnsafeBlocktreeServer may be embedded to opt out of forward compatibility for this service.
@@ -323,6 +382,45 @@ func _Blocktree_GetUpdates_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Blocktree_GetSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlocktreeServer).GetSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Blocktree_GetSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlocktreeServer).GetSnapshot(ctx, req.(*GetSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Blocktree_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BlocktreeServer).Subscribe(m, &blocktreeSubscribeServer{stream})
+}
+
+type Blocktree_SubscribeServer interface {
+	Send(*SubscribeResponse) error
+	grpc.ServerStream
+}
+
+type blocktreeSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *blocktreeSubscribeServer) Send(m *SubscribeResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Blocktree_ServiceDesc is the grpc.ServiceDesc for Blocktree service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -362,7 +460,17 @@ var Blocktree_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUpdates",
 			Handler:    _Blocktree_GetUpdates_Handler,
 		},
+		{
+			MethodName: "GetSnapshot",
+			Handler:    _Blocktree_GetSnapshot_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Blocktree_Subscribe_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "apis/v1/blocktree.proto",
 }
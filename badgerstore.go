@@ -0,0 +1,1275 @@
+package blocktree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/google/uuid"
+)
+
+var (
+	_ Store = (*BadgerStore)(nil)
+)
+
+// key prefixes of the badger store.
+//
+//	s/<space>                          space name
+//	b/<block>                          block record
+//	c/<space><parent><index><block>    child entry, ordered by index bytes then block id
+//	l/<space><block><linked>           back link entry
+//	t/<space><seq>                     transaction record, seq is big endian
+//	x/<space><tx>                      seq of the transaction
+//	p/<space>                          snapshot the transaction log starts at
+const (
+	spacePrefix    = "s/"
+	blockPrefix    = "b/"
+	childPrefix    = "c/"
+	backLinkPrefix = "l/"
+	txPrefix       = "t/"
+	txSeqPrefix    = "x/"
+	snapshotPrefix = "p/"
+)
+
+// BadgerStore is a blocktree store backed by the badger key-value database.
+// the children of a block are keyed by space, parent and index bytes, so the
+// sibling queries are prefix and range scans.
+type BadgerStore struct {
+	db *badger.DB
+	// mu serializes Apply, the transaction seq is read and written in the same update
+	mu sync.Mutex
+	// txn is the transaction of the store given to an Atomic function, the reads and writes go to it
+	txn *badger.Txn
+}
+
+// NewBadgerStore opens (or creates) the badger database in the directory at path.
+func NewBadgerStore(path string) (*BadgerStore, error) {
+	return openBadgerStore(badger.DefaultOptions(path).WithLogger(nil))
+}
+
+// NewInMemoryBadgerStore creates a badger store that keeps everything in memory.
+func NewInMemoryBadgerStore() (*BadgerStore, error) {
+	return openBadgerStore(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+}
+
+func openBadgerStore(opts badger.Options) (*BadgerStore, error) {
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BadgerStore{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (s *BadgerStore) Close() error {
+	return s.db.Close()
+}
+
+// Atomic runs fn with a store bound to a single badger transaction, the writes of fn are committed together.
+func (s *BadgerStore) Atomic(spaceID SpaceID, fn func(store Store) error) error {
+	if s.txn != nil {
+		return fn(s)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.db.Update(func(txn *badger.Txn) error {
+		return fn(&BadgerStore{db: s.db, txn: txn})
+	})
+}
+
+// view runs fn in the transaction of the atomic store or in a new read transaction
+func (s *BadgerStore) view(fn func(txn *badger.Txn) error) error {
+	if s.txn != nil {
+		return fn(s.txn)
+	}
+
+	return s.db.View(fn)
+}
+
+// update runs fn in the transaction of the atomic store or in a new update transaction
+func (s *BadgerStore) update(fn func(txn *badger.Txn) error) error {
+	if s.txn != nil {
+		return fn(s.txn)
+	}
+
+	return s.db.Update(fn)
+}
+
+func (s *BadgerStore) CreateSpace(space *Space) error {
+	return s.update(func(txn *badger.Txn) error {
+		_, err := txn.Get(spaceKey(space.ID))
+		if err == nil {
+			return ErrSpaceExists{ID: space.ID}
+		}
+		if !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+
+		err = txn.Set(spaceKey(space.ID), []byte(space.Name))
+		if err != nil {
+			return err
+		}
+
+		spaceBlock := NewBlock(space.ID, RootBlockID, "space")
+		spaceBlock.Props = NewJsonDoc([]byte(`{"name": "` + space.Name + `"}`))
+		err = putBlock(txn, space.ID, spaceBlock)
+		if err != nil {
+			return err
+		}
+
+		// the initial transaction is the starting point for GetNextTransactions
+		timestamp, _ := time.Parse(time.RFC3339, "2000-01-01T00:00:00Z")
+		return putTransaction(txn, space.ID, 0, &Transaction{
+			ID:      uuid.Nil,
+			SpaceID: space.ID,
+			UserID:  uuid.Nil,
+			Time:    timestamp,
+		})
+	})
+}
+
+func (s *BadgerStore) GetBlockSpaceID(id *BlockID) (*SpaceID, error) {
+	var spaceID SpaceID
+	err := s.view(func(txn *badger.Txn) error {
+		record, err := getBlockRecord(txn, *id)
+		if err != nil {
+			return err
+		}
+		if record == nil {
+			return ErrBlockNotFound{ID: *id}
+		}
+
+		spaceID = record.SpaceID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &spaceID, nil
+}
+
+func (s *BadgerStore) CreateBlock(spaceID *SpaceID, block *Block) error {
+	return s.update(func(txn *badger.Txn) error {
+		return putBlock(txn, *spaceID, block)
+	})
+}
+
+func (s *BadgerStore) GetBlock(spaceID *SpaceID, id BlockID) (*Block, error) {
+	var block *Block
+	err := s.view(func(txn *badger.Txn) error {
+		var err error
+		block, err = getBlock(txn, *spaceID, id)
+		return err
+	})
+
+	return block, err
+}
+
+func (s *BadgerStore) GetChildrenBlocks(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	return s.childBlocks(spaceID, id, func(block *Block) bool {
+		return !block.Linked
+	})
+}
+
+// QueryChildrenBlocks seeks the children entries to the start cursor of the query.
+func (s *BadgerStore) QueryChildrenBlocks(spaceID *SpaceID, id BlockID, query *BlockQuery) (*BlockPage, error) {
+	pager, err := newBlockPager(query)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.view(func(txn *badger.Txn) error {
+		var childIDs []BlockID
+		pivot := BlockCursor("").pivot(pager.query.Start)
+		err := scanChildrenFrom(txn, *spaceID, id, pivot, func(childID BlockID) bool {
+			childIDs = append(childIDs, childID)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, childID := range childIDs {
+			block, err := getBlock(txn, *spaceID, childID)
+			if err != nil {
+				return err
+			}
+			if !block.Linked && !pager.add(block, BlockCursor("").child(block)) {
+				break
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pager.page, nil
+}
+
+func (s *BadgerStore) GetChildrenBlockIDs(spaceID *SpaceID, id BlockID) ([]BlockID, error) {
+	ids := make([]BlockID, 0)
+	err := s.view(func(txn *badger.Txn) error {
+		return scanChildren(txn, *spaceID, id, false, func(childID BlockID) bool {
+			ids = append(ids, childID)
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (s *BadgerStore) GetLinkedBlocks(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	return s.childBlocks(spaceID, id, func(block *Block) bool {
+		return block.Linked
+	})
+}
+
+func (s *BadgerStore) GetBackLinks(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	ids := make([]BlockID, 0)
+	err := s.view(func(txn *badger.Txn) error {
+		prefix := backLinkKey(*spaceID, id, nil)
+		it := txn.NewIterator(badger.IteratorOptions{Prefix: prefix})
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			linkedID, err := uuid.FromBytes(it.Item().Key()[len(prefix):])
+			if err != nil {
+				return err
+			}
+			ids = append(ids, linkedID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetBlocks(spaceID, ids)
+}
+
+func (s *BadgerStore) GetDescendantBlocks(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	page, err := s.QueryDescendantBlocks(spaceID, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return page.Blocks, nil
+}
+
+// QueryDescendantBlocks walks down the children entries in index order.
+// like the MemStore it stops at page blocks, the page block is returned but not its children.
+// the children entries before the start cursor are skipped and the walk stops once the page is full.
+func (s *BadgerStore) QueryDescendantBlocks(spaceID *SpaceID, id BlockID, query *BlockQuery) (*BlockPage, error) {
+	pager, err := newBlockPager(query)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.view(func(txn *badger.Txn) error {
+		// collect returns false once the page is full
+		var collect func(block *Block, cursor BlockCursor, depth int) (bool, error)
+		collect = func(block *Block, cursor BlockCursor, depth int) (bool, error) {
+			if cursor >= pager.query.Start && !pager.add(block, cursor) {
+				return false, nil
+			}
+			// stop at page block, no need to go further
+			if (depth > 0 && block.Type == PageBlockType) || !pager.expands(depth) {
+				return true, nil
+			}
+
+			var childIDs []BlockID
+			err := scanChildrenFrom(txn, *spaceID, block.ID, cursor.pivot(pager.query.Start), func(childID BlockID) bool {
+				childIDs = append(childIDs, childID)
+				return true
+			})
+			if err != nil {
+				return false, err
+			}
+
+			for _, childID := range childIDs {
+				child, err := getBlock(txn, *spaceID, childID)
+				if err != nil {
+					return false, err
+				}
+				childCursor := cursor.child(child)
+				if !childCursor.reaches(pager.query.Start) {
+					continue
+				}
+
+				more, err := collect(child, childCursor, depth+1)
+				if err != nil || !more {
+					return false, err
+				}
+			}
+
+			return true, nil
+		}
+
+		block, err := getBlock(txn, *spaceID, id)
+		if err != nil {
+			return err
+		}
+		_, err = collect(block, "", 0)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pager.page, nil
+}
+
+func (s *BadgerStore) GetParentBlock(spaceID *SpaceID, id BlockID) (*Block, error) {
+	var parent *Block
+	err := s.view(func(txn *badger.Txn) error {
+		block, err := getBlock(txn, *spaceID, id)
+		if err != nil {
+			return err
+		}
+
+		parent, err = getBlock(txn, *spaceID, block.ParentID)
+		return err
+	})
+
+	return parent, err
+}
+
+func (s *BadgerStore) GetBlocks(spaceID *SpaceID, ids []BlockID) ([]*Block, error) {
+	blocks := make([]*Block, 0, len(ids))
+	err := s.view(func(txn *badger.Txn) error {
+		for _, id := range ids {
+			record, err := getBlockRecord(txn, id)
+			if err != nil {
+				return err
+			}
+			if record == nil || record.SpaceID != *spaceID {
+				continue
+			}
+
+			block, err := record.toBlock(id)
+			if err != nil {
+				return err
+			}
+			blocks = append(blocks, block)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+func (s *BadgerStore) GetWithFirstChildBlock(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	return s.withEdgeChild(spaceID, id, false)
+}
+
+func (s *BadgerStore) GetWithLastChildBlock(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	return s.withEdgeChild(spaceID, id, true)
+}
+
+func (s *BadgerStore) GetParentWithNextBlock(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	return s.parentWithSibling(spaceID, id, false)
+}
+
+func (s *BadgerStore) GetParentWithPrevBlock(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	return s.parentWithSibling(spaceID, id, true)
+}
+
+func (s *BadgerStore) GetAncestorEdges(spaceID *SpaceID, ids []BlockID) ([]blockEdge, error) {
+	edges := make([]blockEdge, 0)
+	err := s.view(func(txn *badger.Txn) error {
+		for _, id := range ids {
+			curr := id
+			for {
+				record, err := getBlockRecord(txn, curr)
+				if err != nil {
+					return err
+				}
+				if record == nil || record.SpaceID != *spaceID {
+					return fmt.Errorf("non space block has no parent: %w", ErrBlockNotFound{ID: curr})
+				}
+
+				parent := record.ParentID
+				if parent == RootBlockID {
+					break
+				}
+
+				edges = append(edges, blockEdge{parentID: parent, childID: curr})
+				if parent == *spaceID {
+					break
+				}
+				curr = parent
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return edges, nil
+}
+
+func (s *BadgerStore) GetTransaction(spaceID *SpaceID, id TransactionID) (*Transaction, error) {
+	var tx *Transaction
+	err := s.view(func(txn *badger.Txn) error {
+		var err error
+		tx, err = getTransaction(txn, *spaceID, id)
+		return err
+	})
+
+	return tx, err
+}
+
+func (s *BadgerStore) GetLatestTransaction(spaceID *SpaceID) (*Transaction, error) {
+	var tx *Transaction
+	err := s.view(func(txn *badger.Txn) error {
+		key, value, err := lastEntry(txn, txKey(*spaceID, nil))
+		if err != nil {
+			return err
+		}
+		if key == nil {
+			return ErrSpaceNotFound{ID: *spaceID}
+		}
+
+		tx, err = decodeTransaction(*spaceID, value)
+		return err
+	})
+
+	return tx, err
+}
+
+// PutTransaction appends the transaction to the space transaction log.
+func (s *BadgerStore) PutTransaction(spaceID *SpaceID, tx *Transaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.update(func(txn *badger.Txn) error {
+		return appendTransaction(txn, *spaceID, tx)
+	})
+}
+
+func (s *BadgerStore) GetNextTransactions(spaceID *SpaceID, id TransactionID, start, limit int) ([]*Transaction, error) {
+	txs := make([]*Transaction, 0)
+	err := s.view(func(txn *badger.Txn) error {
+		seq, err := getTransactionSeq(txn, *spaceID, id)
+		if err != nil || seq < 0 {
+			return err
+		}
+
+		it := txn.NewIterator(badger.IteratorOptions{
+			PrefetchValues: true,
+			PrefetchSize:   min(max(limit, 1), 100),
+			Prefix:         txKey(*spaceID, nil),
+		})
+		defer it.Close()
+
+		skip := start
+		for it.Seek(txKey(*spaceID, seqBytes(seq+1))); it.Valid() && len(txs) < limit; it.Next() {
+			if skip > 0 {
+				skip--
+				continue
+			}
+
+			value, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			tx, err := decodeTransaction(*spaceID, value)
+			if err != nil {
+				return err
+			}
+			txs = append(txs, tx)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+// GetTransactionsSince returns at most limit transactions with a seq after the given seq, in seq order.
+func (s *BadgerStore) GetTransactionsSince(spaceID *SpaceID, seq uint64, limit int) ([]*Transaction, error) {
+	txs := make([]*Transaction, 0)
+	err := s.view(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.IteratorOptions{
+			PrefetchValues: true,
+			PrefetchSize:   min(max(limit, 1), 100),
+			Prefix:         txKey(*spaceID, nil),
+		})
+		defer it.Close()
+
+		for it.Seek(txKey(*spaceID, seqBytes(int64(seq)+1))); it.Valid() && len(txs) < limit; it.Next() {
+			value, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			tx, err := decodeTransaction(*spaceID, value)
+			if err != nil {
+				return err
+			}
+			txs = append(txs, tx)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+// GetSnapshot returns the snapshot of the last compaction of the space.
+func (s *BadgerStore) GetSnapshot(spaceID *SpaceID) (*Snapshot, error) {
+	var snapshot *Snapshot
+	err := s.view(func(txn *badger.Txn) error {
+		item, err := txn.Get(snapshotKey(*spaceID))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		return item.Value(func(value []byte) error {
+			snapshot = &Snapshot{}
+			return json.Unmarshal(value, snapshot)
+		})
+	})
+
+	return snapshot, err
+}
+
+// PutSnapshot stores the snapshot and then deletes the transactions before it.
+// the deletes are batched outside the snapshot update, a log with leftover
+// transactions before the snapshot is still valid.
+func (s *BadgerStore) PutSnapshot(snapshot *Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	var head int64
+	err = s.update(func(txn *badger.Txn) error {
+		head, err = getTransactionSeq(txn, snapshot.SpaceID, snapshot.TransactionID)
+		if err != nil {
+			return err
+		}
+		if head < 0 {
+			return ErrTransactionNotFound{ID: snapshot.TransactionID}
+		}
+
+		return txn.Set(snapshotKey(snapshot.SpaceID), value)
+	})
+	if err != nil {
+		return err
+	}
+
+	keys := make([][]byte, 0)
+	err = s.view(func(txn *badger.Txn) error {
+		prefix := txKey(snapshot.SpaceID, nil)
+		it := txn.NewIterator(badger.IteratorOptions{PrefetchValues: true, Prefix: prefix})
+		defer it.Close()
+
+		end := txKey(snapshot.SpaceID, seqBytes(head))
+		for it.Rewind(); it.Valid() && bytes.Compare(it.Item().Key(), end) < 0; it.Next() {
+			value, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			tx, err := decodeTransaction(snapshot.SpaceID, value)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, it.Item().KeyCopy(nil), txSeqKey(snapshot.SpaceID, tx.ID))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	batch := s.db.NewWriteBatch()
+	defer batch.Cancel()
+	for _, key := range keys {
+		err = batch.Delete(key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return batch.Flush()
+}
+
+// Apply applies transactional changes to the store.
+// all the changes are committed in a single badger transaction.
+func (s *BadgerStore) Apply(tx *Transaction, change *storeChange) (*storeChange, error) {
+	if err := s.apply(tx, change); err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+func (s *BadgerStore) apply(tx *Transaction, change *storeChange) error {
+	if change == nil {
+		return errors.New("cannot apply nil change to store")
+	}
+
+	if change.jsonDocChange != nil {
+		return errors.New("json doc changes are not supported by badger store")
+	}
+
+	if change.blockChange == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.update(func(txn *badger.Txn) error {
+		spaceID := tx.SpaceID
+
+		// the transaction is already applied
+		seq, err := getTransactionSeq(txn, spaceID, tx.ID)
+		if err != nil {
+			return err
+		}
+		if seq >= 0 {
+			return nil
+		}
+
+		blockChange := change.blockChange
+		for _, block := range blockChange.inserted.ToSlice() {
+			err := putBlock(txn, spaceID, block)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, block := range blockChange.updated.ToSlice() {
+			err := updateBlock(txn, spaceID, block.ID, func(stored *Block) {
+				stored.ParentID = block.ParentID
+				stored.Index = block.Index
+				stored.Deleted = block.Deleted
+				stored.Erased = block.Erased
+			})
+			if err != nil {
+				return fmt.Errorf("move block: %w", ErrBlockNotFound{ID: block.ID})
+			}
+		}
+
+		for _, block := range blockChange.propSet.ToSlice() {
+			err := updateBlock(txn, spaceID, block.ID, func(stored *Block) {
+				stored.Props = block.Props
+			})
+			if err != nil {
+				return fmt.Errorf("prop update block: %w", ErrBlockNotFound{ID: block.ID})
+			}
+		}
+
+		// patched blocks should already exist in the store
+		for _, block := range blockChange.patched.ToSlice() {
+			err := updateBlock(txn, spaceID, block.ID, func(stored *Block) {
+				stored.Json = block.Json
+			})
+			if err != nil {
+				return fmt.Errorf("patch block: %w", ErrBlockNotFound{ID: block.ID})
+			}
+		}
+
+		//update the backlinks
+		for _, change := range blockChange.linkOps {
+			key := backLinkKey(spaceID, change.childID, &change.parentID)
+			switch change.op {
+			case OpTypeUnlink:
+				err := txn.Delete(key)
+				if err != nil {
+					return err
+				}
+			case OpTypeLink:
+				err := txn.Set(key, nil)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if tx.unlogged {
+			return nil
+		}
+
+		return appendTransaction(txn, spaceID, &Transaction{
+			ID:      tx.ID,
+			SpaceID: tx.SpaceID,
+			UserID:  tx.UserID,
+			Time:    tx.Time,
+			Clock:   tx.Clock,
+			HLC:     tx.HLC,
+			Jitter:  tx.Jitter,
+			Ops:     tx.Ops,
+			changes: change.intoSyncBlocks(),
+			inverse: change.blockChange.Inverse(),
+		})
+	})
+}
+
+// childBlocks returns the children of the block accepted by the filter, in index order.
+func (s *BadgerStore) childBlocks(spaceID *SpaceID, id BlockID, filter func(block *Block) bool) ([]*Block, error) {
+	blocks := make([]*Block, 0)
+	err := s.view(func(txn *badger.Txn) error {
+		var childIDs []BlockID
+		err := scanChildren(txn, *spaceID, id, false, func(childID BlockID) bool {
+			childIDs = append(childIDs, childID)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, childID := range childIDs {
+			block, err := getBlock(txn, *spaceID, childID)
+			if err != nil {
+				return err
+			}
+			if filter(block) {
+				blocks = append(blocks, block)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// withEdgeChild returns the block with its first child, or its last child when last is set.
+func (s *BadgerStore) withEdgeChild(spaceID *SpaceID, id BlockID, last bool) ([]*Block, error) {
+	var blocks []*Block
+	err := s.view(func(txn *badger.Txn) error {
+		block, err := getBlock(txn, *spaceID, id)
+		if err != nil {
+			return err
+		}
+		blocks = []*Block{block}
+
+		var childID *BlockID
+		err = scanChildren(txn, *spaceID, id, last, func(id BlockID) bool {
+			childID = &id
+			return false
+		})
+		if err != nil || childID == nil {
+			return err
+		}
+
+		child, err := getBlock(txn, *spaceID, *childID)
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, child)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// parentWithSibling returns the parent, the block and the next sibling, or the previous sibling when prev is set.
+func (s *BadgerStore) parentWithSibling(spaceID *SpaceID, id BlockID, prev bool) ([]*Block, error) {
+	var blocks []*Block
+	err := s.view(func(txn *badger.Txn) error {
+		record, err := getBlockRecord(txn, id)
+		if err != nil {
+			return err
+		}
+		if record == nil || record.SpaceID != *spaceID {
+			return fmt.Errorf("parent block for %v: %w", id, ErrNotFound)
+		}
+
+		block, err := record.toBlock(id)
+		if err != nil {
+			return err
+		}
+		parent, err := getBlock(txn, *spaceID, block.ParentID)
+		if err != nil {
+			return err
+		}
+		blocks = []*Block{parent, block}
+
+		// the iterator is placed on the block entry, the next item is the sibling
+		prefix := childKey(*spaceID, block.ParentID, nil, nil)
+		it := txn.NewIterator(badger.IteratorOptions{Prefix: prefix, Reverse: prev})
+		defer it.Close()
+
+		it.Seek(childKey(*spaceID, block.ParentID, block.Index.Bytes(), &id))
+		if !it.Valid() {
+			return fmt.Errorf("block siblings for %v: %w", id, ErrNotFound)
+		}
+		it.Next()
+		if !it.Valid() {
+			return nil
+		}
+
+		siblingID, err := childIDFromKey(it.Item().Key())
+		if err != nil {
+			return err
+		}
+		sibling, err := getBlock(txn, *spaceID, siblingID)
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, sibling)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// scanChildren calls fn with the child ids of the block in index order (reverse order when reverse is set)
+// until fn returns false. linked blocks are included.
+func scanChildren(txn *badger.Txn, spaceID SpaceID, id BlockID, reverse bool, fn func(id BlockID) bool) error {
+	prefix := childKey(spaceID, id, nil, nil)
+	it := txn.NewIterator(badger.IteratorOptions{Prefix: prefix, Reverse: reverse})
+	defer it.Close()
+
+	if reverse {
+		it.Seek(prefixEnd(prefix))
+	} else {
+		it.Rewind()
+	}
+
+	for ; it.Valid(); it.Next() {
+		childID, err := childIDFromKey(it.Item().Key())
+		if err != nil {
+			return err
+		}
+		if !fn(childID) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// scanChildrenFrom calls fn with the child ids in index order from the pivot child on, a nil pivot scans all the children.
+func scanChildrenFrom(txn *badger.Txn, spaceID SpaceID, id BlockID, pivot *Block, fn func(id BlockID) bool) error {
+	if pivot == nil {
+		return scanChildren(txn, spaceID, id, false, fn)
+	}
+
+	prefix := childKey(spaceID, id, nil, nil)
+	it := txn.NewIterator(badger.IteratorOptions{Prefix: prefix})
+	defer it.Close()
+
+	for it.Seek(childKey(spaceID, id, pivot.Index.Bytes(), &pivot.ID)); it.Valid(); it.Next() {
+		childID, err := childIDFromKey(it.Item().Key())
+		if err != nil {
+			return err
+		}
+		if !fn(childID) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// putBlock writes the block record and the child entry under its parent.
+func putBlock(txn *badger.Txn, spaceID SpaceID, block *Block) error {
+	value, err := json.Marshal(block.toBadgerBlock(spaceID))
+	if err != nil {
+		return err
+	}
+
+	err = txn.Set(blockKey(block.ID), value)
+	if err != nil {
+		return err
+	}
+
+	return txn.Set(childKey(spaceID, block.ParentID, block.Index.Bytes(), &block.ID), nil)
+}
+
+// updateBlock rewrites the stored block, the child entry is moved when the parent or index changes.
+func updateBlock(txn *badger.Txn, spaceID SpaceID, id BlockID, update func(stored *Block)) error {
+	block, err := getBlock(txn, spaceID, id)
+	if err != nil {
+		return err
+	}
+
+	err = txn.Delete(childKey(spaceID, block.ParentID, block.Index.Bytes(), &block.ID))
+	if err != nil {
+		return err
+	}
+
+	update(block)
+
+	return putBlock(txn, spaceID, block)
+}
+
+func getBlock(txn *badger.Txn, spaceID SpaceID, id BlockID) (*Block, error) {
+	record, err := getBlockRecord(txn, id)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || record.SpaceID != spaceID {
+		return nil, ErrBlockNotFound{ID: id}
+	}
+
+	return record.toBlock(id)
+}
+
+// getBlockRecord returns the stored block record, nil when the block does not exist.
+func getBlockRecord(txn *badger.Txn, id BlockID) (*badgerBlock, error) {
+	item, err := txn.Get(blockKey(id))
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var record badgerBlock
+	err = item.Value(func(value []byte) error {
+		return json.Unmarshal(value, &record)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// appendTransaction writes the transaction after the latest transaction of the space.
+func appendTransaction(txn *badger.Txn, spaceID SpaceID, tx *Transaction) error {
+	key, _, err := lastEntry(txn, txKey(spaceID, nil))
+	if err != nil {
+		return err
+	}
+
+	var seq int64
+	if key != nil {
+		seq = int64(binary.BigEndian.Uint64(key[len(key)-8:])) + 1
+	}
+
+	err = putTransaction(txn, spaceID, seq, tx)
+	if err != nil {
+		return err
+	}
+
+	// the changed blocks take the seq of the transaction as their version
+	for _, id := range tx.changedBlocks() {
+		err := updateBlock(txn, spaceID, id, func(stored *Block) {
+			stored.Version = tx.Seq
+		})
+		if err != nil && !errors.As(err, &ErrBlockNotFound{}) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func putTransaction(txn *badger.Txn, spaceID SpaceID, seq int64, tx *Transaction) error {
+	tx.Seq = uint64(seq)
+	value, err := json.Marshal(&badgerTransaction{
+		ID:      tx.ID,
+		UserID:  tx.UserID,
+		Time:    tx.Time,
+		Seq:     tx.Seq,
+		Clock:   tx.Clock,
+		HLC:     tx.HLC,
+		Jitter:  tx.Jitter,
+		Ops:     tx.Ops,
+		Changes: tx.changes,
+		Inverse: tx.inverse,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = txn.Set(txKey(spaceID, seqBytes(seq)), value)
+	if err != nil {
+		return err
+	}
+
+	return txn.Set(txSeqKey(spaceID, tx.ID), seqBytes(seq))
+}
+
+func getTransaction(txn *badger.Txn, spaceID SpaceID, id TransactionID) (*Transaction, error) {
+	seq, err := getTransactionSeq(txn, spaceID, id)
+	if err != nil {
+		return nil, err
+	}
+	if seq < 0 {
+		return nil, ErrTransactionNotFound{ID: id}
+	}
+
+	item, err := txn.Get(txKey(spaceID, seqBytes(seq)))
+	if err != nil {
+		return nil, err
+	}
+	value, err := item.ValueCopy(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeTransaction(spaceID, value)
+}
+
+// getTransactionSeq returns the seq of the transaction in the space log, -1 when the transaction does not exist.
+func getTransactionSeq(txn *badger.Txn, spaceID SpaceID, id TransactionID) (int64, error) {
+	item, err := txn.Get(txSeqKey(spaceID, id))
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := item.ValueCopy(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(binary.BigEndian.Uint64(value)), nil
+}
+
+func decodeTransaction(spaceID SpaceID, value []byte) (*Transaction, error) {
+	var record badgerTransaction
+	err := json.Unmarshal(value, &record)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Transaction{
+		ID:      record.ID,
+		SpaceID: spaceID,
+		UserID:  record.UserID,
+		Time:    record.Time,
+		Seq:     record.Seq,
+		Clock:   record.Clock,
+		HLC:     record.HLC,
+		Jitter:  record.Jitter,
+		Ops:     record.Ops,
+		changes: record.Changes,
+		inverse: record.Inverse,
+	}, nil
+}
+
+// lastEntry returns the last key and value with the prefix, a nil key when there is none.
+func lastEntry(txn *badger.Txn, prefix []byte) ([]byte, []byte, error) {
+	it := txn.NewIterator(badger.IteratorOptions{Prefix: prefix, Reverse: true})
+	defer it.Close()
+
+	it.Seek(prefixEnd(prefix))
+	if !it.Valid() {
+		return nil, nil, nil
+	}
+
+	item := it.Item()
+	value, err := item.ValueCopy(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return item.KeyCopy(nil), value, nil
+}
+
+// prefixEnd returns the smallest key greater than all the keys with the prefix.
+func prefixEnd(prefix []byte) []byte {
+	end := bytes.Clone(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+
+	return nil
+}
+
+func spaceKey(spaceID SpaceID) []byte {
+	return append([]byte(spacePrefix), spaceID[:]...)
+}
+
+func blockKey(id BlockID) []byte {
+	return append([]byte(blockPrefix), id[:]...)
+}
+
+// childKey returns the child entry key, with a nil index it returns the prefix of the parent children.
+// the index is escaped so that no encoded index is a prefix of another and the byte order is kept,
+// a shorter index sorts before the longer indexes it is a prefix of, like FracIndex.Compare.
+func childKey(spaceID SpaceID, parentID BlockID, index []byte, id *BlockID) []byte {
+	key := make([]byte, 0, len(childPrefix)+32+len(index)+2+16)
+	key = append(key, childPrefix...)
+	key = append(key, spaceID[:]...)
+	key = append(key, parentID[:]...)
+	if index == nil {
+		return key
+	}
+
+	key = append(key, escapeIndex(index)...)
+	if id != nil {
+		key = append(key, id[:]...)
+	}
+
+	return key
+}
+
+// escapeIndex encodes 0x00 as 0x00 0xff and terminates the index with 0x00 0x01.
+func escapeIndex(index []byte) []byte {
+	escaped := make([]byte, 0, len(index)+2)
+	for _, b := range index {
+		escaped = append(escaped, b)
+		if b == 0x00 {
+			escaped = append(escaped, 0xff)
+		}
+	}
+
+	return append(escaped, 0x00, 0x01)
+}
+
+// childIDFromKey returns the block id at the end of a child entry key.
+func childIDFromKey(key []byte) (BlockID, error) {
+	return uuid.FromBytes(key[len(key)-16:])
+}
+
+// backLinkKey returns the back link entry key, with a nil linked id it returns the prefix of the block back links.
+func backLinkKey(spaceID SpaceID, id BlockID, linkedID *BlockID) []byte {
+	key := append([]byte(backLinkPrefix), spaceID[:]...)
+	key = append(key, id[:]...)
+	if linkedID != nil {
+		key = append(key, linkedID[:]...)
+	}
+
+	return key
+}
+
+// txKey returns the transaction record key, with a nil seq it returns the prefix of the space log.
+func txKey(spaceID SpaceID, seq []byte) []byte {
+	key := append([]byte(txPrefix), spaceID[:]...)
+	return append(key, seq...)
+}
+
+func txSeqKey(spaceID SpaceID, id TransactionID) []byte {
+	key := append([]byte(txSeqPrefix), spaceID[:]...)
+	return append(key, id[:]...)
+}
+
+func snapshotKey(spaceID SpaceID) []byte {
+	return append([]byte(snapshotPrefix), spaceID[:]...)
+}
+
+func seqBytes(seq int64) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(seq))
+}
+
+// badgerBlock is the stored form of a block, the block id is part of the key.
+type badgerBlock struct {
+	SpaceID  SpaceID `json:"space_id"`
+	Type     string  `json:"type"`
+	Table    string  `json:"table,omitempty"`
+	ParentID BlockID `json:"parent_id"`
+	Index    []byte  `json:"index"`
+	Props    []byte  `json:"props,omitempty"`
+	Json     []byte  `json:"json,omitempty"`
+	Deleted  bool    `json:"deleted,omitempty"`
+	Erased   bool    `json:"erased,omitempty"`
+	Linked   bool    `json:"linked,omitempty"`
+	Version  uint64  `json:"version,omitempty"`
+}
+
+func (b *Block) toBadgerBlock(spaceID SpaceID) *badgerBlock {
+	return &badgerBlock{
+		SpaceID:  spaceID,
+		Type:     b.Type,
+		Table:    b.Table,
+		ParentID: b.ParentID,
+		Index:    b.Index.Bytes(),
+		Props:    jsonDocBytes(b.Props),
+		Json:     jsonDocBytes(b.Json),
+		Deleted:  b.Deleted,
+		Erased:   b.Erased,
+		Linked:   b.Linked,
+		Version:  b.Version,
+	}
+}
+
+func (b *badgerBlock) toBlock(id BlockID) (*Block, error) {
+	if len(b.Index) == 0 {
+		return nil, fmt.Errorf("index is empty")
+	}
+
+	block := &Block{
+		ID:       id,
+		ParentID: b.ParentID,
+		Type:     b.Type,
+		Table:    b.Table,
+		Index:    FracIndexFromBytes(b.Index),
+		Deleted:  b.Deleted,
+		Erased:   b.Erased,
+		Linked:   b.Linked,
+		Version:  b.Version,
+	}
+
+	if b.Props != nil {
+		block.Props = NewJsonDoc(b.Props)
+	}
+
+	if b.Json != nil {
+		block.Json = NewJsonDoc(b.Json)
+	}
+
+	return block, nil
+}
+
+// badgerTransaction is the stored form of a transaction, the space id is part of the key.
+type badgerTransaction struct {
+	ID      TransactionID `json:"id"`
+	UserID  uuid.UUID     `json:"user_id"`
+	Time    time.Time     `json:"time"`
+	Seq     uint64        `json:"seq"`
+	Clock   uint64        `json:"clock,omitempty"`
+	HLC     HLC           `json:"hlc,omitempty"`
+	Jitter  []byte        `json:"jitter,omitempty"`
+	Ops     []Op          `json:"ops"`
+	Changes *SyncBlocks   `json:"changes,omitempty"`
+	Inverse []Op          `json:"inverse,omitempty"`
+}
@@ -0,0 +1,125 @@
+package blocktree
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBadgerStore_ChildKeyOrder(t *testing.T) {
+	indexes := [][]byte{
+		{0x00},
+		{0x00, 0x00},
+		{0x00, 0x01},
+		{0x01},
+		{127, 128},
+		{128},
+		{128, 0x00},
+		{128, 128},
+		{129},
+		{0xff},
+		{0xff, 0xff},
+	}
+
+	for _, a := range indexes {
+		for _, b := range indexes {
+			expected := FracIndexFromBytes(a).Compare(FracIndexFromBytes(b))
+			actual := bytes.Compare(childKey(s1, b1, a, &b2), childKey(s1, b1, b, &b2))
+			assert.Equal(t, expected, actual, "%v %v", a, b)
+		}
+	}
+
+	// equal indexes are ordered by block id
+	assert.Equal(t, -1, bytes.Compare(childKey(s1, b1, []byte{128}, &b2), childKey(s1, b1, []byte{128}, &b3)))
+}
+
+func TestBadgerStore_Reopen(t *testing.T) {
+	path := t.TempDir()
+	store, err := NewBadgerStore(path)
+	assert.NoError(t, err)
+
+	err = createSpace(store, s1)
+	assert.NoError(t, err)
+	applyTransaction(t, store, createTx(s1,
+		insertOp(b1, "p1", s1, PositionEnd),
+		insertOp(b2, "p2", b1, PositionEnd),
+	))
+	assert.NoError(t, store.Close())
+
+	store, err = NewBadgerStore(path)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	blocks, err := store.GetDescendantBlocks(&s1, s1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(blocks))
+
+	txs, err := store.GetNextTransactions(&s1, uuid.Nil, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(txs))
+}
+
+func TestBadgerStore_Siblings(t *testing.T) {
+	store, err := NewInMemoryBadgerStore()
+	assert.NoError(t, err)
+	defer store.Close()
+	memStore := NewMemStore()
+
+	for _, s := range []Store{store, memStore} {
+		err = createSpace(s, s1)
+		assert.NoError(t, err)
+	}
+
+	// repeated inserts at the same spot grow the index bytes
+	txs := []*Transaction{createTx(s1, insertOp(b1, "p1", s1, PositionEnd))}
+	ids := []BlockID{b1}
+	for i := 0; i < 30; i++ {
+		id := uuid.New()
+		txs = append(txs, createTx(s1, insertOp(id, "p", ids[len(ids)/2], PositionAfter)))
+		ids = append(ids, id)
+	}
+
+	for _, tx := range txs {
+		applyTransaction(t, store, tx)
+		applyTransaction(t, memStore, tx)
+	}
+
+	expected, err := memStore.GetChildrenBlockIDs(&s1, s1)
+	assert.NoError(t, err)
+	actual, err := store.GetChildrenBlockIDs(&s1, s1)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	for _, id := range ids {
+		next, err := store.GetParentWithNextBlock(&s1, id)
+		assert.NoError(t, err)
+		memNext, err := memStore.GetParentWithNextBlock(&s1, id)
+		assert.NoError(t, err)
+		assert.Equal(t, blockIDs(memNext), blockIDs(next))
+
+		prev, err := store.GetParentWithPrevBlock(&s1, id)
+		assert.NoError(t, err)
+		memPrev, err := memStore.GetParentWithPrevBlock(&s1, id)
+		assert.NoError(t, err)
+		assert.Equal(t, blockIDs(memPrev), blockIDs(prev))
+	}
+
+	first, err := store.GetWithFirstChildBlock(&s1, s1)
+	assert.NoError(t, err)
+	assert.Equal(t, expected[0], first[1].ID)
+
+	last, err := store.GetWithLastChildBlock(&s1, s1)
+	assert.NoError(t, err)
+	assert.Equal(t, expected[len(expected)-1], last[1].ID)
+}
+
+func blockIDs(blocks []*Block) []BlockID {
+	ids := make([]BlockID, len(blocks))
+	for i, block := range blocks {
+		ids[i] = block.ID
+	}
+
+	return ids
+}
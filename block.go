@@ -45,6 +45,7 @@ type BlockView struct {
 	Json     *JsonDoc
 	Deleted  bool
 	Erased   bool
+	Version  uint64
 }
 
 // BlockViewFromBlock creates a blockView from a block
@@ -57,6 +58,7 @@ func BlockViewFromBlock(block *Block) *BlockView {
 		Json:     block.Json,
 		Deleted:  block.Deleted,
 		Erased:   block.Erased,
+		Version:  block.Version,
 	}
 }
 
@@ -157,6 +159,8 @@ type Block struct {
 	Erased      bool // permanent delete
 	Linked      bool // linked blocks
 	UpdateFlags uint32
+	// Version is the seq of the last transaction that changed the block or its children
+	Version uint64
 }
 
 // NewBlock creates a new block
@@ -183,11 +187,12 @@ func (b *Block) Clone() *Block {
 		ID:       b.ID,
 		ParentID: b.ParentID,
 		Index:    b.Index.Clone(),
-		Props:    b.Props,
+		Props:    b.Props.Clone(),
 		Json:     b.Json.Clone(),
 		Deleted:  b.Deleted,
 		Erased:   b.Erased,
 		Linked:   b.Linked,
+		Version:  b.Version,
 	}
 }
 
@@ -1,7 +1,8 @@
 package blocktree
 
 import (
-	"errors"
+	"bytes"
+	"encoding/json"
 	"fmt"
 
 	mapset "github.com/deckarep/golang-set/v2"
@@ -62,26 +63,37 @@ type stageTable struct {
 	blocks   map[BlockID]*Block
 	change   *blockChange
 	parking  map[BlockID]*Block
+	// prevs tracks the previous sibling of the moved blocks, nil for the first child
+	prevs map[BlockID]*BlockID
+	// jitter is the suffix of the indices of the placed blocks, nil places the blocks without a suffix
+	jitter []byte
+	// allocated holds the parked blocks of an insert run that already have their index
+	allocated *Set[BlockID]
 }
 
 // newStageTable creates a new stageTable
 func newStageTable() *stageTable {
 	return &stageTable{
-		children: make(map[ParentID]*btree.BTreeG[*Block]),
-		blocks:   make(map[BlockID]*Block),
-		change:   newBlockChange(),
-		parking:  make(map[BlockID]*Block),
+		children:  make(map[ParentID]*btree.BTreeG[*Block]),
+		blocks:    make(map[BlockID]*Block),
+		change:    newBlockChange(),
+		parking:   make(map[BlockID]*Block),
+		prevs:     make(map[BlockID]*BlockID),
+		allocated: NewSet[BlockID](),
 	}
 }
 
 func (st *stageTable) Apply(tx *Transaction) (*blockChange, error) {
-	for _, op := range tx.Ops {
+	st.jitter = tx.Jitter
+
+	ops := tx.ops()
+	for i, op := range ops {
 		logrus.Debugf("applying op: %s", op.String())
 		switch op.Type {
 		case OpTypeInsert:
 			block, ok := st.parking[op.BlockID]
 			if !ok {
-				return nil, errors.New("insert block not found")
+				return nil, fmt.Errorf("insert block: %w", ErrBlockNotFound{ID: op.BlockID})
 			}
 			// NOTE: space insertion is a special case
 			// not need to update index
@@ -92,147 +104,277 @@ func (st *stageTable) Apply(tx *Transaction) (*blockChange, error) {
 
 			parentId := block.ParentID
 			if parentId == uuid.Nil {
-				return nil, errors.New("parent id is nil for insert block")
+				return nil, fmt.Errorf("%w: parent id is nil for insert block: %v", ErrInvalidOp, op.BlockID)
 			}
 			parent, ok := st.block(op.At.BlockID)
 			if !ok {
-				return nil, errors.New("parent block not found for insert at start")
-			}
-			switch op.At.Position {
-			case PositionStart:
-				st.paceAtStart(block, op.At.BlockID, Inserted)
-			case PositionEnd:
-				st.paceAtEnd(block, op.At.BlockID, Inserted)
-			case PositionBefore:
-				err := st.placeBefore(block, op.At.BlockID, Inserted)
-				if err != nil {
-					return nil, err
-				}
-			case PositionAfter:
-				err := st.placeAfter(block, op.At.BlockID, Inserted)
-				if err != nil {
-					return nil, err
+				return nil, fmt.Errorf("parent for insert block: %w", ErrBlockNotFound{ID: op.At.BlockID})
+			}
+			// the consecutive siblings inserted by the ops from here on get their indices at once
+			if !st.allocated.Contains(block.ID) {
+				if run := st.insertRun(ops[i:]); len(run) > 1 {
+					err := st.allocateRun(op.At, run)
+					if err != nil {
+						return nil, err
+					}
 				}
-			case PositionInside:
-				if block.Linked {
-					st.placeInside(block, op.At.BlockID, Inserted)
-				} else {
-					return nil, errors.New("invalid position inside for insert block")
+			}
+
+			if st.allocated.Contains(block.ID) {
+				st.allocated.Remove(block.ID)
+				st.updateChange(block, Inserted)
+			} else {
+				switch op.At.Position {
+				case PositionStart:
+					st.paceAtStart(block, op.At.BlockID, Inserted)
+				case PositionEnd:
+					st.paceAtEnd(block, op.At.BlockID, Inserted)
+				case PositionBefore:
+					err := st.placeBefore(block, op.At.BlockID, Inserted)
+					if err != nil {
+						return nil, err
+					}
+				case PositionAfter:
+					err := st.placeAfter(block, op.At.BlockID, Inserted)
+					if err != nil {
+						return nil, err
+					}
+				case PositionInside:
+					if block.Linked {
+						st.placeInside(block, op.At.BlockID, Inserted)
+					} else {
+						return nil, fmt.Errorf("%w inside for insert block", ErrInvalidPosition)
+					}
 				}
 			}
 
 			st.unpark(block.ID)
 			st.add(block)
+			st.trackPosition(block)
 			st.change.addPropSet(parent)
 			st.change.addChildren(block.ParentID)
+			st.change.addInverse(Op{
+				Table:   op.Table,
+				Type:    OpTypeErase,
+				BlockID: block.ID,
+			})
 
 		case OpTypeMove:
 			block, ok := st.block(op.BlockID)
 			if !ok {
-				return nil, fmt.Errorf("move block not found: %v", op.BlockID)
+				return nil, fmt.Errorf("move block: %w", ErrBlockNotFound{ID: op.BlockID})
 			}
 			parentId := block.ParentID
 			if parentId == uuid.Nil {
-				return nil, fmt.Errorf("old parent id is nil for move block: %v", op.BlockID)
+				return nil, fmt.Errorf("%w: old parent id is nil for move block: %v", ErrInvalidOp, op.BlockID)
 			}
 			parent, ok := st.block(parentId)
 			//logrus.Infof("existing blocks: %v", st.existingIDs())
 			if !ok {
-				return nil, errors.New("old parent block not found for move block")
+				return nil, fmt.Errorf("old parent for move block: %w", ErrBlockNotFound{ID: parentId})
 			}
 
 			st.change.addChildren(*op.ParentID)
+			st.change.addMoved(block)
+			inverse := Op{
+				Table:   op.Table,
+				Type:    OpTypeMove,
+				BlockID: block.ID,
+				At:      st.position(block),
+			}
 
-			// remove block from its current position
-			// to ensure that the block (subtree) is not in the tree
-			// the subtree nodes are still in the table but the connection is removed
-			st.remove(block)
+			if op.At.Position == PositionInside && op.Index == nil {
+				return nil, fmt.Errorf("%w inside for move block", ErrInvalidPosition)
+			}
 
-			switch op.At.Position {
-			case PositionStart:
-				st.paceAtStart(block, op.At.BlockID, Updated)
-			case PositionEnd:
-				st.paceAtEnd(block, op.At.BlockID, Updated)
-			case PositionBefore:
-				err := st.placeBefore(block, op.At.BlockID, Updated)
-				if err != nil {
-					return nil, err
+			if op.At.Position == PositionInside {
+				// the reindexed block stays with its siblings, only its index changes
+				if block.ParentID != op.At.BlockID {
+					return nil, fmt.Errorf("%w: reindexed block %v is not a child of %v", ErrInvalidPosition, block.ID, op.At.BlockID)
 				}
-			case PositionAfter:
-				err := st.placeAfter(block, op.At.BlockID, Updated)
-				if err != nil {
-					return nil, err
+				inverse.At = &Pointer{BlockID: block.ParentID, Position: PositionInside}
+				inverse.Index = block.Index.Bytes()
+				st.remove(block)
+				block.Index = FracIndexFromBytes(bytes.Clone(op.Index))
+				st.add(block)
+				st.updateChange(block, Updated)
+			} else if st.atPosition(block, op.At) {
+				// a block already at the position keeps its index,
+				// the siblings on its other side are not loaded to place it again
+				st.updateChange(block, Updated)
+			} else {
+				// remove block from its current position
+				// to ensure that the block (subtree) is not in the tree
+				// the subtree nodes are still in the table but the connection is removed
+				st.remove(block)
+
+				switch op.At.Position {
+				case PositionStart:
+					st.paceAtStart(block, op.At.BlockID, Updated)
+				case PositionEnd:
+					st.paceAtEnd(block, op.At.BlockID, Updated)
+				case PositionBefore:
+					err := st.placeBefore(block, op.At.BlockID, Updated)
+					if err != nil {
+						return nil, err
+					}
+				case PositionAfter:
+					err := st.placeAfter(block, op.At.BlockID, Updated)
+					if err != nil {
+						return nil, err
+					}
 				}
-			case PositionInside:
-				return nil, errors.New("invalid position inside for move block")
-			}
 
-			st.add(block)
+				st.add(block)
+			}
+			st.trackPosition(block)
 			st.change.addPropSet(parent)
 			st.change.addChildren(parent.ID)
 			st.change.addChildren(block.ParentID)
 
+			movedFrom := block.ParentID
+			inverse.ParentID = &movedFrom
+			st.change.addInverse(inverse)
+
+		case opTypePlace:
+			block, ok := st.block(op.BlockID)
+			if !ok {
+				return nil, fmt.Errorf("place block: %w", ErrBlockNotFound{ID: op.BlockID})
+			}
+			if _, ok := st.block(op.At.BlockID); !ok {
+				return nil, fmt.Errorf("parent for place block: %w", ErrBlockNotFound{ID: op.At.BlockID})
+			}
+
+			// the block goes back to its exact index, the siblings are not needed
+			st.change.addChildren(block.ParentID)
+			st.remove(block)
+			block.ParentID = op.At.BlockID
+			block.Index = FracIndexFromBytes(bytes.Clone(op.Index))
+			st.add(block)
+			st.updateChange(block, Updated)
+			st.change.addChildren(block.ParentID)
+
 		case OpTypeUpdate:
 			block, ok := st.block(op.BlockID)
 			if !ok {
-				return nil, errors.New("update block not found")
+				return nil, fmt.Errorf("update block: %w", ErrBlockNotFound{ID: op.BlockID})
+			}
+			before := block.Props.Clone()
+			if before == nil {
+				before = DefaultJsonDoc()
 			}
 			err := block.mergeProps(op.Props)
 			if err != nil {
 				return nil, err
 			}
 			st.change.addPropSet(block)
+
+			patch, err := block.Props.Diff(before)
+			if err != nil {
+				return nil, err
+			}
+			st.change.addInverse(Op{
+				Table:   op.Table,
+				Type:    OpTypeUpdate,
+				BlockID: block.ID,
+				Props:   patch,
+			})
 		case OpTypePatch:
 			block, ok := st.block(op.BlockID)
 			if !ok {
-				return nil, errors.New("patch block not found")
+				return nil, fmt.Errorf("patch block: %w", ErrBlockNotFound{ID: op.BlockID})
 			}
 			if block.Json == nil {
 				block.Json = DefaultJsonDoc()
 			}
+			before := block.Json.Clone()
 			err := block.Json.Apply(op.Patch)
 			if err != nil {
 				return nil, err
 			}
 			st.change.addUpdated(block)
 			st.change.addPatched(block)
+
+			patch, err := block.Json.Diff(before)
+			if err != nil {
+				return nil, err
+			}
+			st.change.addInverse(Op{
+				Table:   op.Table,
+				Type:    OpTypePatch,
+				BlockID: block.ID,
+				Patch:   patch,
+			})
+		case OpTypeText:
+			block, ok := st.block(op.BlockID)
+			if !ok {
+				return nil, fmt.Errorf("text block: %w", ErrBlockNotFound{ID: op.BlockID})
+			}
+			var patch TextPatch
+			if err := json.Unmarshal(op.Patch, &patch); err != nil {
+				return nil, fmt.Errorf("%w: text patch of block %v: %v", ErrInvalidOp, block.ID, err)
+			}
+			if block.Json == nil {
+				block.Json = DefaultJsonDoc()
+			}
+			inverse, err := block.Json.ApplyText(&patch)
+			if err != nil {
+				return nil, fmt.Errorf("%w: text patch of block %v: %v", ErrInvalidOp, block.ID, err)
+			}
+			st.change.addUpdated(block)
+			st.change.addPatched(block)
+
+			data, err := json.Marshal(inverse)
+			if err != nil {
+				return nil, err
+			}
+			st.change.addInverse(Op{
+				Table:   op.Table,
+				Type:    OpTypeText,
+				BlockID: block.ID,
+				Patch:   data,
+			})
 		case OpTypeDelete:
 			block, ok := st.block(op.BlockID)
 			if !ok {
-				return nil, errors.New("delete block not found")
+				return nil, fmt.Errorf("delete block: %w", ErrBlockNotFound{ID: op.BlockID})
 			}
+			st.change.addInverse(st.flagInverse(op, block.Deleted, OpTypeDelete, OpTypeUndelete))
 			block.Deleted = true
 			st.change.addUpdated(block)
 		case OpTypeUndelete:
 			block, ok := st.block(op.BlockID)
 			if !ok {
-				return nil, errors.New("undelete block not found")
+				return nil, fmt.Errorf("undelete block: %w", ErrBlockNotFound{ID: op.BlockID})
 			}
+			st.change.addInverse(st.flagInverse(op, block.Deleted, OpTypeDelete, OpTypeUndelete))
 			block.Deleted = false
 			st.change.addUpdated(block)
 		case OpTypeErase:
 			block, ok := st.block(op.BlockID)
 			if !ok {
-				return nil, errors.New("erase block not found")
+				return nil, fmt.Errorf("erase block: %w", ErrBlockNotFound{ID: op.BlockID})
 			}
+			st.change.addInverse(st.flagInverse(op, block.Erased, OpTypeErase, OpTypeRestore))
 			block.Erased = true
 			st.change.addUpdated(block)
 		case OpTypeRestore:
 			block, ok := st.block(op.BlockID)
 			if !ok {
-				return nil, errors.New("restore block not found")
+				return nil, fmt.Errorf("restore block: %w", ErrBlockNotFound{ID: op.BlockID})
 			}
+			st.change.addInverse(st.flagInverse(op, block.Erased, OpTypeErase, OpTypeRestore))
 			block.Erased = false
 			st.change.addUpdated(block)
 		case OpTypeLink:
 			block, ok := st.block(op.BlockID)
 			if !ok {
-				return nil, errors.New("link block not found")
+				return nil, fmt.Errorf("link block: %w", ErrBlockNotFound{ID: op.BlockID})
 			}
 			block.Linked = true
 			parent, ok := st.block(op.At.BlockID)
 			if !ok {
-				return nil, errors.New("link parent block not found")
+				return nil, fmt.Errorf("link parent: %w", ErrBlockNotFound{ID: op.At.BlockID})
 			}
 			block.ParentID = parent.ID
 			st.change.addUpdated(block)
@@ -241,10 +383,15 @@ func (st *stageTable) Apply(tx *Transaction) (*blockChange, error) {
 				parentID: block.ParentID,
 				childID:  block.ID,
 			})
+			st.change.addInverse(Op{
+				Table:   op.Table,
+				Type:    OpTypeUnlink,
+				BlockID: block.ID,
+			})
 		case OpTypeUnlink:
 			block, ok := st.block(op.BlockID)
 			if !ok {
-				return nil, errors.New("unlink block not found")
+				return nil, fmt.Errorf("unlink block: %w", ErrBlockNotFound{ID: op.BlockID})
 			}
 			//TODO: check if this is correct
 			st.change.addUpdated(block)
@@ -253,6 +400,12 @@ func (st *stageTable) Apply(tx *Transaction) (*blockChange, error) {
 				parentID: block.ParentID,
 				childID:  block.ID,
 			})
+			st.change.addInverse(Op{
+				Table:   op.Table,
+				Type:    OpTypeLink,
+				BlockID: block.ID,
+				At:      &Pointer{BlockID: block.ParentID, Position: PositionInside},
+			})
 			block.ParentID = uuid.Nil
 		}
 	}
@@ -260,6 +413,57 @@ func (st *stageTable) Apply(tx *Transaction) (*blockChange, error) {
 	return st.change, nil
 }
 
+// position returns the pointer that places the block back at its current position
+func (st *stageTable) position(block *Block) *Pointer {
+	if prevID := st.prevs[block.ID]; prevID != nil {
+		return &Pointer{BlockID: *prevID, Position: PositionAfter}
+	}
+
+	return &Pointer{BlockID: block.ParentID, Position: PositionStart}
+}
+
+// trackPosition records the previous sibling of a placed block
+func (st *stageTable) trackPosition(block *Block) {
+	siblings, err := st.withPrevSibling(block.ID)
+	if err != nil || len(siblings) < 2 {
+		st.prevs[block.ID] = nil
+		return
+	}
+
+	prevID := siblings[1].ID
+	st.prevs[block.ID] = &prevID
+}
+
+// setPrevSibling records the previous sibling of a block as loaded from the store
+func (st *stageTable) setPrevSibling(id BlockID, prev *Block) {
+	if prev == nil {
+		st.prevs[id] = nil
+		return
+	}
+
+	prevID := prev.ID
+	st.prevs[id] = &prevID
+}
+
+func (st *stageTable) hasPrevSibling(id BlockID) bool {
+	_, ok := st.prevs[id]
+	return ok
+}
+
+// flagInverse returns the op that sets a block flag back to its value before the op
+func (st *stageTable) flagInverse(op Op, before bool, set, unset OpType) Op {
+	inverse := Op{
+		Table:   op.Table,
+		Type:    unset,
+		BlockID: op.BlockID,
+	}
+	if before {
+		inverse.Type = set
+	}
+
+	return inverse
+}
+
 //func (st *stageTable) existingIDs() []BlockID {
 //	ids := make([]BlockID, 0, len(st.blocks))
 //	for id := range st.blocks {
@@ -268,12 +472,31 @@ func (st *stageTable) Apply(tx *Transaction) (*blockChange, error) {
 //	return ids
 //}
 
+// atPosition returns true if the block is already at the position
+func (st *stageTable) atPosition(block *Block, at *Pointer) bool {
+	switch at.Position {
+	case PositionStart:
+		first, ok := st.firstChild(at.BlockID)
+		return ok && first.ID == block.ID
+	case PositionEnd:
+		last, ok := st.lastChild(at.BlockID)
+		return ok && last.ID == block.ID
+	case PositionBefore:
+		siblings, err := st.withPrevSibling(at.BlockID)
+		return err == nil && len(siblings) == 2 && siblings[1].ID == block.ID
+	case PositionAfter:
+		siblings, err := st.withNextSibling(at.BlockID)
+		return err == nil && len(siblings) == 2 && siblings[1].ID == block.ID
+	}
+	return false
+}
+
 func (st *stageTable) paceAtStart(block *Block, parentID BlockID, action blockChangeType) {
 	firstChild, ok := st.firstChild(parentID)
 	if ok {
-		block.Index = NewBefore(firstChild.Index)
+		block.Index = st.jittered(NewBefore(firstChild.Index), firstChild.Index)
 	} else {
-		block.Index = DefaultFracIndex()
+		block.Index = st.jittered(DefaultFracIndex(), nil)
 	}
 	block.ParentID = parentID
 	st.updateChange(block, action)
@@ -282,9 +505,9 @@ func (st *stageTable) paceAtStart(block *Block, parentID BlockID, action blockCh
 func (st *stageTable) paceAtEnd(block *Block, parentID BlockID, action blockChangeType) {
 	lastChild, ok := st.lastChild(parentID)
 	if ok {
-		block.Index = NewAfter(lastChild.Index)
+		block.Index = st.jittered(NewAfter(lastChild.Index), nil)
 	} else {
-		block.Index = DefaultFracIndex()
+		block.Index = st.jittered(DefaultFracIndex(), nil)
 	}
 	block.ParentID = parentID
 	st.updateChange(block, action)
@@ -296,11 +519,11 @@ func (st *stageTable) placeBefore(block *Block, nextID BlockID, action blockChan
 		return err
 	}
 	if len(sibling) == 0 {
-		return errors.New("reference block is not found for place before")
+		return fmt.Errorf("reference for place before: %w", ErrBlockNotFound{ID: nextID})
 	}
 
 	if len(sibling) == 1 {
-		block.Index = NewBefore(sibling[0].Index)
+		block.Index = st.jittered(NewBefore(sibling[0].Index), sibling[0].Index)
 		block.ParentID = sibling[0].ParentID
 		st.updateChange(block, action)
 	} else if len(sibling) == 2 {
@@ -308,10 +531,11 @@ func (st *stageTable) placeBefore(block *Block, nextID BlockID, action blockChan
 		if err != nil {
 			return err
 		}
+		block.Index = st.jittered(block.Index, sibling[0].Index)
 		block.ParentID = sibling[0].ParentID
 		st.updateChange(block, action)
 	} else {
-		return errors.New("invalid sibling count for place before")
+		return fmt.Errorf("%w: invalid sibling count for place before", ErrInvalidPosition)
 	}
 
 	return nil
@@ -323,11 +547,11 @@ func (st *stageTable) placeAfter(block *Block, prevID BlockID, action blockChang
 		return err
 	}
 	if len(sibling) == 0 {
-		return errors.New("reference block is not found for place after")
+		return fmt.Errorf("reference for place after: %w", ErrBlockNotFound{ID: prevID})
 	}
 
 	if len(sibling) == 1 {
-		block.Index = NewAfter(sibling[0].Index)
+		block.Index = st.jittered(NewAfter(sibling[0].Index), nil)
 		block.ParentID = sibling[0].ParentID
 		st.updateChange(block, action)
 	} else if len(sibling) == 2 {
@@ -335,17 +559,127 @@ func (st *stageTable) placeAfter(block *Block, prevID BlockID, action blockChang
 		if err != nil {
 			return err
 		}
+		block.Index = st.jittered(block.Index, sibling[1].Index)
 		block.ParentID = sibling[0].ParentID
 		st.updateChange(block, action)
 	} else {
-		return errors.New("invalid sibling count for place after")
+		return fmt.Errorf("%w: invalid sibling count for place after", ErrInvalidPosition)
 	}
 
 	return nil
 }
 
+// insertRun returns the parked blocks the ops insert one after another from the first op,
+// each op of the run inserts after the block of the op before it or at the end of the same parent.
+func (st *stageTable) insertRun(ops []Op) []*Block {
+	run := make([]*Block, 0)
+	for i, op := range ops {
+		if op.Type != OpTypeInsert || op.At == nil {
+			break
+		}
+		block, ok := st.parking[op.BlockID]
+		if !ok || block.Linked || block.Type == "space" {
+			break
+		}
+
+		if i > 0 {
+			prev := ops[i-1]
+			after := op.At.Position == PositionAfter && op.At.BlockID == prev.BlockID
+			atEnd := op.At.Position == PositionEnd && prev.At.Position == PositionEnd && op.At.BlockID == prev.At.BlockID
+			if !after && !atEnd {
+				break
+			}
+		} else if op.At.Position == PositionInside {
+			break
+		}
+
+		run = append(run, block)
+	}
+
+	return run
+}
+
+// allocateRun places the blocks of an insert run at the position of the first op of the run,
+// the blocks get short indices spread evenly between the siblings around the position.
+func (st *stageTable) allocateRun(at *Pointer, run []*Block) error {
+	var parentID BlockID
+	var left, right *FracIndex
+	switch at.Position {
+	case PositionStart:
+		parentID = at.BlockID
+		if first, ok := st.firstChild(parentID); ok {
+			right = first.Index
+		}
+	case PositionEnd:
+		parentID = at.BlockID
+		if last, ok := st.lastChild(parentID); ok {
+			left = last.Index
+		}
+	case PositionBefore:
+		sibling, err := st.withPrevSibling(at.BlockID)
+		if err != nil {
+			return err
+		}
+		if len(sibling) == 0 {
+			return fmt.Errorf("reference for place before: %w", ErrBlockNotFound{ID: at.BlockID})
+		}
+		parentID, right = sibling[0].ParentID, sibling[0].Index
+		if len(sibling) == 2 {
+			left = sibling[1].Index
+		}
+	case PositionAfter:
+		sibling, err := st.withNextSibling(at.BlockID)
+		if err != nil {
+			return err
+		}
+		if len(sibling) == 0 {
+			return fmt.Errorf("reference for place after: %w", ErrBlockNotFound{ID: at.BlockID})
+		}
+		parentID, left = sibling[0].ParentID, sibling[0].Index
+		if len(sibling) == 2 {
+			right = sibling[1].Index
+		}
+	default:
+		return fmt.Errorf("%w: %v for insert run", ErrInvalidPosition, at.Position)
+	}
+
+	indices, err := NewNBetween(left, right, len(run))
+	if err != nil {
+		return err
+	}
+	for i, block := range run {
+		next := right
+		if i+1 < len(indices) {
+			next = indices[i+1]
+		}
+		block.Index = st.jittered(indices[i], next)
+		block.ParentID = parentID
+		st.allocated.Add(block.ID)
+	}
+
+	return nil
+}
+
+// jittered suffixes the index with the jitter of the transaction, the blocks placed at the same spot by
+// concurrent transactions get different indices ordered by the transaction ids. the index is kept when
+// the suffixed index does not come before the next sibling.
+func (st *stageTable) jittered(index, next *FracIndex) *FracIndex {
+	if st.jitter == nil {
+		return index
+	}
+
+	suffixed := index.withSuffix(st.jitter)
+	if next != nil && suffixed.Compare(next) >= 0 {
+		return index
+	}
+
+	return suffixed
+}
+
+// placeInside places a linked block after the last child of the parent,
+// the linked blocks share the children index space with the other children.
 func (st *stageTable) placeInside(block *Block, parentID BlockID, action blockChangeType) {
-	st.updateChange(block, action)
+	st.paceAtEnd(block, parentID, action)
 }
 
 func (st *stageTable) updateChange(block *Block, changeType blockChangeType) {
@@ -392,7 +726,7 @@ func (st *stageTable) withNextSibling(id BlockID) ([]*Block, error) {
 	blocks := make([]*Block, 0, 2)
 	block, ok := st.block(id)
 	if !ok {
-		return blocks, errors.New("block not found")
+		return blocks, ErrBlockNotFound{ID: id}
 	}
 	if tree, ok := st.children[block.ParentID]; ok {
 		if tree.Len() > 0 {
@@ -411,7 +745,7 @@ func (st *stageTable) withPrevSibling(id BlockID) ([]*Block, error) {
 	blocks := make([]*Block, 0, 2)
 	block, ok := st.block(id)
 	if !ok {
-		return blocks, errors.New("block not found")
+		return blocks, ErrBlockNotFound{ID: id}
 	}
 	if tree, ok := st.children[block.ParentID]; ok {
 		if tree.Len() > 0 {
@@ -493,6 +827,10 @@ type blockChange struct {
 	patched  *Set[*Block]
 	children *Set[BlockID]
 	linkOps  []linkChangeOp
+	// inverse holds the ops that revert the applied ops, in the applied order
+	inverse []Op
+	// moved holds the moved blocks as they were before their first move
+	moved []*Block
 }
 
 // NewBlockChange creates a new blockChange
@@ -504,6 +842,8 @@ func newBlockChange() *blockChange {
 		propSet:  NewSet[*Block](),
 		patched:  NewSet[*Block](),
 		linkOps:  make([]linkChangeOp, 0),
+		inverse:  make([]Op, 0),
+		moved:    make([]*Block, 0),
 	}
 }
 
@@ -537,6 +877,16 @@ func (bc *blockChange) PropSet() []*Block {
 	return blocks
 }
 
+// Inverse returns the ops that revert the change, the last applied op is reverted first
+func (bc *blockChange) Inverse() []Op {
+	ops := make([]Op, len(bc.inverse))
+	for i, op := range bc.inverse {
+		ops[len(ops)-1-i] = op
+	}
+
+	return ops
+}
+
 func (bc *blockChange) addInserted(id *Block) {
 	bc.inserted.Add(id)
 }
@@ -561,6 +911,21 @@ func (bc *blockChange) addLinkOp(op linkChangeOp) {
 	bc.linkOps = append(bc.linkOps, op)
 }
 
+func (bc *blockChange) addInverse(op Op) {
+	bc.inverse = append(bc.inverse, op)
+}
+
+// addMoved keeps a copy of the block before its first move in the change
+func (bc *blockChange) addMoved(block *Block) {
+	for _, moved := range bc.moved {
+		if moved.ID == block.ID {
+			return
+		}
+	}
+
+	bc.moved = append(bc.moved, block.Clone())
+}
+
 //func (bc *blockChange) empty() bool {
 //	return bc.inserted.Size() == 0 && bc.updated.Size() == 0 && bc.propSet.Size() == 0
 //}
@@ -593,25 +958,25 @@ func newMoveTree(spaceId SpaceID) *moveTree {
 // move moves a block to a new parent
 func (mt *moveTree) move(child, parent BlockID) error {
 	if mt.spaceId == child {
-		return errors.New("cannot move space block")
+		return fmt.Errorf("%w: cannot move space block", ErrInvalidPosition)
 	}
 
 	if parent == child {
-		return errors.New("cannot move block to itself")
+		return fmt.Errorf("%w: cannot move block to itself", ErrCreatesCycle)
 	}
 
 	if !mt.blocks.Contains(child) {
-		return errors.New("child block not found")
+		return fmt.Errorf("child: %w", ErrBlockNotFound{ID: child})
 	}
 
 	if !mt.blocks.Contains(parent) {
-		return errors.New("parent block not found")
+		return fmt.Errorf("parent: %w", ErrBlockNotFound{ID: parent})
 	}
 
 	// if child_id is already a child of parent_id, then the move is not needed
 	currParent, ok := mt.backEdges[child]
 	if !ok {
-		return errors.New("child block not found")
+		return fmt.Errorf("child: %w", ErrBlockNotFound{ID: child})
 	}
 	if currParent == parent {
 		return nil
@@ -653,6 +1018,68 @@ func (mt *moveTree) addEdge(child, parent BlockID) {
 	mt.backEdges[child] = parent
 }
 
+// apply changes the parent of the block inserted or moved by the op.
+// a move that creates a cycle returns ErrCreatesCycle or ErrDetectedCycle and leaves the tree as it is.
+func (mt *moveTree) apply(op *Op) error {
+	switch op.Type {
+	case OpTypeInsert:
+		if op.At == nil {
+			return fmt.Errorf("%w: invalid create op without at: %v", ErrInvalidPosition, op)
+		}
+		switch op.At.Position {
+		case PositionAfter, PositionBefore:
+			parentID, ok := mt.getParent(op.At.BlockID)
+			if !ok {
+				return fmt.Errorf("%w: cannot find parent for insert after/before: %v", ErrNotFound, op)
+			}
+			mt.addEdge(op.BlockID, *parentID)
+		case PositionStart, PositionEnd:
+			if !mt.contains(op.At.BlockID) {
+				return fmt.Errorf("%w: cannot find parent for insert start/end: %v", ErrNotFound, op)
+			}
+			mt.addEdge(op.BlockID, op.At.BlockID)
+		case PositionInside:
+			// linked blocks are the only blocks inserted inside a block
+			if !op.Linked {
+				return fmt.Errorf("%w: cannot insert inside a block: %v", ErrInvalidPosition, op)
+			}
+			mt.addEdge(op.BlockID, op.At.BlockID)
+		}
+	case OpTypeMove, opTypePlace:
+		if op.At == nil {
+			return fmt.Errorf("%w: invalid move op without at: %v", ErrInvalidPosition, op)
+		}
+		if op.At.BlockID == op.BlockID {
+			return fmt.Errorf("%w: move op with same block id: %v", ErrInvalidOp, op)
+		}
+
+		switch op.At.Position {
+		case PositionAfter, PositionBefore:
+			parentID, ok := mt.getParent(op.At.BlockID)
+			if !ok {
+				return fmt.Errorf("%w: cannot find parent for move after/before: %v", ErrNotFound, op)
+			}
+			return mt.move(op.BlockID, *parentID)
+		case PositionStart, PositionEnd:
+			return mt.move(op.BlockID, op.At.BlockID)
+		case PositionInside:
+			// placed blocks are put inside their parent at an exact index
+			if op.Type == opTypePlace {
+				return mt.move(op.BlockID, op.At.BlockID)
+			}
+			// reindexed blocks stay inside their parent
+			if op.Index != nil {
+				parentID, ok := mt.getParent(op.BlockID)
+				if !ok || *parentID != op.At.BlockID {
+					return fmt.Errorf("%w: block %v is not a child of %v", errMovedAway, op.BlockID, op.At.BlockID)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 // getParent returns the parent of a block
 func (mt *moveTree) getParent(block BlockID) (*BlockID, bool) {
 	if parent, ok := mt.backEdges[block]; ok {
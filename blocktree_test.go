@@ -1,10 +1,13 @@
 package blocktree
 
 import (
+	"bytes"
+	"math/rand"
 	"testing"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMoveTree_Move(t *testing.T) {
@@ -26,6 +29,9 @@ func TestMoveTree_Move(t *testing.T) {
 	err = tree.move(b, a)
 	assert.Equal(t, err, ErrCreatesCycle)
 
+	err = tree.move(a, a)
+	assert.ErrorIs(t, err, ErrCreatesCycle)
+
 	err = tree.move(a, s)
 	assert.NoError(t, err)
 
@@ -35,3 +41,127 @@ func TestMoveTree_Move(t *testing.T) {
 	d := uuid.New()
 	tree.addEdge(d, c)
 }
+
+func TestStageTable_JitterIndices(t *testing.T) {
+	base := createTx(s1, insertOp(b1, "para", s1, PositionEnd), insertOp(b2, "para", s1, PositionEnd))
+	newClient := func() *Api {
+		api := NewApi(NewMemStore())
+		api.JitterIndices(true)
+		err := api.CreateSpace(s1, "test-1")
+		require.NoError(t, err)
+		_, err = api.Apply(base)
+		require.NoError(t, err)
+		return api
+	}
+
+	// two clients insert after b1 at the same time, each on its own copy of the space
+	clients := []*Api{newClient(), newClient()}
+	txs := []*Transaction{createTx(s1, insertOp(b3, "para", b1, PositionAfter)), createTx(s1, insertOp(b4, "para", b1, PositionAfter))}
+	indices := make([]*FracIndex, len(txs))
+	for i, tx := range txs {
+		_, err := clients[i].Apply(tx)
+		require.NoError(t, err)
+		block, err := clients[i].GetBlock(s1, tx.Ops[0].BlockID)
+		require.NoError(t, err)
+		indices[i] = block.Index
+	}
+
+	// the indices differ and are ordered by the transaction ids, both between b1 and b2
+	assert.False(t, indices[0].Equals(indices[1]))
+	assert.Equal(t, bytes.Compare(txs[0].ID[len(txs[0].ID)-jitterLength:], txs[1].ID[len(txs[1].ID)-jitterLength:]), indices[0].Compare(indices[1]))
+	first, err := clients[0].GetBlock(s1, b1)
+	require.NoError(t, err)
+	last, err := clients[0].GetBlock(s1, b2)
+	require.NoError(t, err)
+	for _, index := range indices {
+		assert.Less(t, first.Index.Compare(index), 0)
+		assert.Less(t, index.Compare(last.Index), 0)
+	}
+
+	// a replica that applies the transaction gets the same index
+	replica := newClient()
+	_, err = replica.Apply(txs[0])
+	require.NoError(t, err)
+	block, err := replica.GetBlock(s1, b3)
+	require.NoError(t, err)
+	assert.Equal(t, indices[0], block.Index)
+}
+
+func TestStageTable_JitterIndicesOrder(t *testing.T) {
+	api := NewApi(NewMemStore())
+	api.JitterIndices(true)
+	err := api.CreateSpace(s1, "test-1")
+	require.NoError(t, err)
+
+	random := rand.New(rand.NewSource(1))
+	positions := []PointerPosition{PositionStart, PositionEnd, PositionBefore, PositionAfter}
+	order := make([]uuid.UUID, 0)
+	for i := 0; i < 300; i++ {
+		id := uuid.New()
+		position := positions[random.Intn(len(positions))]
+		if len(order) == 0 {
+			position = PositionEnd
+		}
+
+		switch position {
+		case PositionStart:
+			_, err = api.Apply(createTx(s1, insertOp(id, "para", s1, position)))
+			order = append([]uuid.UUID{id}, order...)
+		case PositionEnd:
+			_, err = api.Apply(createTx(s1, insertOp(id, "para", s1, position)))
+			order = append(order, id)
+		case PositionBefore, PositionAfter:
+			at := random.Intn(len(order))
+			_, err = api.Apply(createTx(s1, insertOp(id, "para", order[at], position)))
+			if position == PositionAfter {
+				at++
+			}
+			order = append(order[:at], append([]uuid.UUID{id}, order[at:]...)...)
+		}
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, order, childIDs(t, api, s1, s1))
+}
+
+func TestStageTable_InsertRun(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	require.NoError(t, err)
+	_, err = api.Apply(createTx(s1, insertOp(b1, "para", s1, PositionEnd), insertOp(b2, "para", s1, PositionEnd)))
+	require.NoError(t, err)
+
+	// a paste of 500 blocks after b1, each after the one before it
+	ops := make([]Op, 0, 500)
+	order := []uuid.UUID{b1}
+	prev := b1
+	for i := 0; i < 500; i++ {
+		id := uuid.New()
+		ops = append(ops, insertOp(id, "para", prev, PositionAfter))
+		order = append(order, id)
+		prev = id
+	}
+	_, err = api.Apply(createTx(s1, ops...))
+	require.NoError(t, err)
+	assert.Equal(t, append(order, b2), childIDs(t, api, s1, s1))
+
+	children, err := api.GetChildrenBlocks(s1, s1)
+	require.NoError(t, err)
+	for _, child := range children {
+		assert.LessOrEqual(t, len(child.Index.Bytes()), 4)
+	}
+
+	// the runs at the end of a parent and before a block
+	_, err = api.Apply(createTx(s1,
+		insertOp(b3, "para", b2, PositionBefore),
+		insertOp(b4, "para", b3, PositionAfter),
+		insertOp(b5, "para", b1, PositionEnd),
+		insertOp(b6, "para", b1, PositionEnd),
+		insertOp(b7, "para", b5, PositionBefore),
+	))
+	require.NoError(t, err)
+	children, err = api.GetChildrenBlocks(s1, s1)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{order[500], b3, b4, b2}, blockIDs(children[len(children)-4:]))
+	assert.Equal(t, []uuid.UUID{b7, b5, b6}, childIDs(t, api, s1, b1))
+}
@@ -98,6 +98,9 @@ func newBlockInsertCmd() *cobra.Command {
 			res, err := client.Apply(context.Background(), &v1.TransactionsRequest{
 				Transactions: []*v1.Transaction{tx},
 			})
+			if err == nil {
+				err = transactionError(res)
+			}
 			if err != nil {
 				logrus.Infof("Failed to create a block: %v", err)
 				return
@@ -184,6 +187,9 @@ func newBlockMoveCmd() *cobra.Command {
 			res, err := client.Apply(context.Background(), &v1.TransactionsRequest{
 				Transactions: []*v1.Transaction{tx},
 			})
+			if err == nil {
+				err = transactionError(res)
+			}
 			if err != nil {
 				logrus.Infof("Failed to create a block: %v", err)
 				return
@@ -305,7 +311,9 @@ func newBlockDeleteCmd() *cobra.Command {
 			res, err := client.Apply(context.Background(), &v1.TransactionsRequest{
 				Transactions: []*v1.Transaction{tx},
 			})
-
+			if err == nil {
+				err = transactionError(res)
+			}
 			if err != nil {
 				logrus.Infof("Failed to get a block: %v", err)
 				return
@@ -383,7 +391,9 @@ func newBlockPatchCmd() *cobra.Command {
 			res, err := client.Apply(context.Background(), &v1.TransactionsRequest{
 				Transactions: []*v1.Transaction{&tx},
 			})
-
+			if err == nil {
+				err = transactionError(res)
+			}
 			if err != nil {
 				logrus.Infof("Failed to patch a block: %v", err)
 				return
@@ -448,7 +458,9 @@ func newBlockLinkCmd() *cobra.Command {
 			res, err := client.Apply(context.Background(), &v1.TransactionsRequest{
 				Transactions: []*v1.Transaction{&tx},
 			})
-
+			if err == nil {
+				err = transactionError(res)
+			}
 			if err != nil {
 				logrus.Infof("Failed to link a block: %v", err)
 				return
@@ -513,7 +525,9 @@ func newBlockUnlinkCmd() *cobra.Command {
 			res, err := client.Apply(context.Background(), &v1.TransactionsRequest{
 				Transactions: []*v1.Transaction{&tx},
 			})
-
+			if err == nil {
+				err = transactionError(res)
+			}
 			if err != nil {
 				logrus.Infof("Failed to link a block: %v", err)
 				return
@@ -529,3 +543,14 @@ func newBlockUnlinkCmd() *cobra.Command {
 
 	return linkCmd
 }
+
+// transactionError returns the error of the first failed transaction in the response
+func transactionError(res *v1.TransactionsResponse) error {
+	for _, result := range res.GetTransactions() {
+		if !result.GetSuccess() {
+			return fmt.Errorf("transaction %s failed with %s: %s", result.GetTransactionId(), result.GetCode(), result.GetMessage())
+		}
+	}
+
+	return nil
+}
@@ -49,7 +49,7 @@ func newPageGetCmd() *cobra.Command {
 			defer conn.Close()
 			client := v1.NewBlocktreeClient(conn)
 
-			req := &v1.GetBlockDescendantsRequest{
+			req := &v1.GetBlockPageRequest{
 				BlockId: pageID,
 			}
 			if spaceID != "" {
@@ -58,7 +58,7 @@ func newPageGetCmd() *cobra.Command {
 
 			logrus.Infof("Getting page %v", req)
 
-			getPage, err := client.GetDescendants(context.Background(), req)
+			getPage, err := client.GetPage(context.Background(), req)
 			if err != nil {
 				logrus.Fatal(err)
 				return
@@ -78,7 +78,7 @@ func newPageSubPagesCmd() *cobra.Command {
 	var pageID, spaceID string
 	var getCmd = &cobra.Command{
 		Use:   "subpages",
-		Short: "Get a page",
+		Short: "List the sub-pages of a page",
 		Run: func(cmd *cobra.Command, args []string) {
 			if spaceID == "" {
 				panic("space ID is required")
@@ -97,15 +97,15 @@ func newPageSubPagesCmd() *cobra.Command {
 			defer conn.Close()
 			client := v1.NewBlocktreeClient(conn)
 
-			logrus.Info("Getting a page")
-			req := &v1.GetBlockChildrenRequest{
+			logrus.Info("Getting the sub-pages")
+			req := &v1.GetBlockPageRequest{
 				BlockId: pageID,
 			}
 			if spaceID != "" {
 				req.SpaceId = &spaceID
 			}
 
-			getPage, err := client.GetChildren(context.Background(), req)
+			getPage, err := client.GetPage(context.Background(), req)
 			if err != nil {
 				logrus.Fatal(err)
 				return
@@ -113,8 +113,8 @@ func newPageSubPagesCmd() *cobra.Command {
 
 			tree := treeprint.New()
 			tree.AddNode(pageID)
-			branch := tree.AddBranch("children")
-			for _, block := range getPage.Blocks {
+			branch := tree.AddBranch("subpages")
+			for _, block := range getPage.SubPages {
 				branch.AddNode(block.BlockId)
 			}
 
@@ -0,0 +1,14 @@
+//go:build !postgres
+
+package cmd
+
+import (
+	"errors"
+
+	"github.com/emrgen/blocktree"
+)
+
+// openPostgresStore fails, the postgres store is built with the postgres build tag only
+func openPostgresStore(string) (blocktree.Store, error) {
+	return nil, errors.New("postgres store is not built in, build with -tags postgres")
+}
@@ -0,0 +1,17 @@
+//go:build postgres
+
+package cmd
+
+import (
+	"github.com/emrgen/blocktree"
+)
+
+// openPostgresStore opens the postgres store for the database url
+func openPostgresStore(dbURL string) (blocktree.Store, error) {
+	config, err := blocktree.DbConfigFromURL(dbURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return blocktree.NewPostgresStore(config)
+}
@@ -0,0 +1,163 @@
+package blocktree
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// BlockQuery limits the blocks returned by the children and descendant queries of the stores.
+type BlockQuery struct {
+	// MaxDepth is the depth of the deepest descendants returned, the children are at depth 1 and 0 is unlimited
+	MaxDepth int
+	// Limit is the max number of blocks in the page, 0 returns all the blocks
+	Limit int
+	// Start is the cursor of the first block of the page, the Next cursor of the previous page
+	Start BlockCursor
+}
+
+// BlockPage is a page of blocks in the order of the query
+type BlockPage struct {
+	Blocks []*Block
+	// Next is the cursor of the first block of the next page, empty on the last page
+	Next BlockCursor
+}
+
+// BlockCursor is the position of a block in a children or descendant query, the path from the query root to the block.
+// each level adds the key of the block index and the block id, the cursors compare in the depth first order of the tree
+// with the children ordered by index and id. the query root has the empty cursor.
+type BlockCursor string
+
+// ParseBlockCursor decodes the page token of a query into a cursor, the empty token starts from the first block
+func ParseBlockCursor(token string) (BlockCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", err
+	}
+
+	cursor := BlockCursor(data)
+	if err := cursor.validate(); err != nil {
+		return "", err
+	}
+
+	return cursor, nil
+}
+
+// PageToken encodes the cursor as an opaque page token
+func (c BlockCursor) PageToken() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c))
+}
+
+// child returns the cursor of the child block below the block at the cursor
+func (c BlockCursor) child(block *Block) BlockCursor {
+	return c + BlockCursor(block.Index.Key()+"."+block.ID.String()+"/")
+}
+
+// reaches reports whether the block at the cursor or its descendants come from the start cursor on.
+// the blocks before the start are skipped together with their descendants.
+func (c BlockCursor) reaches(start BlockCursor) bool {
+	return c >= start || strings.HasPrefix(string(start), string(c))
+}
+
+// pivot returns the child the start cursor goes through below the cursor, nil when the start is not below it.
+// the pivot has the index and the id of the child, the children before it can be skipped.
+func (c BlockCursor) pivot(start BlockCursor) *Block {
+	if len(start) <= len(c) || !strings.HasPrefix(string(start), string(c)) {
+		return nil
+	}
+
+	segment, _, _ := strings.Cut(string(start[len(c):]), "/")
+	block, err := parseCursorSegment(segment)
+	if err != nil {
+		return nil
+	}
+
+	return block
+}
+
+// validate checks that the cursor is a path of index and id pairs
+func (c BlockCursor) validate() error {
+	if c == "" {
+		return nil
+	}
+	if !strings.HasSuffix(string(c), "/") {
+		return fmt.Errorf("unterminated cursor %q", string(c))
+	}
+
+	for _, segment := range strings.Split(string(c[:len(c)-1]), "/") {
+		if _, err := parseCursorSegment(segment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseCursorSegment parses a level of the cursor into a block with the index and the id
+func parseCursorSegment(segment string) (*Block, error) {
+	index, id, ok := strings.Cut(segment, ".")
+	if !ok {
+		return nil, fmt.Errorf("bad cursor segment %q", segment)
+	}
+
+	fracIndex, err := FracIndexFromKey(index)
+	if err != nil {
+		return nil, fmt.Errorf("bad cursor index %q", index)
+	}
+	blockID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("bad cursor id %q", id)
+	}
+
+	return &Block{ID: blockID, Index: fracIndex}, nil
+}
+
+// blockPager collects the blocks of a query in order until the page is full
+type blockPager struct {
+	query *BlockQuery
+	page  *BlockPage
+}
+
+// newBlockPager checks the start cursor of the query, a nil query returns all the blocks
+func newBlockPager(query *BlockQuery) (*blockPager, error) {
+	if query == nil {
+		query = &BlockQuery{}
+	}
+	if err := query.Start.validate(); err != nil {
+		return nil, err
+	}
+
+	return &blockPager{
+		query: query,
+		page:  &BlockPage{Blocks: make([]*Block, 0)},
+	}, nil
+}
+
+// add appends the block at the cursor to the page, once the page is full the cursor becomes the next cursor
+// and add returns false.
+func (p *blockPager) add(block *Block, cursor BlockCursor) bool {
+	if p.query.Limit > 0 && len(p.page.Blocks) == p.query.Limit {
+		p.page.Next = cursor
+		return false
+	}
+
+	p.page.Blocks = append(p.page.Blocks, block)
+	return true
+}
+
+// expands reports whether the children of a block at the depth are in the query
+func (p *blockPager) expands(depth int) bool {
+	return p.query.MaxDepth <= 0 || depth < p.query.MaxDepth
+}
+
+// fetch returns the number of rows a store query needs for the page, the extra row gives the next cursor.
+// 0 is no limit.
+func (p *blockPager) fetch() int {
+	if p.query.Limit <= 0 {
+		return 0
+	}
+
+	return p.query.Limit + 1
+}
@@ -0,0 +1,88 @@
+package blocktree
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/emrgen/blocktree/apis/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBlockCursor(t *testing.T) {
+	first := &Block{ID: b1, Index: FracIndexFromBytes([]byte{0x80})}
+	second := &Block{ID: b2, Index: FracIndexFromBytes([]byte{0x80, 0x01, 0x80})}
+	root := BlockCursor("")
+
+	// the cursors compare in the depth first order of the tree
+	assert.Less(t, root, root.child(first))
+	assert.Less(t, root.child(first), root.child(first).child(second))
+	assert.Less(t, root.child(first).child(second), root.child(second))
+	// the key of an index that extends another index comes after the separator
+	extended := &Block{ID: b1, Index: FracIndexFromBytes([]byte{0x80, 0x00, 0x80})}
+	assert.Less(t, root.child(first), root.child(extended))
+
+	cursor := root.child(first).child(second)
+	assert.True(t, root.child(first).reaches(cursor))
+	assert.False(t, root.child(&Block{ID: b3, Index: FracIndexFromBytes([]byte{0x70, 0x80})}).reaches(cursor))
+	assert.Equal(t, b2, root.child(first).pivot(cursor).ID)
+	assert.Nil(t, root.child(second).pivot(cursor))
+
+	parsed, err := ParseBlockCursor(cursor.PageToken())
+	require.NoError(t, err)
+	assert.Equal(t, cursor, parsed)
+
+	parsed, err = ParseBlockCursor("")
+	require.NoError(t, err)
+	assert.Equal(t, root, parsed)
+
+	_, err = ParseBlockCursor(BlockCursor("80." + b1.String()).PageToken())
+	assert.Error(t, err)
+	_, err = ParseBlockCursor("%%")
+	assert.Error(t, err)
+}
+
+func TestGrpcApi_GetDescendantsPages(t *testing.T) {
+	server := newGrpcApi(createPages(t))
+
+	res, err := server.GetDescendants(context.Background(), &v1.GetBlockDescendantsRequest{BlockId: b1.String(), Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{b1.String(), b2.String()}, protoBlockIDs(res.Blocks))
+	require.NotNil(t, res.Block)
+	assert.Len(t, res.Block.Children, 1)
+	require.NotEmpty(t, res.NextPageToken)
+
+	res, err = server.GetDescendants(context.Background(), &v1.GetBlockDescendantsRequest{BlockId: b1.String(), Limit: 2, PageToken: res.NextPageToken})
+	require.NoError(t, err)
+	assert.Equal(t, []string{b3.String(), b5.String()}, protoBlockIDs(res.Blocks))
+	assert.Nil(t, res.Block, "the later pages have no root")
+	assert.Empty(t, res.NextPageToken)
+
+	res, err = server.GetDescendants(context.Background(), &v1.GetBlockDescendantsRequest{BlockId: b1.String(), MaxDepth: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{b1.String(), b2.String(), b5.String()}, protoBlockIDs(res.Blocks))
+
+	children, err := server.GetChildren(context.Background(), &v1.GetBlockChildrenRequest{BlockId: b1.String(), Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{b2.String()}, protoBlockIDs(children.Blocks))
+	next := children.Blocks[0]
+	children, err = server.GetChildren(context.Background(), &v1.GetBlockChildrenRequest{BlockId: b1.String(), PageToken: children.NextPageToken})
+	require.NoError(t, err)
+	assert.Equal(t, []string{b5.String()}, protoBlockIDs(children.Blocks))
+	assert.Empty(t, children.NextPageToken)
+	// the clients order the siblings by the index keys
+	assert.Less(t, next.GetIndex(), children.Blocks[0].GetIndex())
+
+	_, err = server.GetChildren(context.Background(), &v1.GetBlockChildrenRequest{BlockId: b1.String(), PageToken: "bad token"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func protoBlockIDs(blocks []*v1.Block) []string {
+	ids := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		ids = append(ids, block.BlockId)
+	}
+	return ids
+}
@@ -0,0 +1,87 @@
+package blocktree
+
+import (
+	"fmt"
+)
+
+var (
+	ErrCreatesCycle    = fmt.Errorf("operation creates cycle")
+	ErrDetectedCycle   = fmt.Errorf("existing cycle detected")
+	ErrFailedToPublish = fmt.Errorf("failed to publish sync blocks")
+	// ErrSubscribeNotSupported is returned when the api has no subscriber for the changes
+	ErrSubscribeNotSupported = fmt.Errorf("subscribe is not supported")
+	// ErrNotFound is returned when a space, block or transaction does not exist,
+	// errors.As gives the ErrSpaceNotFound, ErrBlockNotFound or ErrTransactionNotFound with the id.
+	ErrNotFound = fmt.Errorf("not found")
+	// ErrInvalidPosition is returned when an op puts a block at a position it cannot take
+	ErrInvalidPosition = fmt.Errorf("invalid position")
+	// ErrInvalidOp is returned when an op misses a field or has fields that do not go together
+	ErrInvalidOp = fmt.Errorf("invalid op")
+	// ErrDuplicate is returned when a transaction inserts a block or a space is created that already exists
+	ErrDuplicate = fmt.Errorf("block already exists")
+)
+
+// ErrSpaceNotFound is returned when the space does not exist
+type ErrSpaceNotFound struct {
+	ID SpaceID
+}
+
+func (e ErrSpaceNotFound) Error() string {
+	return fmt.Sprintf("space %v not found", e.ID)
+}
+
+func (e ErrSpaceNotFound) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ErrBlockNotFound is returned when the block does not exist in the space
+type ErrBlockNotFound struct {
+	ID BlockID
+}
+
+func (e ErrBlockNotFound) Error() string {
+	return fmt.Sprintf("block %v not found", e.ID)
+}
+
+func (e ErrBlockNotFound) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ErrTransactionNotFound is returned when the transaction is not in the space log
+type ErrTransactionNotFound struct {
+	ID TransactionID
+}
+
+func (e ErrTransactionNotFound) Error() string {
+	return fmt.Sprintf("transaction %v not found", e.ID)
+}
+
+func (e ErrTransactionNotFound) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ErrBlockExists is returned when a transaction inserts a block that already exists
+type ErrBlockExists struct {
+	ID BlockID
+}
+
+func (e ErrBlockExists) Error() string {
+	return fmt.Sprintf("block %v already exists", e.ID)
+}
+
+func (e ErrBlockExists) Is(target error) bool {
+	return target == ErrDuplicate
+}
+
+// ErrSpaceExists is returned when a space is created with the id of an existing space
+type ErrSpaceExists struct {
+	ID SpaceID
+}
+
+func (e ErrSpaceExists) Error() string {
+	return fmt.Sprintf("space %v already exists", e.ID)
+}
+
+func (e ErrSpaceExists) Is(target error) bool {
+	return target == ErrDuplicate
+}
@@ -2,7 +2,9 @@ package blocktree
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
+	"math/big"
 )
 
 // ref: https://github.com/drifting-in-space/fractional_index/blob/main/src/fract_index.rs
@@ -25,6 +27,19 @@ func FracIndexFromBytes(bytes []byte) *FracIndex {
 	return &FracIndex{bytes: bytes}
 }
 
+// FracIndexFromKey decodes the index from its key, see FracIndex.Key
+func FracIndexFromKey(key string) (*FracIndex, error) {
+	data, err := indexKeyEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("bad index key %q: %w", key, err)
+	}
+	if !validIndex(data) {
+		return nil, fmt.Errorf("bad index key %q: unterminated index", key)
+	}
+
+	return FracIndexFromBytes(data), nil
+}
+
 func fromUnterminated(bytes []byte) *FracIndex {
 	return &FracIndex{bytes: append(bytes, terminator)}
 }
@@ -42,17 +57,19 @@ func NewBetween(left, right *FracIndex) (*FracIndex, error) {
 	shorterLen -= 1 // don't count the last byte, which may be the terminator
 
 	for i := 0; i < shorterLen; i++ {
-		if left.bytes[i] < right.bytes[i]-1 {
+		// compare as ints, a zero byte on the right must not wrap around
+		if int(left.bytes[i]) < int(right.bytes[i])-1 {
 			buf := make([]byte, i+1)
 			copy(buf, left.bytes)
-			buf[i] = (left.bytes[i] + right.bytes[i]) / 2
+			// the midpoint without overflowing the byte
+			buf[i] = left.bytes[i] + (right.bytes[i]-left.bytes[i])/2
 			return fromUnterminated(buf), nil
 		}
 
-		if left.bytes[i] == right.bytes[i]-1 {
-			buf := make([]byte, len(left.bytes))
-			copy(buf, left.bytes[:i+1])
-			copy(buf[i+1:], newAfter(left.bytes[i+1:]))
+		if int(left.bytes[i]) == int(right.bytes[i])-1 {
+			// the suffix after the left bytes can be shorter than the left bytes
+			buf := bytes.Clone(left.bytes[:i+1])
+			buf = append(buf, newAfter(left.bytes[i+1:])...)
 			return fromUnterminated(buf), nil
 		}
 
@@ -95,6 +112,99 @@ func NewAfter(prev *FracIndex) *FracIndex {
 	return fromUnterminated(newAfter(prev.bytes))
 }
 
+// NewSpread returns n indices in order, spread evenly over the index space.
+// the keys of n children take about log256(n) bytes whatever the order the children were inserted in.
+func NewSpread(n int) []*FracIndex {
+	// the open bounds are never out of order
+	indices, _ := NewNBetween(nil, nil, n)
+	return indices
+}
+
+// NewNBetween returns n indices in order between the left and the right index, spread evenly between them.
+// a nil left index is the start of the index space and a nil right index is the end of it.
+// the indices share the shortest length that leaves a few free indices between and around them,
+// n blocks inserted one after another get keys of about log256(n) bytes more than the bounds.
+func NewNBetween(left, right *FracIndex, n int) ([]*FracIndex, error) {
+	indices := make([]*FracIndex, 0, max(n, 0))
+	if n <= 0 {
+		return indices, nil
+	}
+	if left != nil && right != nil && left.Compare(right) >= 0 {
+		return nil, fmt.Errorf("left index %v is not less than right index %v", left, right)
+	}
+
+	// the indices keep the bytes the bounds share, the k bytes after them are a number
+	// between the bounds with a gap of at least spreadGap numbers between the indices
+	prefix := commonPrefix(left, right)
+	slots := big.NewInt(int64(n) + 1)
+	for k := 1; ; k++ {
+		low := suffixValue(left, len(prefix), k)
+		high := new(big.Int).Lsh(big.NewInt(1), uint(8*k))
+		if right != nil {
+			high = suffixValue(right, len(prefix), k)
+		}
+
+		span := new(big.Int).Sub(high, low)
+		if new(big.Int).Quo(span, slots).Cmp(big.NewInt(spreadGap)) < 0 {
+			continue
+		}
+
+		for i := int64(1); i <= int64(n); i++ {
+			value := new(big.Int).Mul(span, big.NewInt(i))
+			value.Quo(value, slots).Add(value, low)
+
+			buf := make([]byte, len(prefix)+k)
+			copy(buf, prefix)
+			value.FillBytes(buf[len(prefix):])
+			indices = append(indices, fromUnterminated(buf))
+		}
+
+		return indices, nil
+	}
+}
+
+// commonPrefix returns the bytes the indices start with, none when an index is nil
+func commonPrefix(left, right *FracIndex) []byte {
+	if left == nil || right == nil {
+		return nil
+	}
+
+	i := 0
+	for i < len(left.bytes) && i < len(right.bytes) && left.bytes[i] == right.bytes[i] {
+		i++
+	}
+
+	return left.bytes[:i]
+}
+
+// suffixValue returns the k bytes of the index after the offset as a number, the missing bytes are zeros.
+// a nil index is zero.
+func suffixValue(index *FracIndex, offset, k int) *big.Int {
+	buf := make([]byte, k)
+	if index != nil && offset < len(index.bytes) {
+		copy(buf, index.bytes[offset:])
+	}
+
+	return new(big.Int).SetBytes(buf)
+}
+
+// withSuffix returns the index extended with the suffix, the extended index comes right after the index
+// and before the other indices after it that do not extend it.
+func (f *FracIndex) withSuffix(suffix []byte) *FracIndex {
+	buf := make([]byte, 0, len(f.bytes)+len(suffix)+1)
+	buf = append(buf, f.bytes...)
+	buf = append(buf, suffix...)
+	return fromUnterminated(buf)
+}
+
+// spreadGap is the least distance between the prefixes of the spread indices
+const spreadGap = 4
+
+// validIndex reports whether the bytes are a terminated index
+func validIndex(index []byte) bool {
+	return len(index) > 0 && index[len(index)-1] == terminator
+}
+
 func (f *FracIndex) Bytes() []byte {
 	return f.bytes
 }
@@ -128,6 +238,19 @@ func (f *FracIndex) Clone() *FracIndex {
 	return FracIndexFromBytes(bytes.Clone(f.bytes))
 }
 
+// Key encodes the index as a string that compares like the index, the clients and the sql stores order
+// the blocks by the keys. the key is the index bytes in base64 with the digits in ascii order.
+func (f *FracIndex) Key() string {
+	return indexKeyEncoding.EncodeToString(f.bytes)
+}
+
+// indexKeyAlphabet is the base64 alphabet in ascii order, all the digits come after '.' and '/'
+// so the keys in the block cursors compare like the indices too
+const indexKeyAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ_abcdefghijklmnopqrstuvwxyz~"
+
+// indexKeyEncoding pads the last digit with zero bits, a shorter index gets a shorter key
+var indexKeyEncoding = base64.NewEncoding(indexKeyAlphabet).WithPadding(base64.NoPadding)
+
 func (f *FracIndex) String() string {
 	nums := make([]uint8, len(f.bytes))
 	copy(nums, f.bytes)
@@ -1,6 +1,8 @@
 package blocktree
 
 import (
+	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -125,6 +127,31 @@ func TestNewBetweenSimple(t *testing.T) {
 	assert.Equal(t, mid.bytes, []uint8{109, 128})
 }
 
+func TestNewBetweenHighBytes(t *testing.T) {
+	left := fromUnterminated([]uint8{129})
+	right := fromUnterminated([]uint8{131})
+	mid, err := NewBetween(left, right)
+	assert.NoError(t, err)
+	assert.Equal(t, mid.bytes, []uint8{130, 128})
+}
+
+func TestNewBetweenShortSuffix(t *testing.T) {
+	left := fromUnterminated([]uint8{129, 130})
+	right := fromUnterminated([]uint8{130})
+	mid, err := NewBetween(left, right)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint8{129, 131, 128}, mid.bytes)
+}
+
+func TestNewBetweenZeroBytes(t *testing.T) {
+	left := fromUnterminated([]uint8{0, 0})
+	right := fromUnterminated([]uint8{0})
+	mid, err := NewBetween(left, right)
+	assert.NoError(t, err)
+	assert.Equal(t, -1, left.Compare(mid))
+	assert.Equal(t, -1, mid.Compare(right))
+}
+
 func TestNewBetweenError(t *testing.T) {
 	a := DefaultFracIndex()
 	b := NewAfter(a)
@@ -185,3 +212,77 @@ func TestNewBetweenPrefix(t *testing.T) {
 func TestFractionalIndex(t *testing.T) {
 	// not implemented
 }
+
+func TestNewSpread(t *testing.T) {
+	assert.Empty(t, NewSpread(0))
+
+	indices := NewSpread(3)
+	assert.Equal(t, []uint8{64, 128}, indices[0].bytes)
+	assert.Equal(t, []uint8{128, 128}, indices[1].bytes)
+	assert.Equal(t, []uint8{192, 128}, indices[2].bytes)
+
+	indices = NewSpread(1000)
+	for i := 1; i < len(indices); i++ {
+		assert.Less(t, indices[i-1].Compare(indices[i]), 0)
+		assert.Len(t, indices[i].bytes, 3)
+	}
+}
+
+func TestNewNBetween(t *testing.T) {
+	left := fromUnterminated([]uint8{10})
+	right := fromUnterminated([]uint8{11})
+	indices, err := NewNBetween(left, right, 500)
+	assert.NoError(t, err)
+	assert.Len(t, indices, 500)
+	prev := left
+	for _, index := range indices {
+		assert.Less(t, prev.Compare(index), 0)
+		assert.LessOrEqual(t, len(index.bytes), 4)
+		prev = index
+	}
+	assert.Less(t, prev.Compare(right), 0)
+
+	// the left index is a prefix of the right index
+	left = fromUnterminated([]uint8{})
+	right = fromUnterminated([]uint8{128, 2})
+	indices, err = NewNBetween(left, right, 3)
+	assert.NoError(t, err)
+	for _, index := range indices {
+		assert.Less(t, left.Compare(index), 0)
+		assert.Less(t, index.Compare(right), 0)
+	}
+
+	indices, err = NewNBetween(nil, fromUnterminated([]uint8{1}), 2)
+	assert.NoError(t, err)
+	assert.Less(t, indices[0].Compare(indices[1]), 0)
+	assert.Less(t, indices[1].Compare(fromUnterminated([]uint8{1})), 0)
+
+	_, err = NewNBetween(right, left, 1)
+	assert.Error(t, err)
+}
+
+func TestFracIndexKey(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	indices := []*FracIndex{DefaultFracIndex(), fromUnterminated([]uint8{}), fromUnterminated([]uint8{128, 0}), fromUnterminated([]uint8{0})}
+	for i := 0; i < 200; i++ {
+		buf := make([]uint8, random.Intn(6))
+		random.Read(buf)
+		indices = append(indices, fromUnterminated(buf))
+	}
+
+	for _, left := range indices {
+		key := left.Key()
+		index, err := FracIndexFromKey(key)
+		assert.NoError(t, err)
+		assert.Equal(t, left.bytes, index.bytes)
+
+		for _, right := range indices {
+			assert.Equal(t, left.Compare(right), strings.Compare(key, right.Key()), "%v %v", left, right)
+		}
+	}
+
+	_, err := FracIndexFromKey("")
+	assert.Error(t, err)
+	_, err = FracIndexFromKey("+/")
+	assert.Error(t, err)
+}
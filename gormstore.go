@@ -1,10 +1,15 @@
 package blocktree
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 var (
@@ -21,147 +26,769 @@ func NewGormStore(db *gorm.DB) *GormStore {
 	return &GormStore{db: db}
 }
 
+// Migrate creates or updates the tables used by the store.
+// the indices the older versions stored as bytes are rewritten as index keys first.
+func (g GormStore) Migrate() error {
+	err := g.migrateIndexKeys()
+	if err != nil {
+		return err
+	}
+
+	return g.db.AutoMigrate(
+		&gormSpace{},
+		&gormBlock{},
+		&gormBackLink{},
+		&gormTransaction{},
+		&gormSnapshot{},
+	)
+}
+
+// gormByteIndex is a block index stored as bytes, the form of frac_index before the index keys
+type gormByteIndex struct {
+	ID    uuid.UUID
+	Index []byte `gorm:"column:frac_index"`
+}
+
+// isKey reports whether the stored index is already an index key.
+// the index bytes end with the terminator, which is not a key digit, so the bytes are never taken for a key.
+func (i gormByteIndex) isKey() bool {
+	_, err := FracIndexFromKey(string(i.Index))
+	return err == nil
+}
+
+// migrateIndexKeys rewrites the frac_index column of the blocks from the index bytes to the index keys,
+// see FracIndex.Key. the older versions stored the bytes in a text column, a bytea or blob column is converted to text.
+// the column is left as it is once it holds the keys.
+func (g GormStore) migrateIndexKeys() error {
+	migrator := g.db.Migrator()
+	if !migrator.HasTable(&gormBlock{}) {
+		return nil
+	}
+
+	columns, err := migrator.ColumnTypes(&gormBlock{})
+	if err != nil {
+		return err
+	}
+	found, stored := false, false
+	for _, column := range columns {
+		if column.Name() == "frac_index" {
+			name := strings.ToLower(column.DatabaseTypeName())
+			found = true
+			stored = name == "bytea" || name == "blob"
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	// a text column is rewritten as a whole, checking one block is enough to tell the keys from the bytes
+	if !stored {
+		var probe gormByteIndex
+		res := g.db.Table("blocks").Select("id, frac_index").Limit(1).Find(&probe)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 || probe.isKey() {
+			return nil
+		}
+	}
+
+	return g.db.Transaction(func(db *gorm.DB) error {
+		var indices []gormByteIndex
+		err := db.Table("blocks").Select("id, frac_index").Find(&indices).Error
+		if err != nil {
+			return err
+		}
+
+		if stored {
+			// the column is converted to text first, the converted values are replaced with the keys
+			err = db.Migrator().AlterColumn(&gormBlock{}, "Index")
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, index := range indices {
+			if index.isKey() {
+				continue
+			}
+			err := db.Table("blocks").
+				Where("id = ?", index.ID).
+				Update("frac_index", FracIndexFromBytes(index.Index).Key()).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
 func (g GormStore) GetLatestTransaction(spaceID *SpaceID) (*Transaction, error) {
-	//TODO implement me
-	panic("implement me")
+	var model gormTransaction
+	res := g.db.Where("space_id = ?", spaceID).Order("seq DESC").Limit(1).Find(&model)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrSpaceNotFound{ID: *spaceID}
+	}
+
+	return model.toTransaction()
+}
+
+// Apply applies transactional changes to the store.
+// all the changes are committed in a single database transaction.
+func (g GormStore) Apply(tx *Transaction, change *storeChange) (*storeChange, error) {
+	if change == nil {
+		return nil, errors.New("cannot apply nil change to store")
+	}
+
+	if change.jsonDocChange != nil {
+		return nil, errors.New("json doc changes are not supported by gorm store")
+	}
+
+	err := g.db.Transaction(func(db *gorm.DB) error {
+		return GormStore{db: db}.apply(tx, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// Atomic runs fn with a store bound to a single database transaction, the writes of fn are committed together.
+func (g GormStore) Atomic(spaceID SpaceID, fn func(store Store) error) error {
+	return g.db.Transaction(func(db *gorm.DB) error {
+		return fn(GormStore{db: db})
+	})
 }
 
-func (g GormStore) Apply(tx *Transaction, change *storeChange) error {
-	//TODO implement me
-	panic("implement me")
+func (g GormStore) apply(tx *Transaction, change *storeChange) error {
+	spaceID := &tx.SpaceID
+	if change.blockChange == nil {
+		return nil
+	}
+
+	// the transaction is already applied
+	if _, err := g.GetTransaction(spaceID, tx.ID); err == nil {
+		return nil
+	}
+
+	blockChange := change.blockChange
+	for _, block := range blockChange.inserted.ToSlice() {
+		err := g.CreateBlock(spaceID, block)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, block := range blockChange.updated.ToSlice() {
+		res := g.db.Model(&gormBlock{}).
+			Where("space_id = ? AND id = ?", spaceID, block.ID).
+			Updates(map[string]interface{}{
+				"parent_id":  block.ParentID,
+				"frac_index": block.Index.Key(),
+				"deleted":    block.Deleted,
+				"erased":     block.Erased,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("move block: %w", ErrBlockNotFound{ID: block.ID})
+		}
+	}
+
+	for _, block := range blockChange.propSet.ToSlice() {
+		res := g.db.Model(&gormBlock{}).
+			Where("space_id = ? AND id = ?", spaceID, block.ID).
+			Update("props", jsonDocBytes(block.Props))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("prop update block: %w", ErrBlockNotFound{ID: block.ID})
+		}
+	}
+
+	// patched blocks should already exist in the store
+	for _, block := range blockChange.patched.ToSlice() {
+		res := g.db.Model(&gormBlock{}).
+			Where("space_id = ? AND id = ?", spaceID, block.ID).
+			Update("json", jsonDocBytes(block.Json))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("patch block: %w", ErrBlockNotFound{ID: block.ID})
+		}
+	}
+
+	//update the backlinks
+	for _, change := range blockChange.linkOps {
+		link := &gormBackLink{
+			SpaceID:  *spaceID,
+			BlockID:  change.childID,
+			LinkedID: change.parentID,
+		}
+		switch change.op {
+		case OpTypeUnlink:
+			err := g.db.Delete(link).Error
+			if err != nil {
+				return err
+			}
+		case OpTypeLink:
+			err := g.db.Save(link).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if tx.unlogged {
+		return nil
+	}
+
+	return g.PutTransaction(spaceID, &Transaction{
+		ID:      tx.ID,
+		SpaceID: tx.SpaceID,
+		UserID:  tx.UserID,
+		Time:    tx.Time,
+		Clock:   tx.Clock,
+		HLC:     tx.HLC,
+		Jitter:  tx.Jitter,
+		Ops:     tx.Ops,
+		changes: change.intoSyncBlocks(),
+		inverse: change.blockChange.Inverse(),
+	})
 }
 
+// CreateSpace creates the space, its space block and the initial transaction in one database transaction.
 func (g GormStore) CreateSpace(space *Space) error {
-	model := space.toGormSpace()
-	return g.db.Create(model).Error
+	return g.db.Transaction(func(db *gorm.DB) error {
+		var count int64
+		err := db.Model(&gormSpace{}).Where("id = ?", space.ID).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count != 0 {
+			return ErrSpaceExists{ID: space.ID}
+		}
+
+		err = db.Create(space.toGormSpace()).Error
+		if err != nil {
+			return err
+		}
+
+		spaceBlock := NewBlock(space.ID, RootBlockID, "space")
+		spaceBlock.Props = NewJsonDoc([]byte(`{"name": "` + space.Name + `"}`))
+		err = GormStore{db: db}.CreateBlock(&space.ID, spaceBlock)
+		if err != nil {
+			return err
+		}
+
+		// the initial transaction is the starting point for GetNextTransactions
+		timestamp, _ := time.Parse(time.RFC3339, "2000-01-01T00:00:00Z")
+		genesis := &gormTransaction{
+			ID:      uuid.Nil,
+			SpaceID: space.ID,
+			UserID:  uuid.Nil,
+			Time:    timestamp,
+			Seq:     0,
+		}
+
+		return db.Create(genesis).Error
+	})
 }
 
 func (g GormStore) GetBlockSpaceID(id *BlockID) (*SpaceID, error) {
-	//TODO implement me
-	panic("implement me")
+	var model gormBlock
+	res := g.db.Select("space_id").Where("id = ?", id).Limit(1).Find(&model)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrBlockNotFound{ID: *id}
+	}
+
+	return &model.SpaceID, nil
 }
 
 func (g GormStore) CreateBlock(spaceID *SpaceID, block *Block) error {
-	model := block.toGormBlock()
+	model := block.toGormBlock(*spaceID)
 	return g.db.Create(model).Error
 }
 
 func (g GormStore) GetBlock(spaceID *SpaceID, id BlockID) (*Block, error) {
 	var model gormBlock
-	res := g.db.First(&model, "id = ?", id)
+	res := g.db.Where("space_id = ? AND id = ?", spaceID, id).Limit(1).Find(&model)
 	if res.Error != nil {
 		return nil, res.Error
 	}
+	if res.RowsAffected == 0 {
+		return nil, ErrBlockNotFound{ID: id}
+	}
 
 	return model.toBlock()
 }
 
 func (g GormStore) GetChildrenBlocks(spaceID *SpaceID, id BlockID) ([]*Block, error) {
-	var blocks []*gormBlock
-	res := g.db.Where("parent_id = ?", id).Find(&blocks)
-	if res.Error != nil {
-		return nil, res.Error
+	return g.findBlocks(g.children(spaceID, id).Where("linked = ?", false))
+}
+
+// QueryChildrenBlocks loads the children from the start cursor on, one more than the limit gives the next cursor.
+func (g GormStore) QueryChildrenBlocks(spaceID *SpaceID, id BlockID, query *BlockQuery) (*BlockPage, error) {
+	pager, err := newBlockPager(query)
+	if err != nil {
+		return nil, err
 	}
 
-	ret := make([]*Block, len(blocks))
-	for i, b := range blocks {
-		e, err := b.toBlock()
-		if err != nil {
-			return nil, err
+	children := g.children(spaceID, id).Where("linked = ?", false)
+	if pivot := BlockCursor("").pivot(pager.query.Start); pivot != nil {
+		children = children.Where("frac_index > ? OR (frac_index = ? AND id >= ?)", pivot.Index.Key(), pivot.Index.Key(), pivot.ID)
+	}
+	if fetch := pager.fetch(); fetch > 0 {
+		children = children.Limit(fetch)
+	}
+
+	blocks, err := g.findBlocks(children)
+	if err != nil {
+		return nil, err
+	}
+	for _, block := range blocks {
+		if !pager.add(block, BlockCursor("").child(block)) {
+			break
 		}
-		ret[i] = e
 	}
 
-	return ret, nil
+	return pager.page, nil
 }
 
 func (g GormStore) GetChildrenBlockIDs(spaceID *SpaceID, id BlockID) ([]BlockID, error) {
 	ids := make([]BlockID, 0)
+	res := g.children(spaceID, id).Model(&gormBlock{}).Pluck("id", &ids)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
 	return ids, nil
 }
 
 func (g GormStore) GetLinkedBlocks(spaceID *SpaceID, id BlockID) ([]*Block, error) {
-	//TODO implement me
-	panic("implement me")
+	return g.findBlocks(g.children(spaceID, id).Where("linked = ?", true))
 }
 
 func (g GormStore) GetBackLinks(spaceID *SpaceID, id BlockID) ([]*Block, error) {
-	//TODO implement me
-	panic("implement me")
+	linked := g.db.Model(&gormBackLink{}).
+		Select("linked_id").
+		Where("space_id = ? AND block_id = ?", spaceID, id)
+
+	return g.findBlocks(g.db.Where("space_id = ? AND id IN (?)", spaceID, linked))
 }
 
 func (g GormStore) GetDescendantBlocks(spaceID *SpaceID, id BlockID) ([]*Block, error) {
-	//TODO implement me
-	panic("implement me")
-}
+	page, err := g.QueryDescendantBlocks(spaceID, id, nil)
+	if err != nil {
+		return nil, err
+	}
 
-func (g GormStore) GetParentBlock(spaceID *SpaceID, id BlockID) (*Block, error) {
-	//TODO implement me
-	panic("implement me")
+	return page.Blocks, nil
 }
 
-func (g GormStore) GetBlocks(spaceID *SpaceID, ids []BlockID) ([]*Block, error) {
-	var blocks []*gormBlock
-	res := g.db.Where("id IN (?)", ids).Find(&blocks)
-	if res.Error != nil {
-		return nil, res.Error
+// QueryDescendantBlocks loads the descendants one level at a time.
+// like the MemStore it stops at page blocks, the page block is returned but not its children.
+// the levels stop at the max depth and the blocks before the start cursor are not expanded.
+func (g GormStore) QueryDescendantBlocks(spaceID *SpaceID, id BlockID, query *BlockQuery) (*BlockPage, error) {
+	pager, err := newBlockPager(query)
+	if err != nil {
+		return nil, err
+	}
+	root, err := g.GetBlock(spaceID, id)
+	if err != nil {
+		return nil, err
 	}
 
-	ret := make([]*Block, len(blocks))
-	for i, b := range blocks {
-		block, err := b.toBlock()
+	cursors := map[BlockID]BlockCursor{root.ID: ""}
+	children := make(map[BlockID][]*Block)
+	parentIDs := []BlockID{root.ID}
+	for depth := 0; len(parentIDs) > 0 && pager.expands(depth); depth++ {
+		level, err := g.findBlocks(g.db.
+			Where("space_id = ? AND parent_id IN (?)", spaceID, parentIDs).
+			Order("frac_index ASC, id ASC"))
 		if err != nil {
 			return nil, err
 		}
-		ret[i] = block
+
+		parentIDs = make([]BlockID, 0)
+		for _, block := range level {
+			cursor := cursors[block.ParentID].child(block)
+			if !cursor.reaches(pager.query.Start) {
+				continue
+			}
+			cursors[block.ID] = cursor
+			children[block.ParentID] = append(children[block.ParentID], block)
+			// stop at page block, no need to go further
+			if block.Type != PageBlockType {
+				parentIDs = append(parentIDs, block.ID)
+			}
+		}
+	}
+
+	// collect returns false once the page is full
+	var collect func(block *Block) bool
+	collect = func(block *Block) bool {
+		cursor := cursors[block.ID]
+		if cursor >= pager.query.Start && !pager.add(block, cursor) {
+			return false
+		}
+		for _, child := range children[block.ID] {
+			if !collect(child) {
+				return false
+			}
+		}
+		return true
+	}
+	collect(root)
+
+	return pager.page, nil
+}
+
+func (g GormStore) GetParentBlock(spaceID *SpaceID, id BlockID) (*Block, error) {
+	block, err := g.GetBlock(spaceID, id)
+	if err != nil {
+		return nil, err
 	}
 
-	return ret, nil
+	return g.GetBlock(spaceID, block.ParentID)
+}
+
+func (g GormStore) GetBlocks(spaceID *SpaceID, ids []BlockID) ([]*Block, error) {
+	return g.findBlocks(g.db.Where("space_id = ? AND id IN (?)", spaceID, ids))
 }
 
 func (g GormStore) GetWithFirstChildBlock(spaceID *SpaceID, id BlockID) ([]*Block, error) {
-	//TODO implement me
-	panic("implement me")
+	block, err := g.GetBlock(spaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := g.findBlocks(g.children(spaceID, id).Limit(1))
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]*Block{block}, children...), nil
 }
 
 func (g GormStore) GetWithLastChildBlock(spaceID *SpaceID, id BlockID) ([]*Block, error) {
-	//TODO implement me
-	panic("implement me")
+	block, err := g.GetBlock(spaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := g.findBlocks(g.db.
+		Where("space_id = ? AND parent_id = ?", spaceID, id).
+		Order("frac_index DESC, id DESC").
+		Limit(1))
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]*Block{block}, children...), nil
 }
 
 func (g GormStore) GetParentWithNextBlock(spaceID *SpaceID, id BlockID) ([]*Block, error) {
-	//TODO implement me
-	panic("implement me")
+	block, err := g.GetBlock(spaceID, id)
+	if err != nil {
+		return nil, fmt.Errorf("parent block for %v: %w", id, ErrNotFound)
+	}
+
+	parent, err := g.GetBlock(spaceID, block.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	index := block.Index.Key()
+	next, err := g.findBlocks(g.db.
+		Where("space_id = ? AND parent_id = ?", spaceID, block.ParentID).
+		Where("frac_index > ? OR (frac_index = ? AND id > ?)", index, index, block.ID).
+		Order("frac_index ASC, id ASC").
+		Limit(1))
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]*Block{parent, block}, next...), nil
 }
 
 func (g GormStore) GetParentWithPrevBlock(spaceID *SpaceID, id BlockID) ([]*Block, error) {
-	//TODO implement me
-	panic("implement me")
+	block, err := g.GetBlock(spaceID, id)
+	if err != nil {
+		return nil, fmt.Errorf("parent block for %v: %w", id, ErrNotFound)
+	}
+
+	parent, err := g.GetBlock(spaceID, block.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	index := block.Index.Key()
+	prev, err := g.findBlocks(g.db.
+		Where("space_id = ? AND parent_id = ?", spaceID, block.ParentID).
+		Where("frac_index < ? OR (frac_index = ? AND id < ?)", index, index, block.ID).
+		Order("frac_index DESC, id DESC").
+		Limit(1))
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]*Block{parent, block}, prev...), nil
 }
 
-func (g GormStore) GetAncestorEdges(spaceID *SpaceID, id []BlockID) ([]blockEdge, error) {
-	//TODO implement me
-	panic("implement me")
+func (g GormStore) GetAncestorEdges(spaceID *SpaceID, ids []BlockID) ([]blockEdge, error) {
+	edges := make([]blockEdge, 0)
+	for _, id := range ids {
+		curr := id
+		for {
+			var model gormBlock
+			res := g.db.Select("parent_id").
+				Where("space_id = ? AND id = ?", spaceID, curr).
+				Limit(1).
+				Find(&model)
+			if res.Error != nil {
+				return nil, res.Error
+			}
+			if res.RowsAffected == 0 {
+				return nil, fmt.Errorf("non space block has no parent: %w", ErrBlockNotFound{ID: curr})
+			}
+
+			parent := model.ParentID
+			if parent == RootBlockID {
+				break
+			}
+
+			edges = append(edges, blockEdge{parentID: parent, childID: curr})
+			if parent == *spaceID {
+				break
+			}
+			curr = parent
+		}
+	}
+
+	return edges, nil
 }
 
 func (g GormStore) GetTransaction(spaceID *SpaceID, id TransactionID) (*Transaction, error) {
-	//TODO implement me
-	panic("implement me")
+	var model gormTransaction
+	res := g.db.Where("space_id = ? AND id = ?", spaceID, id).Limit(1).Find(&model)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrTransactionNotFound{ID: id}
+	}
+
+	return model.toTransaction()
+}
+
+// GetSnapshot returns the snapshot of the last compaction of the space.
+func (g GormStore) GetSnapshot(spaceID *SpaceID) (*Snapshot, error) {
+	var model gormSnapshot
+	res := g.db.Where("space_id = ?", spaceID).Limit(1).Find(&model)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	var snapshot Snapshot
+	err := json.Unmarshal(model.Data, &snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// PutSnapshot replaces the snapshot of the space and deletes the transactions before it in one database transaction.
+func (g GormStore) PutSnapshot(snapshot *Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	return g.db.Transaction(func(db *gorm.DB) error {
+		var head gormTransaction
+		res := db.Select("seq").Where("space_id = ? AND id = ?", snapshot.SpaceID, snapshot.TransactionID).Limit(1).Find(&head)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrTransactionNotFound{ID: snapshot.TransactionID}
+		}
+
+		err := db.Where("space_id = ? AND seq < ?", snapshot.SpaceID, head.Seq).Delete(&gormTransaction{}).Error
+		if err != nil {
+			return err
+		}
+
+		return db.Save(&gormSnapshot{
+			SpaceID:       snapshot.SpaceID,
+			TransactionID: snapshot.TransactionID,
+			Data:          data,
+		}).Error
+	})
 }
 
+// PutTransaction appends the transaction to the space transaction log.
 func (g GormStore) PutTransaction(spaceID *SpaceID, tx *Transaction) error {
-	//TODO implement me
-	panic("implement me")
+	var seq int64
+	err := g.db.Model(&gormTransaction{}).
+		Select("COALESCE(MAX(seq), 0)").
+		Where("space_id = ?", spaceID).
+		Scan(&seq).Error
+	if err != nil {
+		return err
+	}
+
+	model, err := tx.toGormTransaction(*spaceID, seq+1)
+	if err != nil {
+		return err
+	}
+
+	err = g.db.Create(model).Error
+	if err != nil {
+		return err
+	}
+	tx.Seq = uint64(model.Seq)
+
+	// the changed blocks take the seq of the transaction as their version
+	changed := tx.changedBlocks()
+	if len(changed) == 0 {
+		return nil
+	}
+
+	return g.db.Model(&gormBlock{}).
+		Where("space_id = ? AND id IN ?", spaceID, changed).
+		Update("version", tx.Seq).Error
 }
 
 func (g GormStore) GetNextTransactions(spaceID *SpaceID, id TransactionID, start, limit int) ([]*Transaction, error) {
-	//TODO implement me
-	panic("implement me")
+	var from gormTransaction
+	res := g.db.Select("seq").Where("space_id = ? AND id = ?", spaceID, id).Limit(1).Find(&from)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return []*Transaction{}, nil
+	}
+
+	var models []*gormTransaction
+	res = g.db.Where("space_id = ? AND seq > ?", spaceID, from.Seq).
+		Order("seq ASC").
+		Offset(start).
+		Limit(limit).
+		Find(&models)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	txs := make([]*Transaction, len(models))
+	for i, model := range models {
+		tx, err := model.toTransaction()
+		if err != nil {
+			return nil, err
+		}
+		txs[i] = tx
+	}
+
+	return txs, nil
+}
+
+// GetTransactionsSince returns at most limit transactions with a seq after the given seq, in seq order.
+func (g GormStore) GetTransactionsSince(spaceID *SpaceID, seq uint64, limit int) ([]*Transaction, error) {
+	var models []*gormTransaction
+	res := g.db.Where("space_id = ? AND seq > ?", spaceID, int64(seq)).
+		Order("seq ASC").
+		Limit(limit).
+		Find(&models)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	txs := make([]*Transaction, len(models))
+	for i, model := range models {
+		tx, err := model.toTransaction()
+		if err != nil {
+			return nil, err
+		}
+		txs[i] = tx
+	}
+
+	return txs, nil
+}
+
+// children returns a query for the children of a block, linked blocks included, in index order.
+func (g GormStore) children(spaceID *SpaceID, id BlockID) *gorm.DB {
+	return g.db.Where("space_id = ? AND parent_id = ?", spaceID, id).Order("frac_index ASC, id ASC")
+}
+
+// findBlocks runs the block query and converts the result into blocks.
+func (g GormStore) findBlocks(query *gorm.DB) ([]*Block, error) {
+	var models []*gormBlock
+	res := query.Find(&models)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	blocks := make([]*Block, len(models))
+	for i, model := range models {
+		block, err := model.toBlock()
+		if err != nil {
+			return nil, err
+		}
+		blocks[i] = block
+	}
+
+	return blocks, nil
+}
+
+// findPage runs a tree query with the path column and adds the blocks to the page in the query order,
+// the path of a block is its cursor.
+func (g GormStore) findPage(query *gorm.DB, pager *blockPager) (*BlockPage, error) {
+	var models []*gormPathBlock
+	res := query.Scan(&models)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	for _, model := range models {
+		block, err := model.Block.toBlock()
+		if err != nil {
+			return nil, err
+		}
+		if !pager.add(block, BlockCursor(model.Path)) {
+			break
+		}
+	}
+
+	return pager.page, nil
 }
 
-func (g GormStore) ApplyChange(space *SpaceID, change *storeChange) error {
-	//TODO implement me
-	panic("implement me")
+// findDescendantsPage scans the descendants query into the page, the query finds no blocks for a missing block.
+func (g GormStore) findDescendantsPage(spaceID *SpaceID, id BlockID, query *gorm.DB, pager *blockPager) (*BlockPage, error) {
+	page, err := g.findPage(query, pager)
+	if err != nil || len(page.Blocks) > 0 {
+		return page, err
+	}
+	if _, err := g.GetBlock(spaceID, id); err != nil {
+		return nil, err
+	}
+
+	return page, nil
 }
 
 // gormSpace is a space in gorm database.
@@ -170,6 +797,10 @@ type gormSpace struct {
 	Name string    `gorm:"not null"`
 }
 
+func (gormSpace) TableName() string {
+	return "spaces"
+}
+
 //func (s *gormSpace) toSpace() *Space {
 //	return &Space{
 //		ID:   s.ID,
@@ -184,13 +815,42 @@ func (s *Space) toGormSpace() *gormSpace {
 	}
 }
 
+// gormBlock is a block in gorm database.
 type gormBlock struct {
 	ID       uuid.UUID `gorm:"type:uuid;primary_key"`
+	SpaceID  uuid.UUID `gorm:"type:uuid;not null;index"`
 	Type     string    `gorm:"not null"`
-	ParentID uuid.UUID `gorm:"type:uuid;not null"`
-	Index    string    `gorm:"not null"`
-	Deleted  bool      `gorm:"not null"`
-	Erased   bool      `gorm:"not null"`
+	Table    string    `gorm:"column:block_table"`
+	ParentID uuid.UUID `gorm:"type:uuid;not null;index:idx_blocks_parent"`
+	Index    indexKey  `gorm:"column:frac_index;not null;index:idx_blocks_parent"`
+	Props    []byte
+	Json     []byte
+	Deleted  bool   `gorm:"not null"`
+	Erased   bool   `gorm:"not null"`
+	Linked   bool   `gorm:"not null"`
+	Version  uint64 `gorm:"not null;default:0"`
+}
+
+func (gormBlock) TableName() string {
+	return "blocks"
+}
+
+// indexKey is the key of a block index, see FracIndex.Key
+type indexKey string
+
+// GormDBDataType compares the keys by bytes in postgres, the default collation of the database may not
+func (indexKey) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
+	if db.Dialector.Name() == "postgres" {
+		return `text COLLATE "C"`
+	}
+
+	return "text"
+}
+
+// gormPathBlock is a block row of a tree query with the path of the block from the query root
+type gormPathBlock struct {
+	Block gormBlock `gorm:"embedded"`
+	Path  string
 }
 
 func (b *gormBlock) toBlock() (*Block, error) {
@@ -198,27 +858,170 @@ func (b *gormBlock) toBlock() (*Block, error) {
 		ID:       b.ID,
 		ParentID: b.ParentID,
 		Type:     b.Type,
+		Table:    b.Table,
 		Deleted:  b.Deleted,
 		Erased:   b.Erased,
+		Linked:   b.Linked,
+		Version:  b.Version,
 	}
 
-	if b.Index != "" {
-		id := FracIndexFromBytes([]byte(b.Index))
-		block.Index = id
+	if len(b.Index) != 0 {
+		index, err := FracIndexFromKey(string(b.Index))
+		if err != nil {
+			return nil, err
+		}
+		block.Index = index
 	} else {
 		return nil, fmt.Errorf("index is empty")
 	}
 
+	if b.Props != nil {
+		block.Props = NewJsonDoc(b.Props)
+	}
+
+	if b.Json != nil {
+		block.Json = NewJsonDoc(b.Json)
+	}
+
 	return &block, nil
 }
 
-func (b *Block) toGormBlock() *gormBlock {
+func (b *Block) toGormBlock(spaceID SpaceID) *gormBlock {
 	return &gormBlock{
 		ID:       b.ID,
+		SpaceID:  spaceID,
 		Type:     b.Type,
+		Table:    b.Table,
 		ParentID: b.ParentID,
-		Index:    string(b.Index.Bytes()),
-		Deleted:  false,
-		Erased:   false,
+		Index:    indexKey(b.Index.Key()),
+		Props:    jsonDocBytes(b.Props),
+		Json:     jsonDocBytes(b.Json),
+		Deleted:  b.Deleted,
+		Erased:   b.Erased,
+		Linked:   b.Linked,
+		Version:  b.Version,
+	}
+}
+
+func jsonDocBytes(doc *JsonDoc) []byte {
+	if doc == nil {
+		return nil
+	}
+
+	return doc.Bytes()
+}
+
+// gormBackLink records that a block is linked inside another block.
+type gormBackLink struct {
+	SpaceID  uuid.UUID `gorm:"type:uuid;not null;index"`
+	BlockID  uuid.UUID `gorm:"type:uuid;primary_key"`
+	LinkedID uuid.UUID `gorm:"type:uuid;primary_key"`
+}
+
+func (gormBackLink) TableName() string {
+	return "back_links"
+}
+
+// gormTransaction is a transaction in the space transaction log.
+type gormTransaction struct {
+	ID      uuid.UUID `gorm:"type:uuid;primary_key"`
+	SpaceID uuid.UUID `gorm:"type:uuid;primary_key;uniqueIndex:idx_transactions_space_seq,priority:1"`
+	Seq     int64     `gorm:"not null;index;uniqueIndex:idx_transactions_space_seq,priority:2"`
+	UserID  uuid.UUID `gorm:"type:uuid"`
+	Time    time.Time
+	Clock   uint64 `gorm:"not null;default:0"`
+	HLC     uint64 `gorm:"column:hlc;not null;default:0"`
+	Jitter  []byte
+	Ops     []byte
+	Changes []byte
+	Inverse []byte
+}
+
+func (gormTransaction) TableName() string {
+	return "transactions"
+}
+
+// gormSnapshot is the snapshot the transaction log of a space starts at.
+type gormSnapshot struct {
+	SpaceID       uuid.UUID `gorm:"type:uuid;primary_key"`
+	TransactionID uuid.UUID `gorm:"type:uuid;not null"`
+	Data          []byte
+}
+
+func (gormSnapshot) TableName() string {
+	return "snapshots"
+}
+
+func (tx *Transaction) toGormTransaction(spaceID SpaceID, seq int64) (*gormTransaction, error) {
+	ops, err := json.Marshal(tx.Ops)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []byte
+	if tx.changes != nil {
+		changes, err = json.Marshal(tx.changes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var inverse []byte
+	if tx.inverse != nil {
+		inverse, err = json.Marshal(tx.inverse)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &gormTransaction{
+		ID:      tx.ID,
+		SpaceID: spaceID,
+		Seq:     seq,
+		UserID:  tx.UserID,
+		Time:    tx.Time,
+		Clock:   tx.Clock,
+		HLC:     uint64(tx.HLC),
+		Jitter:  tx.Jitter,
+		Ops:     ops,
+		Changes: changes,
+		Inverse: inverse,
+	}, nil
+}
+
+func (t *gormTransaction) toTransaction() (*Transaction, error) {
+	tx := &Transaction{
+		ID:      t.ID,
+		SpaceID: t.SpaceID,
+		UserID:  t.UserID,
+		Time:    t.Time,
+		Seq:     uint64(t.Seq),
+		Clock:   t.Clock,
+		HLC:     HLC(t.HLC),
+		Jitter:  t.Jitter,
+	}
+
+	if t.Ops != nil {
+		err := json.Unmarshal(t.Ops, &tx.Ops)
+		if err != nil {
+			return nil, err
+		}
 	}
+
+	if t.Changes != nil {
+		tx.changes = NewSyncBlocks()
+		err := json.Unmarshal(t.Changes, tx.changes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if t.Inverse != nil {
+		err := json.Unmarshal(t.Inverse, &tx.inverse)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tx, nil
 }
@@ -1,25 +1,433 @@
 package blocktree
 
 import (
+	"path/filepath"
 	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
-func TestGormStore_Create(t *testing.T) {
-	//db, err := gorm.Open(sqlite.Open("./tmp/blocktree.db"), &gorm.Config{})
-	//if err != nil {
-	//	t.Fatal(err)
-	//}
+func newTestGormStore(t *testing.T) *GormStore {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "blocktree.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewGormStore(db)
+	err = store.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	//store := NewGormStore(db)
-	//s := newSpace(s1, "physics")
-	//err = store.CreateSpace(s)
-	//assert.NoError(t, err)
+	return store
 }
 
 func TestGormStore_CreateSpace(t *testing.T) {
+	store := newTestGormStore(t)
+	s := newSpace(s1, "physics")
+	err := store.CreateSpace(s)
+	assert.NoError(t, err)
+
+	err = store.CreateSpace(s)
+	assert.Error(t, err)
 
+	block, err := store.GetBlock(&s1, s1)
+	assert.NoError(t, err)
+	assert.Equal(t, "space", block.Type)
+	assert.Equal(t, RootBlockID, block.ParentID)
+	assert.Equal(t, `{"name": "physics"}`, block.Props.String())
+
+	tx, err := store.GetLatestTransaction(&s1)
+	assert.NoError(t, err)
+	assert.Equal(t, uuid.Nil, tx.ID)
 }
 
 func TestGormStore_CreateBlock(t *testing.T) {
+	store := newTestGormStore(t)
+	s := newSpace(s1, "physics")
+	err := store.CreateSpace(s)
+	assert.NoError(t, err)
+
+	bl1 := NewBlock(b1, s1, "p1")
+	err = store.CreateBlock(&s1, bl1)
+	assert.NoError(t, err)
+	block, err := store.GetBlock(&s1, b1)
+	assert.NoError(t, err)
+	assert.Equal(t, bl1, block)
+
+	spaceID, err := store.GetBlockSpaceID(&b1)
+	assert.NoError(t, err)
+	assert.Equal(t, s1, *spaceID)
+
+	_, err = store.GetBlock(&s1, b2)
+	assert.Error(t, err)
+}
+
+func TestGormStore_InsertMultipleBlocks(t *testing.T) {
+	store := newTestGormStore(t)
+	err := store.CreateSpace(newSpace(s1, "physics"))
+	assert.NoError(t, err)
+
+	bl1 := NewBlock(b1, s1, "p1")
+	bl2 := NewBlock(b2, b1, "p2")
+	bl3 := NewBlock(b3, b2, "page")
+	bl4 := NewBlock(b4, b3, "p4")
+	for _, block := range []*Block{bl1, bl2, bl3, bl4} {
+		err = store.CreateBlock(&s1, block)
+		assert.NoError(t, err)
+	}
+
+	// check the parent-child relationship
+	blocks, err := store.GetChildrenBlocks(&s1, s1)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Block{bl1}, blocks)
+
+	// check the parent-descendant relationship
+	blocks, err = store.GetDescendantBlocks(&s1, b1)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Block{bl1, bl2, bl3}, blocks)
+
+	parent, err := store.GetParentBlock(&s1, b4)
+	assert.NoError(t, err)
+	assert.Equal(t, bl3, parent)
+
+	edges, err := store.GetAncestorEdges(&s1, []BlockID{b4})
+	assert.NoError(t, err)
+	assert.Equal(t, []blockEdge{
+		{parentID: b3, childID: b4},
+		{parentID: b2, childID: b3},
+		{parentID: b1, childID: b2},
+		{parentID: s1, childID: b1},
+	}, edges)
+}
+
+func TestGormStore_InsertMultipleBlocksInMultipleSpaces(t *testing.T) {
+	store := newTestGormStore(t)
+	err := store.CreateSpace(newSpace(s1, "physics"))
+	assert.NoError(t, err)
+	err = store.CreateSpace(newSpace(s2, "chemistry"))
+	assert.NoError(t, err)
+
+	bl1 := NewBlock(b1, s1, "p1")
+	err = store.CreateBlock(&s1, bl1)
+	assert.NoError(t, err)
+
+	bl2 := NewBlock(b2, s2, "p2")
+	err = store.CreateBlock(&s2, bl2)
+	assert.NoError(t, err)
+
+	_, err = store.GetBlock(&s1, b2)
+	assert.Error(t, err)
+
+	blocks, err := store.GetChildrenBlocks(&s1, s1)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Block{bl1}, blocks)
+
+	blocks, err = store.GetChildrenBlocks(&s2, s2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Block{bl2}, blocks)
+}
+
+func TestGormStore_InsertOpBetween(t *testing.T) {
+	store := newTestGormStore(t)
+	err := createSpace(store, s1)
+	assert.NoError(t, err)
+
+	applyTransaction(t, store, createTx(s1,
+		insertOp(b1, "page", s1, PositionStart),
+		insertOp(b2, "title", b1, PositionStart),
+	))
+
+	applyTransaction(t, store, createTx(s1,
+		insertOp(b3, "p1", b2, PositionAfter),
+		insertOp(b4, "p2", b3, PositionBefore),
+		insertOp(b5, "p3", b1, PositionEnd),
+	))
+
+	applyTransaction(t, store, createTx(s1,
+		insertOp(b6, "p4", b2, PositionBefore),
+	))
+
+	blocks, err := store.GetChildrenBlockIDs(&s1, b1)
+	assert.NoError(t, err)
+	assert.Equal(t, []BlockID{b6, b2, b4, b3, b5}, blocks)
+
+	blocks, err = store.GetChildrenBlockIDs(&s1, b5)
+	assert.NoError(t, err)
+	assert.Empty(t, blocks)
+}
+
+func TestGormStore_MoveOp(t *testing.T) {
+	store := newTestGormStore(t)
+	err := prepareSpace(store, s1)
+	assert.NoError(t, err)
+
+	applyTransaction(t, store, createTx(s1,
+		moveOp(b1, s1, b2, PositionStart),
+		moveOp(b3, s1, b2, PositionEnd),
+	))
+
+	applyTransaction(t, store, createTx(s1,
+		moveOp(b4, s1, b1, PositionAfter),
+		moveOp(b5, s1, b3, PositionBefore),
+	))
+
+	blocks, err := store.GetChildrenBlocks(&s1, b2)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(blocks), "b2 should have 4 children blocks")
+	assert.Equal(t, b1, blocks[0].ID)
+	assert.Equal(t, b4, blocks[1].ID)
+	assert.Equal(t, b5, blocks[2].ID)
+	assert.Equal(t, b3, blocks[3].ID)
+
+	blocks, err = store.GetChildrenBlocks(&s1, s1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(blocks))
+}
+
+func TestGormStore_MoveOpWithCycle(t *testing.T) {
+	store := newTestGormStore(t)
+	err := prepareSpace(store, s1)
+	assert.NoError(t, err)
+
+	tx := createTx(s1,
+		moveOp(b1, s1, b2, PositionStart),
+		moveOp(b2, s1, b3, PositionStart),
+		moveOp(b3, s1, b4, PositionStart),
+		moveOp(b4, s1, b1, PositionStart),
+	)
+
+	_, err = tx.prepare(store)
+	assert.EqualError(t, err, ErrCreatesCycle.Error())
+}
+
+func TestGormStore_BlockLink(t *testing.T) {
+	store := newTestGormStore(t)
+	err := prepareSpace(store, s1)
+	assert.NoError(t, err)
+
+	applyTransaction(t, store, createTx(s1,
+		linkInsertOp(b6, "l1", b2),
+		linkInsertOp(b7, "l2", b2),
+		insertOp(b8, "p2", b2, PositionEnd),
+		insertOp(b9, "p2", b6, PositionEnd),
+	))
+
+	ids, err := store.GetChildrenBlockIDs(&s1, b2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(ids), "b2 should have total 3 linked+child blocks")
+
+	blocks, err := store.GetChildrenBlocks(&s1, b2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(blocks), "b2 should have 1 children block")
+
+	blocks, err = store.GetLinkedBlocks(&s1, b2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(blocks), "b2 should have 2 linked blocks")
+
+	descendants, err := store.GetDescendantBlocks(&s1, s1)
+	assert.NoError(t, err)
+	v1, err := blockViewFromBlocks(s1, descendants)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(v1.Children))
+
+	applyTransaction(t, store, createTx(s1, linkOp(b5, b3)))
+
+	blocks, err = store.GetBackLinks(&s1, b5)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(blocks))
+	assert.Equal(t, b3, blocks[0].ID)
+
+	applyTransaction(t, store, createTx(s1, unlinkOp(b5)))
+
+	blocks, err = store.GetBackLinks(&s1, b5)
+	assert.NoError(t, err)
+	assert.Empty(t, blocks)
+}
+
+func TestGormStore_PatchOp(t *testing.T) {
+	store := newTestGormStore(t)
+	err := createSpace(store, s1)
+	assert.NoError(t, err)
+
+	applyTransaction(t, store, createTx(s1,
+		insertOp(b1, "p1", s1, PositionEnd),
+		insertOp(b2, "p2", s1, PositionEnd),
+	))
+
+	applyTransaction(t, store, createTx(s1,
+		patchOp(b1, []byte(`[{"op":"add","path":"/name","value":"John Doe"}]`)),
+	))
+
+	block, err := store.GetBlock(&s1, b1)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"John Doe"}`, string(block.Json.Content))
+
+	applyTransaction(t, store, createTx(s1,
+		patchOp(b1, []byte(`[{"op":"add","path":"/age","value":30}]`)),
+	))
+
+	block, err = store.GetBlock(&s1, b1)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"John Doe","age":30}`, string(block.Json.Content))
+}
+
+func TestGormStore_UpdateBlockProps(t *testing.T) {
+	store := newTestGormStore(t)
+	err := createSpace(store, s1)
+	assert.NoError(t, err)
+
+	applyTransaction(t, store, createTx(s1, insertOp(b1, "p1", s1, PositionEnd)))
+	applyTransaction(t, store, createTx(s1,
+		updateOp(b1, []byte(`[{"op":"add","path":"/name","value":"John Doe"}, {"op":"add","path":"/age","value":30}]`)),
+	))
+
+	block, err := store.GetBlock(&s1, b1)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"John Doe","age":30}`, block.Props.String())
+
+	applyTransaction(t, store, createTx(s1,
+		updateOp(b1, []byte(`[{"op":"replace","path":"/name","value":"Jane Doe"}]`)),
+		updateOp(b1, []byte(`[{"op":"add","path":"/age","value":20},{"op":"add","path":"/pin","value":700010}]`)),
+	))
+
+	block, err = store.GetBlock(&s1, b1)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"Jane Doe","age":20,"pin":700010}`, block.Props.String())
+}
+
+func TestGormStore_DeleteAndErase(t *testing.T) {
+	store := newTestGormStore(t)
+	err := prepareSpace(store, s1)
+	assert.NoError(t, err)
+
+	applyTransaction(t, store, createTx(s1, deleteOp(b1), eraseOp(b2)))
+
+	blocks, err := store.GetBlocks(&s1, []BlockID{b1, b2})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(blocks))
+	for _, block := range blocks {
+		switch block.ID {
+		case b1:
+			assert.True(t, block.Deleted)
+		case b2:
+			assert.True(t, block.Erased)
+		}
+	}
+}
+
+func TestGormStore_Transactions(t *testing.T) {
+	store := newTestGormStore(t)
+	err := createSpace(store, s1)
+	assert.NoError(t, err)
+
+	tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionEnd))
+	tx2 := createTx(s1, insertOp(b2, "p2", s1, PositionEnd))
+	applyTransaction(t, store, tx1)
+	applyTransaction(t, store, tx2)
+
+	// an applied transaction is not applied again
+	change, err := tx1.prepare(store)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, change.blockChange.inserted.Size())
+
+	tx, err := store.GetTransaction(&s1, tx1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, tx1.Ops, tx.Ops)
+	assert.True(t, tx.changes.inserted.Contains(b1))
+
+	latest, err := store.GetLatestTransaction(&s1)
+	assert.NoError(t, err)
+	assert.Equal(t, tx2.ID, latest.ID)
+
+	txs, err := store.GetNextTransactions(&s1, uuid.Nil, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(txs))
+	assert.Equal(t, tx1.ID, txs[0].ID)
+	assert.Equal(t, tx2.ID, txs[1].ID)
+
+	txs, err = store.GetNextTransactions(&s1, tx1.ID, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(txs))
+	assert.Equal(t, tx2.ID, txs[0].ID)
+
+	txs, err = store.GetNextTransactions(&s1, uuid.Nil, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(txs))
+}
+
+func TestGormStore_ApiGetUpdates(t *testing.T) {
+	api := NewApi(newTestGormStore(t))
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	_, err = api.Apply(
+		createTx(s1, insertOp(b1, "p1", s1, PositionEnd)),
+		createTx(s1, insertOp(b2, "p2", b1, PositionAfter)),
+	)
+	assert.NoError(t, err)
+
+	updates, err := api.GetUpdates(s1, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []BlockID{b1, b2}, updates.Children[s1])
+	assert.Contains(t, updates.Blocks, b1)
+	assert.Contains(t, updates.Blocks, b2)
+}
+
+// textIndexBlock is the blocks table of the versions that stored the index bytes as text
+type textIndexBlock struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key"`
+	SpaceID  uuid.UUID `gorm:"type:uuid;not null;index"`
+	Type     string    `gorm:"not null"`
+	Table    string    `gorm:"column:block_table"`
+	ParentID uuid.UUID `gorm:"type:uuid;not null;index:idx_blocks_parent"`
+	Index    string    `gorm:"column:frac_index;not null;index:idx_blocks_parent"`
+	Props    []byte
+	Json     []byte
+	Deleted  bool `gorm:"not null"`
+	Erased   bool `gorm:"not null"`
+	Linked   bool `gorm:"not null"`
+}
+
+func (textIndexBlock) TableName() string {
+	return "blocks"
+}
+
+func TestGormStore_MigrateIndexText(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "blocktree.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	assert.NoError(t, err)
+	err = db.AutoMigrate(&textIndexBlock{})
+	assert.NoError(t, err)
+
+	indices := NewSpread(3)
+	for i, id := range []uuid.UUID{b3, b1, b2} {
+		err = db.Create(&textIndexBlock{ID: id, SpaceID: s1, Type: "para", ParentID: s1, Index: string(indices[i].Bytes())}).Error
+		assert.NoError(t, err)
+	}
+
+	// the indices are rewritten as keys, the blocks keep their order
+	store := NewGormStore(db)
+	err = store.Migrate()
+	assert.NoError(t, err)
+	children, err := store.GetChildrenBlocks(&s1, s1)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{b3, b1, b2}, blockIDs(children))
+	for i, child := range children {
+		assert.Equal(t, indices[i], child.Index)
+	}
 
+	// the keys are migrated once
+	err = store.Migrate()
+	assert.NoError(t, err)
+	block, err := store.GetBlock(&s1, b1)
+	assert.NoError(t, err)
+	assert.Equal(t, indices[1], block.Index)
 }
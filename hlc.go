@@ -0,0 +1,44 @@
+package blocktree
+
+import (
+	"fmt"
+	"time"
+)
+
+// hlcLogicalBits is the number of low bits of a HLC holding the logical counter
+const hlcLogicalBits = 16
+
+// HLC is a hybrid logical clock timestamp.
+// the high 48 bits hold the physical time in milliseconds and the low 16 bits a logical counter,
+// the timestamps are ordered like their uint64 values.
+type HLC uint64
+
+// NewHLC returns the timestamp of the wall time with the logical counter
+func NewHLC(wall time.Time, logical uint16) HLC {
+	return HLC(uint64(wall.UnixMilli())<<hlcLogicalBits | uint64(logical))
+}
+
+// Time returns the physical time of the timestamp
+func (h HLC) Time() time.Time {
+	return time.UnixMilli(int64(h >> hlcLogicalBits))
+}
+
+// Logical returns the logical counter of the timestamp
+func (h HLC) Logical() uint16 {
+	return uint16(h)
+}
+
+func (h HLC) String() string {
+	return fmt.Sprintf("%s.%d", h.Time().UTC().Format(time.RFC3339Nano), h.Logical())
+}
+
+// next returns the timestamp of an event at the wall time after h.
+// the logical counter is bumped when the wall time is not ahead of h, an overflow moves the timestamp to the next millisecond.
+func (h HLC) next(wall time.Time) HLC {
+	now := NewHLC(wall, 0)
+	if now > h {
+		return now
+	}
+
+	return h + 1
+}
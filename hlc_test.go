@@ -0,0 +1,29 @@
+package blocktree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHLC(t *testing.T) {
+	wall := time.UnixMilli(1700000000123)
+	h := NewHLC(wall, 7)
+	assert.Equal(t, wall, h.Time())
+	assert.Equal(t, uint16(7), h.Logical())
+	assert.Less(t, h, NewHLC(wall.Add(time.Millisecond), 0))
+
+	// the wall time ahead of the clock resets the logical counter
+	next := h.next(wall.Add(time.Second))
+	assert.Equal(t, NewHLC(wall.Add(time.Second), 0), next)
+
+	// the wall time behind the clock bumps the logical counter
+	next = h.next(wall.Add(-time.Second))
+	assert.Equal(t, wall, next.Time())
+	assert.Equal(t, uint16(8), next.Logical())
+
+	// an overflow of the counter moves to the next millisecond
+	next = NewHLC(wall, 1<<16-1).next(wall)
+	assert.Equal(t, NewHLC(wall.Add(time.Millisecond), 0), next)
+}
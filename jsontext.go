@@ -0,0 +1,373 @@
+package blocktree
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// TextID identifies a character of a collaborative text, each site numbers its characters with a lamport clock.
+// the zero id is the start of the text.
+type TextID struct {
+	Clock uint64
+	Site  string
+}
+
+// ParseTextID parses the clock@site form of an id, the empty string is the zero id
+func ParseTextID(s string) (TextID, error) {
+	if s == "" {
+		return TextID{}, nil
+	}
+
+	clock, site, ok := strings.Cut(s, "@")
+	if !ok || site == "" {
+		return TextID{}, fmt.Errorf("bad text id %q", s)
+	}
+	value, err := strconv.ParseUint(clock, 10, 64)
+	if err != nil || value == 0 {
+		return TextID{}, fmt.Errorf("bad text id clock %q", s)
+	}
+
+	return TextID{Clock: value, Site: site}, nil
+}
+
+// IsZero reports whether the id is the start of the text
+func (id TextID) IsZero() bool {
+	return id.Clock == 0 && id.Site == ""
+}
+
+func (id TextID) String() string {
+	if id.IsZero() {
+		return ""
+	}
+
+	return strconv.FormatUint(id.Clock, 10) + "@" + id.Site
+}
+
+func (id TextID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(id.String())
+}
+
+func (id *TextID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	parsed, err := ParseTextID(s)
+	if err != nil {
+		return err
+	}
+	*id = parsed
+
+	return nil
+}
+
+// less orders the ids by clock and then by site
+func (id TextID) less(other TextID) bool {
+	if id.Clock != other.Clock {
+		return id.Clock < other.Clock
+	}
+
+	return id.Site < other.Site
+}
+
+// TextPatch edits the collaborative text at the path of a json document, it is the patch of a text op.
+// the edits refer to the characters by id instead of by position, the concurrent edits of a text merge
+// in any order. the first patch of a path creates the text.
+type TextPatch struct {
+	// Path is the json pointer of the text, the parent of the text must exist
+	Path  string     `json:"path"`
+	Edits []TextEdit `json:"edits"`
+}
+
+// TextEdit inserts the text after a character and restores or deletes the characters, in that order.
+type TextEdit struct {
+	// After is the character the text is inserted after, the zero id inserts at the start
+	After TextID `json:"after"`
+	// ID is the id of the first inserted character, the next characters get the next clocks of the site
+	ID TextID `json:"id"`
+	// Insert is the inserted text, an edit with an id that is already in the text is applied once
+	Insert string `json:"insert,omitempty"`
+	// Delete are the characters deleted from the text, they stay in the text as the place of the later inserts
+	Delete []TextID `json:"delete,omitempty"`
+	// Undelete are the deleted characters that are put back, the undo of a delete
+	Undelete []TextID `json:"undelete,omitempty"`
+}
+
+// textNode is a collaborative text in a json document, the characters are in the text order with the deleted ones.
+// the readers that do not merge the edits read the text.
+type textNode struct {
+	Text  string     `json:"$text"`
+	Chars []textChar `json:"$chars"`
+}
+
+type textChar struct {
+	ID      TextID `json:"id"`
+	Char    string `json:"c"`
+	Deleted bool   `json:"d,omitempty"`
+}
+
+// Text returns the collaborative text at the path, empty when the path has no text yet
+func (j *JsonDoc) Text(path string) (string, error) {
+	node, err := j.textNode(path)
+	if err != nil {
+		return "", err
+	}
+
+	return node.Text, nil
+}
+
+// InsertText returns the patch that inserts the text at the position of the collaborative text at the path,
+// the patch is not applied. the characters get the clocks after the last clock in the text.
+func (j *JsonDoc) InsertText(path, site string, pos int, text string) (*TextPatch, error) {
+	node, err := j.textNode(path)
+	if err != nil {
+		return nil, err
+	}
+
+	chars := node.visible()
+	if pos < 0 || pos > len(chars) {
+		return nil, fmt.Errorf("text position %d out of range [0, %d]", pos, len(chars))
+	}
+
+	edit := TextEdit{
+		ID:     TextID{Clock: node.clock() + 1, Site: site},
+		Insert: text,
+	}
+	if pos > 0 {
+		edit.After = chars[pos-1].ID
+	}
+
+	return &TextPatch{Path: path, Edits: []TextEdit{edit}}, nil
+}
+
+// DeleteText returns the patch that deletes n characters from the position of the collaborative text at the path,
+// the patch is not applied.
+func (j *JsonDoc) DeleteText(path string, pos, n int) (*TextPatch, error) {
+	node, err := j.textNode(path)
+	if err != nil {
+		return nil, err
+	}
+
+	chars := node.visible()
+	if pos < 0 || n < 0 || pos+n > len(chars) {
+		return nil, fmt.Errorf("text range [%d, %d) out of range [0, %d]", pos, pos+n, len(chars))
+	}
+
+	edit := TextEdit{Delete: make([]TextID, 0, n)}
+	for _, char := range chars[pos : pos+n] {
+		edit.Delete = append(edit.Delete, char.ID)
+	}
+
+	return &TextPatch{Path: path, Edits: []TextEdit{edit}}, nil
+}
+
+// ApplyText applies the text patch and returns the patch that reverts it.
+func (j *JsonDoc) ApplyText(patch *TextPatch) (*TextPatch, error) {
+	if !strings.HasPrefix(patch.Path, "/") {
+		return nil, fmt.Errorf("bad text path %q", patch.Path)
+	}
+
+	node, err := j.textNode(patch.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	inverse := &TextPatch{Path: patch.Path, Edits: make([]TextEdit, 0, len(patch.Edits))}
+	for _, edit := range patch.Edits {
+		undo, err := node.apply(edit)
+		if err != nil {
+			return nil, err
+		}
+		inverse.Edits = append(inverse.Edits, undo)
+	}
+	slices.Reverse(inverse.Edits)
+
+	node.Text = node.String()
+	value, err := json.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+	add, err := json.Marshal([]map[string]interface{}{
+		{"op": "add", "path": patch.Path, "value": json.RawMessage(value)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return inverse, j.Apply(add)
+}
+
+// textNode returns the collaborative text at the path, an empty text when the path has no value
+func (j *JsonDoc) textNode(path string) (*textNode, error) {
+	node := &textNode{Chars: make([]textChar, 0)}
+	if j == nil || j.Content == nil {
+		return node, nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(j.Content, &doc); err != nil {
+		return nil, err
+	}
+
+	value, ok := jsonPointerValue(doc, path)
+	if !ok {
+		return node, nil
+	}
+	object, isObject := value.(map[string]interface{})
+	if _, isText := object["$chars"]; !isObject || !isText {
+		return nil, fmt.Errorf("value at %q is not a text", path)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, node); err != nil {
+		return nil, fmt.Errorf("value at %q is not a text: %w", path, err)
+	}
+
+	return node, nil
+}
+
+// apply applies the edit to the text and returns the edit that reverts it
+func (t *textNode) apply(edit TextEdit) (TextEdit, error) {
+	undo := TextEdit{}
+	if edit.Insert != "" {
+		if edit.ID.Clock == 0 || edit.ID.Site == "" {
+			return undo, fmt.Errorf("text insert without an id")
+		}
+
+		if t.find(edit.ID) < 0 {
+			pos := t.integrate(edit.After, edit.ID)
+			if pos < 0 {
+				return undo, fmt.Errorf("text insert after the missing character %v", edit.After)
+			}
+
+			runes := []rune(edit.Insert)
+			chars := make([]textChar, len(runes))
+			for i, r := range runes {
+				chars[i] = textChar{ID: TextID{Clock: edit.ID.Clock + uint64(i), Site: edit.ID.Site}, Char: string(r)}
+				undo.Delete = append(undo.Delete, chars[i].ID)
+			}
+			t.Chars = slices.Insert(t.Chars, pos, chars...)
+		}
+	}
+
+	for _, id := range edit.Undelete {
+		i := t.find(id)
+		if i < 0 {
+			return undo, fmt.Errorf("text undelete of the missing character %v", id)
+		}
+		if t.Chars[i].Deleted {
+			t.Chars[i].Deleted = false
+			undo.Delete = append(undo.Delete, id)
+		}
+	}
+
+	for _, id := range edit.Delete {
+		i := t.find(id)
+		if i < 0 {
+			return undo, fmt.Errorf("text delete of the missing character %v", id)
+		}
+		if !t.Chars[i].Deleted {
+			t.Chars[i].Deleted = true
+			undo.Undelete = append(undo.Undelete, id)
+		}
+	}
+
+	return undo, nil
+}
+
+// integrate returns the position of a character inserted after the character with the id, -1 when it is missing.
+// the concurrent inserts after the same character are ordered with the later ids first, the characters after
+// a later id have a later clock too and are skipped with it.
+func (t *textNode) integrate(after, id TextID) int {
+	pos := 0
+	if !after.IsZero() {
+		pos = t.find(after) + 1
+		if pos == 0 {
+			return -1
+		}
+	}
+
+	for pos < len(t.Chars) && id.less(t.Chars[pos].ID) {
+		pos++
+	}
+
+	return pos
+}
+
+// find returns the position of the character with the id, -1 when it is missing
+func (t *textNode) find(id TextID) int {
+	return slices.IndexFunc(t.Chars, func(char textChar) bool {
+		return char.ID == id
+	})
+}
+
+// visible returns the characters that are not deleted
+func (t *textNode) visible() []textChar {
+	chars := make([]textChar, 0, len(t.Chars))
+	for _, char := range t.Chars {
+		if !char.Deleted {
+			chars = append(chars, char)
+		}
+	}
+
+	return chars
+}
+
+// clock returns the latest clock of the text
+func (t *textNode) clock() uint64 {
+	var clock uint64
+	for _, char := range t.Chars {
+		clock = max(clock, char.ID.Clock)
+	}
+
+	return clock
+}
+
+func (t *textNode) String() string {
+	var text strings.Builder
+	for _, char := range t.visible() {
+		text.WriteString(char.Char)
+	}
+
+	return text.String()
+}
+
+// jsonPointerValue returns the value at the json pointer in the decoded document
+func jsonPointerValue(doc interface{}, pointer string) (interface{}, bool) {
+	if pointer == "" {
+		return doc, true
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+
+	value := doc
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch container := value.(type) {
+		case map[string]interface{}:
+			next, ok := container[token]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(container) {
+				return nil, false
+			}
+			value = container[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
@@ -0,0 +1,175 @@
+package blocktree
+
+import (
+	"encoding/json"
+	"testing"
+
+	v1 "github.com/emrgen/blocktree/apis/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// editText builds the patch with the edit and applies it to the doc
+func editText(t *testing.T, doc *JsonDoc, edit func(doc *JsonDoc) (*TextPatch, error)) *TextPatch {
+	patch, err := edit(doc)
+	require.NoError(t, err)
+	_, err = doc.ApplyText(patch)
+	require.NoError(t, err)
+
+	return patch
+}
+
+func TestJsonDoc_ApplyText(t *testing.T) {
+	doc := NewJsonDoc([]byte(`{"title":"plain"}`))
+	editText(t, doc, func(doc *JsonDoc) (*TextPatch, error) { return doc.InsertText("/text", "a", 0, "hello") })
+	editText(t, doc, func(doc *JsonDoc) (*TextPatch, error) { return doc.InsertText("/text", "a", 5, " world") })
+	editText(t, doc, func(doc *JsonDoc) (*TextPatch, error) { return doc.DeleteText("/text", 0, 1) })
+	patch := editText(t, doc, func(doc *JsonDoc) (*TextPatch, error) { return doc.InsertText("/text", "a", 0, "J") })
+
+	text, err := doc.Text("/text")
+	require.NoError(t, err)
+	assert.Equal(t, "Jello world", text)
+
+	// the readers that do not merge the edits read the text next to the characters
+	var content struct {
+		Title string `json:"title"`
+		Text  struct {
+			Text string `json:"$text"`
+		} `json:"text"`
+	}
+	require.NoError(t, json.Unmarshal(doc.Content, &content))
+	assert.Equal(t, "plain", content.Title)
+	assert.Equal(t, "Jello world", content.Text.Text)
+
+	// the inverse puts the text back, the patch again is applied once
+	inverse, err := doc.ApplyText(patch)
+	require.NoError(t, err)
+	text, _ = doc.Text("/text")
+	assert.Equal(t, "Jello world", text)
+	assert.Empty(t, inverse.Edits[0].Delete)
+
+	undo := editText(t, doc, func(doc *JsonDoc) (*TextPatch, error) { return doc.DeleteText("/text", 0, 6) })
+	inverse, err = doc.ApplyText(undo)
+	require.NoError(t, err)
+	_, err = doc.ApplyText(inverse)
+	require.NoError(t, err)
+	text, _ = doc.Text("/text")
+	assert.Equal(t, "world", text)
+
+	_, err = doc.Text("/title")
+	assert.Error(t, err, "a plain string is not a text")
+	_, err = doc.ApplyText(&TextPatch{Path: "/text", Edits: []TextEdit{{After: TextID{Clock: 99, Site: "b"}, ID: TextID{Clock: 100, Site: "b"}, Insert: "x"}}})
+	assert.Error(t, err)
+	_, err = doc.ApplyText(&TextPatch{Path: "/missing/text", Edits: []TextEdit{{ID: TextID{Clock: 100, Site: "b"}, Insert: "x"}}})
+	assert.Error(t, err)
+}
+
+func TestJsonDoc_ConcurrentText(t *testing.T) {
+	base := NewJsonDoc([]byte(`{}`))
+	editText(t, base, func(doc *JsonDoc) (*TextPatch, error) { return doc.InsertText("/text", "a", 0, "abc") })
+
+	// the sites edit the same spot of the text at the same time
+	var patches [3]*TextPatch
+	var err error
+	patches[0], err = base.InsertText("/text", "a", 1, "XY")
+	require.NoError(t, err)
+	patches[1], err = base.InsertText("/text", "b", 1, "12")
+	require.NoError(t, err)
+	patches[2], err = base.DeleteText("/text", 0, 2)
+	require.NoError(t, err)
+
+	orders := [][]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}}
+	texts := make([]string, 0, len(orders))
+	for _, order := range orders {
+		doc := base.Clone()
+		for _, i := range order {
+			_, err := doc.ApplyText(patches[i])
+			require.NoError(t, err)
+		}
+		text, err := doc.Text("/text")
+		require.NoError(t, err)
+		texts = append(texts, text)
+	}
+
+	// the later site goes first, the inserts keep their characters together
+	for _, text := range texts {
+		assert.Equal(t, "12XYc", text)
+	}
+}
+
+func TestTextID(t *testing.T) {
+	id, err := ParseTextID("12@site@host")
+	require.NoError(t, err)
+	assert.Equal(t, TextID{Clock: 12, Site: "site@host"}, id)
+	assert.Equal(t, "12@site@host", id.String())
+
+	id, err = ParseTextID("")
+	require.NoError(t, err)
+	assert.True(t, id.IsZero())
+
+	for _, bad := range []string{"12", "@a", "0@a", "x@a"} {
+		_, err = ParseTextID(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func textOp(t *testing.T, patch *TextPatch) Op {
+	data, err := json.Marshal(patch)
+	require.NoError(t, err)
+
+	return Op{Table: "block", Type: OpTypeText, BlockID: b1, Patch: data}
+}
+
+func TestApi_TextOp(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	require.NoError(t, err)
+
+	first := DefaultJsonDoc()
+	patch, err := first.InsertText("/text", "a", 0, "hello")
+	require.NoError(t, err)
+	_, err = api.Apply(createTx(s1, insertOp(b1, "para", s1, PositionEnd), textOp(t, patch)))
+	require.NoError(t, err)
+
+	// two users edit the paragraph from the same state, the second edit merges with the first
+	block, err := api.GetBlock(s1, b1)
+	require.NoError(t, err)
+	edits := make([]*TextPatch, 2)
+	edits[0], err = block.Json.InsertText("/text", "a", 5, " world")
+	require.NoError(t, err)
+	edits[1], err = block.Json.InsertText("/text", "b", 0, "oh, ")
+	require.NoError(t, err)
+
+	tx := createTx(s1, textOp(t, edits[0]))
+	_, err = api.Apply(tx)
+	require.NoError(t, err)
+	_, err = api.Apply(createTx(s1, textOp(t, edits[1])))
+	require.NoError(t, err)
+
+	block, err = api.GetBlock(s1, b1)
+	require.NoError(t, err)
+	text, err := block.Json.Text("/text")
+	require.NoError(t, err)
+	assert.Equal(t, "oh, hello world", text)
+
+	// the inverse removes only the characters of the edit
+	inverse, err := api.Invert(s1, tx.ID)
+	require.NoError(t, err)
+	_, err = api.Apply(inverse)
+	require.NoError(t, err)
+	block, err = api.GetBlock(s1, b1)
+	require.NoError(t, err)
+	text, _ = block.Json.Text("/text")
+	assert.Equal(t, "oh, hello", text)
+
+	_, err = api.Apply(createTx(s1, Op{Table: "block", Type: OpTypeText, BlockID: b1, Patch: []byte(`{"path":"/text","edits":[{"delete":["9@z"]}]}`)}))
+	assert.ErrorIs(t, err, ErrInvalidOp)
+}
+
+func TestOpFromProtoV1_Text(t *testing.T) {
+	patch := `{"path":"/text","edits":[{"id":"1@a","insert":"x"}]}`
+	op, err := OpFromProtoV1(&v1.Op{Table: "block", BlockId: b1.String(), Type: v1.OpType_OP_TYPE_TEXT, Patch: &patch})
+	require.NoError(t, err)
+	assert.Equal(t, OpTypeText, op.Type)
+	assert.Equal(t, []byte(patch), op.Patch)
+}
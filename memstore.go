@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -25,6 +26,8 @@ type spaceStore struct {
 	props     map[BlockID][]byte
 	backLinks map[BlockID]Set[BlockID]
 	txs       []*Transaction
+	// snapshot is the snapshot of the first transaction in txs after a compaction
+	snapshot *Snapshot
 }
 
 func newSpaceStore() *spaceStore {
@@ -57,7 +60,10 @@ func (ss *spaceStore) equals(other *spaceStore) bool {
 			return false
 		}
 
-		if !reflect.DeepEqual(block, otherBlock) {
+		// the versions are the seqs of the replica transaction log, they differ between the replicas
+		compared := *otherBlock
+		compared.Version = block.Version
+		if !reflect.DeepEqual(block, &compared) {
 			return false
 		}
 
@@ -69,8 +75,11 @@ func (ss *spaceStore) equals(other *spaceStore) bool {
 	return true
 }
 
+// AddBlock adds a copy of the block, the children tree holds the same copy as the blocks map
+// so the props and json set on the stored block are seen by the children queries.
 func (ss *spaceStore) AddBlock(block *Block) {
-	ss.blocks[block.ID] = block.Clone()
+	block = block.Clone()
+	ss.blocks[block.ID] = block
 	ss.parents[block.ID] = block.ParentID
 	children, ok := ss.children[block.ParentID]
 	if !ok {
@@ -104,20 +113,120 @@ func (ss *spaceStore) RemoveBlock(id BlockID) {
 }
 
 // MemStore is a blocktree store that stores everything in memory.
+// the reads can run next to an apply, e.g. the subscriptions read the log while the transactions are applied.
 type MemStore struct {
+	mu         sync.RWMutex
 	spaces     map[SpaceID]*spaceStore
 	blockSpace map[BlockID]SpaceID
+	// journals keep the spaces as they were before the writes of the running Atomic functions
+	journals map[SpaceID]*memJournal
 }
 
 func NewMemStore() *MemStore {
 	return &MemStore{
 		spaces:     make(map[SpaceID]*spaceStore),
 		blockSpace: make(map[BlockID]SpaceID),
+		journals:   make(map[SpaceID]*memJournal),
 	}
 }
 
+// memJournal keeps the blocks and back links of a space as they were before their first write,
+// nil for the ones that did not exist. the log is cut back to its length.
+type memJournal struct {
+	blocks    map[BlockID]*Block
+	backLinks map[BlockID]*Set[BlockID]
+	txs       int
+}
+
+func (j *memJournal) recordBlock(space *spaceStore, id BlockID) {
+	if _, ok := j.blocks[id]; ok {
+		return
+	}
+
+	if block, ok := space.blocks[id]; ok {
+		j.blocks[id] = block.Clone()
+	} else {
+		j.blocks[id] = nil
+	}
+}
+
+func (j *memJournal) recordBackLinks(space *spaceStore, id BlockID) {
+	if _, ok := j.backLinks[id]; ok {
+		return
+	}
+
+	if links, ok := space.backLinks[id]; ok {
+		j.backLinks[id] = NewSet(links.ToSlice()...)
+	} else {
+		j.backLinks[id] = nil
+	}
+}
+
+// rollback puts the recorded blocks, back links and log of the space back
+func (j *memJournal) rollback(ms *MemStore, space *spaceStore) {
+	for id, block := range j.blocks {
+		space.RemoveBlock(id)
+		delete(space.blocks, id)
+		delete(space.props, id)
+		if block == nil {
+			delete(ms.blockSpace, id)
+			continue
+		}
+		space.AddBlock(block)
+	}
+
+	for id, links := range j.backLinks {
+		if links == nil {
+			delete(space.backLinks, id)
+		} else {
+			space.backLinks[id] = *links
+		}
+	}
+
+	space.txs = space.txs[:j.txs]
+}
+
+// Atomic runs fn on the store, the space is put back as it was when fn fails.
+// the readers see the writes of fn before it returns.
+func (ms *MemStore) Atomic(spaceID SpaceID, fn func(store Store) error) error {
+	ms.mu.Lock()
+	space, err := ms.getSpace(&spaceID)
+	if err != nil {
+		ms.mu.Unlock()
+		return err
+	}
+	if _, ok := ms.journals[spaceID]; ok {
+		ms.mu.Unlock()
+		return fn(ms)
+	}
+	journal := &memJournal{
+		blocks:    make(map[BlockID]*Block),
+		backLinks: make(map[BlockID]*Set[BlockID]),
+		txs:       len(space.txs),
+	}
+	ms.journals[spaceID] = journal
+	ms.mu.Unlock()
+
+	err = fn(ms)
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	delete(ms.journals, spaceID)
+	if err != nil {
+		journal.rollback(ms, space)
+	}
+
+	return err
+}
+
 // Equals compares two MemStore instances.
 func (ms *MemStore) Equals(other *MemStore) bool {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+
 	if len(ms.spaces) != len(other.spaces) {
 		return false
 	}
@@ -136,57 +245,94 @@ func (ms *MemStore) Equals(other *MemStore) bool {
 }
 
 func (ms *MemStore) GetLatestTransaction(spaceID *SpaceID) (*Transaction, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
 	space, ok := ms.spaces[*spaceID]
 	if !ok {
-		return nil, fmt.Errorf("space %v not found", *spaceID)
+		return nil, ErrSpaceNotFound{ID: *spaceID}
 	}
 
 	return space.txs[len(space.txs)-1], nil
 }
 
 func (ms *MemStore) GetBlockSpaceID(id *BlockID) (*SpaceID, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
 	spaceID, ok := ms.blockSpace[*id]
 	if !ok {
-		return nil, fmt.Errorf("space id for block is not found, %v", *id)
+		return nil, ErrBlockNotFound{ID: *id}
 	}
 
 	return &spaceID, nil
 }
 
 func (ms *MemStore) GetChildrenBlocks(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	page, err := ms.queryChildrenBlocks(spaceID, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return page.Blocks, nil
+}
+
+// QueryChildrenBlocks ascends the children from the start cursor of the query.
+func (ms *MemStore) QueryChildrenBlocks(spaceID *SpaceID, id BlockID, query *BlockQuery) (*BlockPage, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return ms.queryChildrenBlocks(spaceID, id, query)
+}
+
+func (ms *MemStore) queryChildrenBlocks(spaceID *SpaceID, id BlockID, query *BlockQuery) (*BlockPage, error) {
+	pager, err := newBlockPager(query)
+	if err != nil {
+		return nil, err
+	}
 	space, err := ms.getSpace(spaceID)
 	if err != nil {
 		return nil, err
 	}
 
-	blocks := make([]*Block, 0)
 	children, ok := space.children[id]
 	if !ok {
-		return blocks, nil
+		return pager.page, nil
 	}
 
-	children.Ascend(func(item *Block) bool {
-		if !item.Linked {
-			blocks = append(blocks, item.Clone())
+	visit := func(item *Block) bool {
+		if item.Linked {
+			return true
 		}
-		return true
-	})
+		return pager.add(item.Clone(), BlockCursor("").child(item))
+	}
+	if pivot := BlockCursor("").pivot(pager.query.Start); pivot != nil {
+		children.AscendGreaterOrEqual(pivot, visit)
+	} else {
+		children.Ascend(visit)
+	}
 
-	return blocks, nil
+	return pager.page, nil
 }
 
 func (ms *MemStore) GetChildrenBlockIDs(spaceID *SpaceID, id BlockID) ([]BlockID, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
 	space, ok := ms.spaces[*spaceID]
 	if !ok {
-		return nil, fmt.Errorf("space %v not found", *spaceID)
+		return nil, ErrSpaceNotFound{ID: *spaceID}
 	}
 
+	ids := make([]BlockID, 0)
 	children, ok := space.children[id]
 	if !ok {
-		return nil, fmt.Errorf("block %v not found", id)
+		return ids, nil
 	}
 
-	ids := make([]BlockID, 0)
 	children.Ascend(func(item *Block) bool {
 		ids = append(ids, item.ID)
 		return true
@@ -196,6 +342,9 @@ func (ms *MemStore) GetChildrenBlockIDs(spaceID *SpaceID, id BlockID) ([]BlockID
 }
 
 func (ms *MemStore) GetLinkedBlocks(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
 	space, err := ms.getSpace(spaceID)
 	if err != nil {
 		return nil, err
@@ -218,6 +367,9 @@ func (ms *MemStore) GetLinkedBlocks(spaceID *SpaceID, id BlockID) ([]*Block, err
 }
 
 func (ms *MemStore) GetBackLinks(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
 	space, err := ms.getSpace(spaceID)
 	if err != nil {
 		return nil, err
@@ -227,7 +379,7 @@ func (ms *MemStore) GetBackLinks(spaceID *SpaceID, id BlockID) ([]*Block, error)
 		return []*Block{}, nil
 	} else {
 
-		blocks, err := ms.GetBlocks(spaceID, s.ToSlice())
+		blocks, err := ms.getBlocks(spaceID, s.ToSlice())
 		if err != nil {
 			return nil, err
 		}
@@ -237,65 +389,118 @@ func (ms *MemStore) GetBackLinks(spaceID *SpaceID, id BlockID) ([]*Block, error)
 }
 
 func (ms *MemStore) GetDescendantBlocks(spaceID *SpaceID, id BlockID) ([]*Block, error) {
-	space, err := ms.getSpace(spaceID)
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	page, err := ms.queryDescendantBlocks(spaceID, id, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	blocks := make([]*Block, 0)
-	ms.getDescendantBlocks(space, id, &blocks)
+	return page.Blocks, nil
+}
 
-	return blocks, nil
+// QueryDescendantBlocks walks down the children btrees, the subtrees before the start cursor are skipped
+// and the walk stops once the page is full.
+func (ms *MemStore) QueryDescendantBlocks(spaceID *SpaceID, id BlockID, query *BlockQuery) (*BlockPage, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return ms.queryDescendantBlocks(spaceID, id, query)
 }
 
-func (ms *MemStore) getDescendantBlocks(space *spaceStore, id BlockID, blocks *[]*Block) {
-	if block, ok := space.blocks[id]; ok && block != nil {
-		*blocks = append(*blocks, block.Clone())
+func (ms *MemStore) queryDescendantBlocks(spaceID *SpaceID, id BlockID, query *BlockQuery) (*BlockPage, error) {
+	pager, err := newBlockPager(query)
+	if err != nil {
+		return nil, err
+	}
+	space, err := ms.getSpace(spaceID)
+	if err != nil {
+		return nil, err
+	}
 
-	} else {
-		return
+	block, ok := space.blocks[id]
+	if !ok || block == nil {
+		return nil, ErrBlockNotFound{ID: id}
 	}
+	if pager.query.Start == "" && !pager.add(block.Clone(), "") {
+		return pager.page, nil
+	}
+
+	ms.getDescendantBlocks(space, id, "", 0, pager)
 
+	return pager.page, nil
+}
+
+// getDescendantBlocks adds the descendants of the block at the cursor to the page, it returns false once the page is full
+func (ms *MemStore) getDescendantBlocks(space *spaceStore, id BlockID, cursor BlockCursor, depth int, pager *blockPager) bool {
 	children, ok := space.children[id]
-	if !ok {
-		return
+	if !ok || !pager.expands(depth) {
+		return true
 	}
 
-	children.Ascend(func(item *Block) bool {
+	more := true
+	visit := func(item *Block) bool {
+		child := cursor.child(item)
+		if !child.reaches(pager.query.Start) {
+			return true
+		}
+		if child >= pager.query.Start && !pager.add(item.Clone(), child) {
+			more = false
+			return false
+		}
+
 		// stop at page block, no need to go further
-		if item.Type == "page" {
-			*blocks = append(*blocks, item.Clone())
+		if item.Type == PageBlockType {
 			return true
 		}
 
-		ms.getDescendantBlocks(space, item.ID, blocks)
-		return true
-	})
+		more = ms.getDescendantBlocks(space, item.ID, child, depth+1, pager)
+		return more
+	}
+	if pivot := cursor.pivot(pager.query.Start); pivot != nil {
+		children.AscendGreaterOrEqual(pivot, visit)
+	} else {
+		children.Ascend(visit)
+	}
+
+	return more
 }
 
 func (ms *MemStore) GetParentBlock(spaceID *SpaceID, id BlockID) (*Block, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
 	space, ok := ms.spaces[*spaceID]
 	if !ok {
-		return nil, fmt.Errorf("space %v not found", *spaceID)
+		return nil, ErrSpaceNotFound{ID: *spaceID}
 	}
 
 	parentID, ok := space.parents[id]
 	if !ok {
-		return nil, fmt.Errorf("block %v not found", id)
+		return nil, ErrBlockNotFound{ID: id}
+	}
+
+	parent, ok := space.blocks[parentID]
+	if !ok {
+		return nil, fmt.Errorf("parent: %w", ErrBlockNotFound{ID: parentID})
 	}
 
-	return space.blocks[parentID].Clone(), nil
+	return parent.Clone(), nil
 }
 
 func (ms *MemStore) GetWithFirstChildBlock(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
 	space, ok := ms.spaces[*spaceID]
 	if !ok {
-		return nil, fmt.Errorf("space %v not found", *spaceID)
+		return nil, ErrSpaceNotFound{ID: *spaceID}
 	}
 
 	block, ok := space.blocks[id]
 	if !ok {
-		return nil, fmt.Errorf("block %v not found", id)
+		return nil, ErrBlockNotFound{ID: id}
 	}
 	blocks := []*Block{block.Clone()}
 
@@ -317,14 +522,17 @@ func (ms *MemStore) GetWithFirstChildBlock(spaceID *SpaceID, id BlockID) ([]*Blo
 }
 
 func (ms *MemStore) GetWithLastChildBlock(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
 	space, ok := ms.spaces[*spaceID]
 	if !ok {
-		return nil, fmt.Errorf("space %v not found", *spaceID)
+		return nil, ErrSpaceNotFound{ID: *spaceID}
 	}
 
 	block, ok := space.blocks[id]
 	if !ok {
-		return nil, fmt.Errorf("block %v not found", id)
+		return nil, ErrBlockNotFound{ID: id}
 	}
 	blocks := []*Block{block.Clone()}
 
@@ -346,6 +554,9 @@ func (ms *MemStore) GetWithLastChildBlock(spaceID *SpaceID, id BlockID) ([]*Bloc
 }
 
 func (ms *MemStore) GetParentWithNextBlock(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
 	space, err := ms.getSpace(spaceID)
 	if err != nil {
 		return nil, err
@@ -354,13 +565,13 @@ func (ms *MemStore) GetParentWithNextBlock(spaceID *SpaceID, id BlockID) ([]*Blo
 	blocks := make([]*Block, 0)
 	parent, ok := space.parents[id]
 	if !ok {
-		return nil, fmt.Errorf("parent block not found for: %v", id)
+		return nil, fmt.Errorf("parent block for %v: %w", id, ErrNotFound)
 	}
 	blocks = append(blocks, space.blocks[parent].Clone())
 
 	children, ok := space.children[parent]
 	if !ok {
-		return nil, fmt.Errorf("block siblings not found id: %v", id)
+		return nil, fmt.Errorf("block siblings for %v: %w", id, ErrNotFound)
 	}
 
 	children.AscendGreaterOrEqual(space.blocks[id], func(item *Block) bool {
@@ -369,13 +580,16 @@ func (ms *MemStore) GetParentWithNextBlock(spaceID *SpaceID, id BlockID) ([]*Blo
 	})
 
 	if len(blocks) == 1 {
-		return nil, fmt.Errorf("block not found id: %v", id)
+		return nil, ErrBlockNotFound{ID: id}
 	}
 
 	return blocks, nil
 }
 
 func (ms *MemStore) GetParentWithPrevBlock(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
 	space, err := ms.getSpace(spaceID)
 	if err != nil {
 		return nil, err
@@ -384,13 +598,13 @@ func (ms *MemStore) GetParentWithPrevBlock(spaceID *SpaceID, id BlockID) ([]*Blo
 	blocks := make([]*Block, 0)
 	parent, ok := space.parents[id]
 	if !ok {
-		return nil, fmt.Errorf("parent block not found for: %v", id)
+		return nil, fmt.Errorf("parent block for %v: %w", id, ErrNotFound)
 	}
 	blocks = append(blocks, space.blocks[parent].Clone())
 
 	children, ok := space.children[parent]
 	if !ok {
-		return nil, fmt.Errorf("block siblings not found id: %v", id)
+		return nil, fmt.Errorf("block siblings for %v: %w", id, ErrNotFound)
 	}
 
 	children.DescendLessOrEqual(space.blocks[id], func(item *Block) bool {
@@ -399,22 +613,25 @@ func (ms *MemStore) GetParentWithPrevBlock(spaceID *SpaceID, id BlockID) ([]*Blo
 	})
 
 	if len(blocks) == 1 {
-		return nil, fmt.Errorf("block not found id: %v", id)
+		return nil, ErrBlockNotFound{ID: id}
 	}
 
 	return blocks, nil
 }
 
 func (ms *MemStore) CreateSpace(space *Space) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
 	if _, ok := ms.spaces[space.ID]; ok {
-		return fmt.Errorf("space %v already exists", space.ID)
+		return ErrSpaceExists{ID: space.ID}
 	}
 
 	ms.spaces[space.ID] = newSpaceStore()
 	spaceBlock := NewBlock(space.ID, RootBlockID, "space")
 	spaceBlock.Props = NewJsonDoc([]byte(`{"name": "` + space.Name + `"}`))
 
-	err := ms.CreateBlock(&space.ID, spaceBlock)
+	err := ms.createBlock(&space.ID, spaceBlock)
 	if err != nil {
 		return err
 	}
@@ -423,7 +640,18 @@ func (ms *MemStore) CreateSpace(space *Space) error {
 }
 
 // Apply applies transactional changes to the store.
-func (ms *MemStore) Apply(tx *Transaction, change *storeChange) error {
+func (ms *MemStore) Apply(tx *Transaction, change *storeChange) (*storeChange, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if err := ms.apply(tx, change); err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+func (ms *MemStore) apply(tx *Transaction, change *storeChange) error {
 	if change == nil {
 		return errors.New("cannot apply nil change to store")
 	}
@@ -437,10 +665,25 @@ func (ms *MemStore) Apply(tx *Transaction, change *storeChange) error {
 		ms.spaces[*spaceID] = space
 	}
 
+	// the transaction is already applied
+	if _, err := ms.getTransaction(spaceID, tx.ID); err == nil {
+		return nil
+	}
+
 	if change.blockChange != nil {
 		blockChange := change.blockChange
+		if journal, ok := ms.journals[*spaceID]; ok {
+			for _, blocks := range []*Set[*Block]{blockChange.updated, blockChange.propSet, blockChange.patched} {
+				for _, block := range blocks.ToSlice() {
+					journal.recordBlock(space, block.ID)
+				}
+			}
+			for _, change := range blockChange.linkOps {
+				journal.recordBackLinks(space, change.childID)
+			}
+		}
 		for _, block := range blockChange.inserted.ToSlice() {
-			err := ms.CreateBlock(spaceID, block)
+			err := ms.createBlock(spaceID, block)
 			if err != nil {
 				return err
 			}
@@ -450,7 +693,7 @@ func (ms *MemStore) Apply(tx *Transaction, change *storeChange) error {
 			//logrus.Infof("updating block %v", block)
 			storeBlock, ok := space.blocks[block.ID]
 			if !ok {
-				return fmt.Errorf("move block not found, %v", block.ID)
+				return fmt.Errorf("move block: %w", ErrBlockNotFound{ID: block.ID})
 			}
 			space.RemoveBlock(block.ID)
 
@@ -464,7 +707,7 @@ func (ms *MemStore) Apply(tx *Transaction, change *storeChange) error {
 		for _, block := range blockChange.propSet.ToSlice() {
 			storeBlock, ok := space.blocks[block.ID]
 			if !ok {
-				return fmt.Errorf("prop update block not found, %v", block.ID)
+				return fmt.Errorf("prop update block: %w", ErrBlockNotFound{ID: block.ID})
 			}
 			//logrus.Infof("updating props for block %v", block.Props.String())
 			storeBlock.Props = block.Props
@@ -474,7 +717,7 @@ func (ms *MemStore) Apply(tx *Transaction, change *storeChange) error {
 		for _, block := range blockChange.patched.ToSlice() {
 			storeBlock, ok := space.blocks[block.ID]
 			if !ok {
-				return fmt.Errorf("patch block not found, %v", block.ID)
+				return fmt.Errorf("patch block: %w", ErrBlockNotFound{ID: block.ID})
 			}
 			//logrus.Infof("patching block %v", block.ID)
 			storeBlock.Json = block.Json
@@ -502,13 +745,21 @@ func (ms *MemStore) Apply(tx *Transaction, change *storeChange) error {
 			}
 		}
 
-		err := ms.PutTransaction(spaceID, &Transaction{
+		if tx.unlogged {
+			return nil
+		}
+
+		err := ms.putTransaction(spaceID, &Transaction{
 			ID:      tx.ID,
 			SpaceID: tx.SpaceID,
 			UserID:  tx.UserID,
 			Time:    tx.Time,
+			Clock:   tx.Clock,
+			HLC:     tx.HLC,
+			Jitter:  tx.Jitter,
 			Ops:     tx.Ops,
 			changes: change.intoSyncBlocks(),
+			inverse: change.blockChange.Inverse(),
 		})
 
 		if err != nil {
@@ -530,11 +781,21 @@ func (ms *MemStore) Apply(tx *Transaction, change *storeChange) error {
 }
 
 func (ms *MemStore) CreateBlock(spaceID *SpaceID, block *Block) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	return ms.createBlock(spaceID, block)
+}
+
+func (ms *MemStore) createBlock(spaceID *SpaceID, block *Block) error {
 	space, ok := ms.spaces[*spaceID]
 	if !ok {
 		space = newSpaceStore()
 		ms.spaces[*spaceID] = space
 	}
+	if journal, ok := ms.journals[*spaceID]; ok {
+		journal.recordBlock(space, block.ID)
+	}
 
 	space.AddBlock(block)
 	ms.blockSpace[block.ID] = *spaceID
@@ -543,22 +804,32 @@ func (ms *MemStore) CreateBlock(spaceID *SpaceID, block *Block) error {
 }
 
 func (ms *MemStore) GetBlock(spaceID *SpaceID, id BlockID) (*Block, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
 	space, ok := ms.spaces[*spaceID]
 	if !ok {
-		return nil, fmt.Errorf("space %v not found", *spaceID)
+		return nil, ErrSpaceNotFound{ID: *spaceID}
 	}
 
 	if block, ok := space.blocks[id]; !ok {
-		return nil, fmt.Errorf("block %v not found", id)
+		return nil, ErrBlockNotFound{ID: id}
 	} else {
 		return block.Clone(), nil
 	}
 }
 
 func (ms *MemStore) GetBlocks(spaceID *SpaceID, ids []BlockID) ([]*Block, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return ms.getBlocks(spaceID, ids)
+}
+
+func (ms *MemStore) getBlocks(spaceID *SpaceID, ids []BlockID) ([]*Block, error) {
 	space, ok := ms.spaces[*spaceID]
 	if !ok {
-		return nil, fmt.Errorf("space %v not found", *spaceID)
+		return nil, ErrSpaceNotFound{ID: *spaceID}
 	}
 
 	blocks := make([]*Block, 0, len(ids))
@@ -571,10 +842,13 @@ func (ms *MemStore) GetBlocks(spaceID *SpaceID, ids []BlockID) ([]*Block, error)
 }
 
 func (ms *MemStore) GetAncestorEdges(spaceID *SpaceID, ids []BlockID) ([]blockEdge, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
 	edges := make([]blockEdge, 0)
 	space, ok := ms.spaces[*spaceID]
 	if !ok {
-		return nil, fmt.Errorf("space %v not found", *spaceID)
+		return nil, ErrSpaceNotFound{ID: *spaceID}
 	}
 
 	for _, id := range ids {
@@ -583,7 +857,7 @@ func (ms *MemStore) GetAncestorEdges(spaceID *SpaceID, ids []BlockID) ([]blockEd
 			parent, ok := space.parents[curr]
 
 			if !ok {
-				return nil, fmt.Errorf("non space block %v has no parent", curr)
+				return nil, fmt.Errorf("non space block has no parent: %w", ErrBlockNotFound{ID: curr})
 			}
 
 			if parent == RootBlockID {
@@ -604,25 +878,40 @@ func (ms *MemStore) GetAncestorEdges(spaceID *SpaceID, ids []BlockID) ([]blockEd
 func (ms *MemStore) getSpace(spaceID *SpaceID) (*spaceStore, error) {
 	space, ok := ms.spaces[*spaceID]
 	if !ok {
-		return nil, fmt.Errorf("space not found: %v", *spaceID)
+		return nil, ErrSpaceNotFound{ID: *spaceID}
 	}
 	return space, nil
 }
 
 func (ms *MemStore) GetTransaction(spaceID *SpaceID, id TransactionID) (*Transaction, error) {
-	for _, tx := range ms.spaces[*spaceID].txs {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return ms.getTransaction(spaceID, id)
+}
+
+func (ms *MemStore) getTransaction(spaceID *SpaceID, id TransactionID) (*Transaction, error) {
+	space, err := ms.getSpace(spaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tx := range space.txs {
 		if tx.ID == id {
 			return tx, nil
 		}
 	}
 
-	return nil, fmt.Errorf("transaction not found: %v", id)
+	return nil, ErrTransactionNotFound{ID: id}
 }
 
 func (ms *MemStore) GetNextTransactions(spaceID *SpaceID, id TransactionID, start, limit int) ([]*Transaction, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
 	space, ok := ms.spaces[*spaceID]
 	if !ok {
-		return nil, fmt.Errorf("space not found: %v", *spaceID)
+		return nil, ErrSpaceNotFound{ID: *spaceID}
 	}
 
 	for i, tx := range space.txs {
@@ -637,7 +926,32 @@ func (ms *MemStore) GetNextTransactions(spaceID *SpaceID, id TransactionID, star
 	return []*Transaction{}, nil
 }
 
+// GetTransactionsSince returns at most limit transactions with a seq after the given seq, in seq order.
+func (ms *MemStore) GetTransactionsSince(spaceID *SpaceID, seq uint64, limit int) ([]*Transaction, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	space, ok := ms.spaces[*spaceID]
+	if !ok {
+		return nil, ErrSpaceNotFound{ID: *spaceID}
+	}
+
+	start := sort.Search(len(space.txs), func(i int) bool {
+		return space.txs[i].Seq > seq
+	})
+	end := min(len(space.txs), start+limit)
+
+	return space.txs[start:end], nil
+}
+
 func (ms *MemStore) PutTransaction(spaceID *SpaceID, tx *Transaction) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	return ms.putTransaction(spaceID, tx)
+}
+
+func (ms *MemStore) putTransaction(spaceID *SpaceID, tx *Transaction) error {
 	//logrus.Infof("putting transaction %v", tx.ID)
 	space, ok := ms.spaces[*spaceID]
 	if !ok {
@@ -645,14 +959,61 @@ func (ms *MemStore) PutTransaction(spaceID *SpaceID, tx *Transaction) error {
 		ms.spaces[*spaceID] = space
 	}
 
+	// transactions are kept in the order they are applied
+	tx.Seq = space.txs[len(space.txs)-1].Seq + 1
 	space.txs = append(space.txs, tx)
-	sort.Slice(space.txs, func(i, j int) bool {
-		return space.txs[i].Time.After(space.txs[j].Time)
-	})
+
+	// the changed blocks take the seq of the transaction as their version
+	journal := ms.journals[*spaceID]
+	for _, id := range tx.changedBlocks() {
+		if block, ok := space.blocks[id]; ok {
+			if journal != nil {
+				journal.recordBlock(space, id)
+			}
+			block.Version = tx.Seq
+		}
+	}
+
 	return nil
 }
 
+func (ms *MemStore) GetSnapshot(spaceID *SpaceID) (*Snapshot, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	space, ok := ms.spaces[*spaceID]
+	if !ok {
+		return nil, ErrSpaceNotFound{ID: *spaceID}
+	}
+
+	return space.snapshot, nil
+}
+
+func (ms *MemStore) PutSnapshot(snapshot *Snapshot) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	space, ok := ms.spaces[snapshot.SpaceID]
+	if !ok {
+		return ErrSpaceNotFound{ID: snapshot.SpaceID}
+	}
+
+	for i, tx := range space.txs {
+		if tx.ID == snapshot.TransactionID {
+			// the snapshot transaction stays as the head of the log
+			space.txs = append([]*Transaction{}, space.txs[i:]...)
+			space.snapshot = snapshot
+			return nil
+		}
+	}
+
+	return ErrTransactionNotFound{ID: snapshot.TransactionID}
+}
+
 func (ms *MemStore) Print(spaceID *SpaceID) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
 	space, ok := ms.spaces[*spaceID]
 	if !ok {
 		logrus.Warnf("space %v not found", *spaceID)
@@ -0,0 +1,493 @@
+package blocktree
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// moveLog orders the moves of the spaces by the clocks of their transactions.
+//
+// the transactions are stamped with a Lamport clock, a transaction without a clock gets the next clock
+// of the space and keeps its arrival order. the moves of a transaction that arrives after moves with a
+// later clock are applied the way the tree move operation of Kleppmann et al. does it:
+//   - the later moves are undone newest first, the moved blocks go back to their exact parent and index
+//   - the transaction is applied
+//   - the later moves are applied again in clock order
+//
+// a move that creates a cycle when it is applied is skipped, it stays in the log and is tried again
+// when an earlier move arrives. the moves that win are the same on every replica no matter in which
+// order the transactions arrive. the other ops are applied in arrival order.
+//
+// a transaction and the moves it undoes and redoes are written in one store transaction under the space
+// lock of the store. the servers that share a store catch up with the moves logged by the others before
+// they apply a transaction.
+type moveLog struct {
+	mu     sync.Mutex
+	spaces map[SpaceID]*spaceMoves
+}
+
+func newMoveLog() *moveLog {
+	return &moveLog{
+		spaces: make(map[SpaceID]*spaceMoves),
+	}
+}
+
+// spaceMoves is the move log of a space, it is locked while a transaction of the space is applied.
+type spaceMoves struct {
+	mu     sync.Mutex
+	loaded bool
+	// clock is the highest clock of the space transactions
+	clock uint64
+	// hlc is the latest hybrid logical clock timestamp of the space transactions
+	hlc HLC
+	// seq is the seq of the latest stored transaction seen by the move log
+	seq uint64
+	// records are the transactions with moves, ordered by clock and transaction id.
+	// the compactions keep them in the snapshot of the space
+	records []*moveRecord
+}
+
+// moveRecord is a transaction with moves in the move log
+type moveRecord struct {
+	clock uint64
+	txID  TransactionID
+	// ops are the move ops of the transaction, the skipped ones included
+	ops []Op
+	// jitter is the jitter of the transaction, the redone moves place the blocks at the same indices
+	jitter []byte
+	// moved holds the places of the moved blocks before the transaction, to undo its moves
+	moved []*Block
+}
+
+// movedPlaces returns the ids, parents and indices of the moved blocks, all an undo needs
+func movedPlaces(moved []*Block) []*Block {
+	places := make([]*Block, 0, len(moved))
+	for _, block := range moved {
+		places = append(places, &Block{ID: block.ID, ParentID: block.ParentID, Index: block.Index})
+	}
+
+	return places
+}
+
+// copyRecords returns copies of the records, the redo of a copy does not change the original
+func copyRecords(records []*moveRecord) []*moveRecord {
+	copied := make([]*moveRecord, 0, len(records))
+	for _, record := range records {
+		r := *record
+		copied = append(copied, &r)
+	}
+
+	return copied
+}
+
+// newMoveRecord returns the record of the applied transaction
+func newMoveRecord(tx *Transaction, change *storeChange) *moveRecord {
+	ops := make([]Op, 0)
+	for _, op := range tx.Ops {
+		if op.Type == OpTypeMove {
+			ops = append(ops, op)
+		}
+	}
+
+	return &moveRecord{
+		clock:  tx.Clock,
+		txID:   tx.ID,
+		ops:    ops,
+		jitter: tx.Jitter,
+		moved:  movedPlaces(change.blockChange.moved),
+	}
+}
+
+// after returns true if the record is ordered after the clock and transaction id
+func (r *moveRecord) after(clock uint64, txID TransactionID) bool {
+	if r.clock != clock {
+		return r.clock > clock
+	}
+
+	return bytes.Compare(r.txID[:], txID[:]) > 0
+}
+
+// redo returns the unlogged transaction that applies the moves of the record again
+func (r *moveRecord) redo(spaceID SpaceID) *Transaction {
+	return &Transaction{
+		ID:       uuid.New(),
+		SpaceID:  spaceID,
+		Time:     time.Now(),
+		Clock:    r.clock,
+		Jitter:   r.jitter,
+		Ops:      r.ops,
+		unlogged: true,
+	}
+}
+
+// space returns the locked move log of the space
+func (ml *moveLog) space(spaceID SpaceID) *spaceMoves {
+	ml.mu.Lock()
+	moves, ok := ml.spaces[spaceID]
+	if !ok {
+		moves = &spaceMoves{}
+		ml.spaces[spaceID] = moves
+	}
+	ml.mu.Unlock()
+
+	moves.mu.Lock()
+	return moves
+}
+
+// init starts the move log of the space with the records at the given seq and clocks
+func (ml *moveLog) init(spaceID SpaceID, seq, clock uint64, hlc HLC, records []*moveRecord) {
+	moves := ml.space(spaceID)
+	defer moves.mu.Unlock()
+
+	moves.loaded = true
+	moves.seq = seq
+	moves.clock = clock
+	moves.hlc = hlc
+	moves.records = copyRecords(records)
+}
+
+// snapshotRecords returns copies of the records of the space for a snapshot
+func (ml *moveLog) snapshotRecords(spaceID SpaceID) []*moveRecord {
+	moves := ml.space(spaceID)
+	defer moves.mu.Unlock()
+
+	return copyRecords(moves.records)
+}
+
+// stamp sets the clocks of the transaction.
+// a transaction without a clock gets the next clock of the space, a transaction without a HLC gets
+// the next timestamp of the space. the clocks of a transaction stamped by another replica are kept.
+func (sm *spaceMoves) stamp(tx *Transaction) {
+	if tx.Clock == 0 {
+		tx.Clock = sm.clock + 1
+	}
+	sm.clock = max(sm.clock, tx.Clock)
+
+	if tx.HLC == 0 {
+		tx.HLC = sm.hlc.next(time.Now())
+	}
+	sm.hlc = max(sm.hlc, tx.HLC)
+}
+
+// later returns the records ordered after the clock and transaction id
+func (sm *spaceMoves) later(clock uint64, txID TransactionID) []*moveRecord {
+	i := sort.Search(len(sm.records), func(i int) bool {
+		return sm.records[i].after(clock, txID)
+	})
+
+	later := make([]*moveRecord, len(sm.records)-i)
+	copy(later, sm.records[i:])
+	return later
+}
+
+// insert adds the record in clock order
+func (sm *spaceMoves) insert(record *moveRecord) {
+	i := sort.Search(len(sm.records), func(i int) bool {
+		return sm.records[i].after(record.clock, record.txID)
+	})
+
+	sm.records = append(sm.records, nil)
+	copy(sm.records[i+1:], sm.records[i:])
+	sm.records[i] = record
+}
+
+// undoMoves returns the unlogged transaction that puts the blocks moved by the records back, newest first.
+// nil is returned when the records moved no block.
+func undoMoves(spaceID SpaceID, records []*moveRecord) *Transaction {
+	ops := make([]Op, 0)
+	for i := len(records) - 1; i >= 0; i-- {
+		for _, block := range records[i].moved {
+			ops = append(ops, Op{
+				Type:    opTypePlace,
+				BlockID: block.ID,
+				At:      &Pointer{BlockID: block.ParentID, Position: PositionInside},
+				Index:   block.Index.Bytes(),
+			})
+		}
+	}
+
+	if len(ops) == 0 {
+		return nil
+	}
+
+	return &Transaction{
+		ID:       uuid.New(),
+		SpaceID:  spaceID,
+		Time:     time.Now(),
+		Ops:      ops,
+		unlogged: true,
+	}
+}
+
+// spaceMoves returns the locked move log of the space, it is loaded from the transaction log on first use.
+func (a *Api) spaceMoves(spaceID SpaceID) (*spaceMoves, error) {
+	moves := a.moves.space(spaceID)
+	if moves.loaded {
+		return moves, nil
+	}
+
+	err := a.loadMoves(spaceID, moves)
+	if err != nil {
+		moves.mu.Unlock()
+		return nil, err
+	}
+
+	return moves, nil
+}
+
+// loadMoves loads the clocks of the space from the transaction log,
+// the log is replayed to rebuild the records when it or the snapshot has moves.
+func (a *Api) loadMoves(spaceID SpaceID, moves *spaceMoves) error {
+	head, snapshot, err := a.logHead(spaceID)
+	if err != nil {
+		return err
+	}
+
+	var seq, clock uint64
+	var hlc HLC
+	if snapshot != nil {
+		seq = snapshot.Seq
+		clock = snapshot.Clock
+		hlc = snapshot.HLC
+	}
+
+	latest := head
+	hasMoves := false
+	err = a.scanTransactions(spaceID, func(tx *Transaction) bool {
+		seq = tx.Seq
+		clock = max(clock, tx.Clock)
+		hlc = max(hlc, tx.HLC)
+		latest = tx.ID
+		hasMoves = hasMoves || tx.moves()
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	moves.records = nil
+	if hasMoves || (snapshot != nil && len(snapshot.moves) > 0) {
+		replayed, _, _, err := a.replay(spaceID, latest)
+		if err != nil {
+			return err
+		}
+
+		replayedMoves := replayed.moves.space(spaceID)
+		moves.records = replayedMoves.records
+		replayedMoves.mu.Unlock()
+	}
+
+	moves.seq = seq
+	moves.clock = clock
+	moves.hlc = hlc
+	moves.loaded = true
+
+	return nil
+}
+
+// applyOrdered applies the transaction in clock order with the later moves undone and applied again.
+// the returned change is the change of the transaction, the sync blocks include the changes of the later moves.
+// the undo, the transaction and the redo are written in one atomic write of the store.
+func (a *Api) applyOrdered(tx *Transaction) (*storeChange, *SyncBlocks, error) {
+	moves, err := a.spaceMoves(tx.SpaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer moves.mu.Unlock()
+
+	var change *storeChange
+	var changes *SyncBlocks
+	applied := false
+	err = a.store.Atomic(tx.SpaceID, func(store Store) error {
+		var err error
+		change, changes, err = a.withStore(store).applyMoves(moves, tx)
+		applied = err == nil
+		return err
+	})
+	if err != nil {
+		// the records of the moves that are not stored are dropped with a reload
+		if applied {
+			moves.loaded = false
+		}
+		return nil, nil, err
+	}
+
+	return change, changes, nil
+}
+
+// applyMoves applies the transaction with the locked move log of the space, the move log is changed
+// only once every write succeeded.
+func (a *Api) applyMoves(moves *spaceMoves, tx *Transaction) (*storeChange, *SyncBlocks, error) {
+	// the already applied transaction is not stamped again
+	if _, err := a.store.GetTransaction(&tx.SpaceID, tx.ID); err == nil {
+		change, err := tx.prepare(a.store)
+		if err != nil {
+			return nil, nil, err
+		}
+		return change, change.intoSyncBlocks(), nil
+	}
+
+	err := a.catchUp(tx.SpaceID, moves)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = a.checkPrecondition(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stamped := *tx
+	moves.stamp(&stamped)
+	if a.jitter {
+		stamped.stampJitter()
+	}
+
+	// the record keeps the skipped moves too
+	hasMoves := stamped.moves()
+	later := moves.later(stamped.Clock, stamped.ID)
+	if !hasMoves || len(later) == 0 {
+		change, err := a.applyStep(&stamped)
+		if err != nil {
+			return nil, nil, err
+		}
+		if hasMoves {
+			moves.insert(newMoveRecord(&stamped, change))
+		}
+		return change, change.intoSyncBlocks(), a.seen(tx.SpaceID, moves)
+	}
+
+	changes := NewSyncBlocks()
+	undo := undoMoves(stamped.SpaceID, later)
+	if undo != nil {
+		undoChange, err := a.applyStep(undo)
+		if err != nil {
+			return nil, nil, err
+		}
+		changes.extend(undoChange.intoSyncBlocks())
+	}
+
+	// the transaction is logged once the later moves are applied again
+	applied := stamped
+	applied.unlogged = true
+	change, err := a.applyStep(&applied)
+	if err != nil {
+		return nil, nil, err
+	}
+	changes.extend(change.intoSyncBlocks())
+
+	moved := make([][]*Block, len(later))
+	for i, record := range later {
+		redoChange, err := a.applyStep(record.redo(stamped.SpaceID))
+		if err != nil {
+			return nil, nil, err
+		}
+		moved[i] = movedPlaces(redoChange.blockChange.moved)
+		changes.extend(redoChange.intoSyncBlocks())
+	}
+
+	err = a.store.PutTransaction(&stamped.SpaceID, &Transaction{
+		ID:      stamped.ID,
+		SpaceID: stamped.SpaceID,
+		UserID:  stamped.UserID,
+		Time:    stamped.Time,
+		Clock:   stamped.Clock,
+		HLC:     stamped.HLC,
+		Jitter:  stamped.Jitter,
+		Ops:     stamped.Ops,
+		changes: changes,
+		inverse: change.blockChange.Inverse(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, record := range later {
+		record.moved = moved[i]
+	}
+	moves.insert(newMoveRecord(&stamped, change))
+
+	return change, changes, a.seen(tx.SpaceID, moves)
+}
+
+// catchUp brings the move log of the space up to the transactions stored by the other servers sharing the store.
+// their clocks are taken, the move log is loaded again when they have moves or compacted the log.
+func (a *Api) catchUp(spaceID SpaceID, moves *spaceMoves) error {
+	latest, err := a.store.GetLatestTransaction(&spaceID)
+	if err != nil {
+		return err
+	}
+	if latest.Seq == moves.seq {
+		return nil
+	}
+
+	_, snapshot, err := a.logHead(spaceID)
+	if err != nil {
+		return err
+	}
+	if snapshot != nil && snapshot.Seq > moves.seq {
+		return a.loadMoves(spaceID, moves)
+	}
+
+	seq := moves.seq
+	for seq < latest.Seq {
+		txs, err := a.store.GetTransactionsSince(&spaceID, seq, replayBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(txs) == 0 {
+			break
+		}
+
+		for _, tx := range txs {
+			if tx.moves() {
+				return a.loadMoves(spaceID, moves)
+			}
+			moves.clock = max(moves.clock, tx.Clock)
+			moves.hlc = max(moves.hlc, tx.HLC)
+		}
+		seq = txs[len(txs)-1].Seq
+	}
+	moves.seq = seq
+
+	return nil
+}
+
+// seen sets the latest stored transaction of the space as the last one seen by the move log
+func (a *Api) seen(spaceID SpaceID, moves *spaceMoves) error {
+	latest, err := a.store.GetLatestTransaction(&spaceID)
+	if err != nil {
+		return err
+	}
+	moves.seq = latest.Seq
+
+	return nil
+}
+
+// withStore returns a copy of the api that reads and writes the store, e.g. the store of an atomic write
+func (a *Api) withStore(store Store) *Api {
+	bound := *a
+	bound.store = store
+	return &bound
+}
+
+// applyStep applies the transaction to the store, the moves that create a cycle are skipped.
+func (a *Api) applyStep(tx *Transaction) (*storeChange, error) {
+	skip, err := tx.cyclicMoves(a.store)
+	if err != nil {
+		return nil, err
+	}
+	tx.skip = skip
+	tx.schemas = a.schemas
+
+	change, err := tx.prepare(a.store)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.store.Apply(tx, change)
+}
@@ -0,0 +1,303 @@
+package blocktree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func clockTx(clock uint64, ops ...Op) *Transaction {
+	tx := createTx(s1, ops...)
+	tx.Clock = clock
+	return tx
+}
+
+func newReplica(t *testing.T, txs ...*Transaction) (*Api, *MemStore) {
+	t.Helper()
+	store := NewMemStore()
+	api := NewApi(store)
+	err := api.CreateSpace(s1, "test-1")
+	require.NoError(t, err)
+
+	for _, tx := range txs {
+		_, err = api.Apply(tx)
+		require.NoError(t, err)
+	}
+
+	return api, store
+}
+
+func TestApi_ConcurrentMoves(t *testing.T) {
+	base := clockTx(1, insertOp(b1, "p1", s1, PositionEnd), insertOp(b2, "p2", s1, PositionEnd))
+	// b1 into b2 and b2 into b1 are concurrent, the earlier clock wins
+	tx1 := clockTx(2, moveOp(b1, s1, b2, PositionEnd))
+	tx2 := clockTx(3, moveOp(b2, s1, b1, PositionEnd))
+
+	api1, store1 := newReplica(t, base, tx1, tx2)
+	api2, store2 := newReplica(t, base, tx2, tx1)
+
+	assert.True(t, store1.Equals(store2))
+	for _, api := range []*Api{api1, api2} {
+		assert.Equal(t, []uuid.UUID{b2}, childIDs(t, api, s1, s1))
+		assert.Equal(t, []uuid.UUID{b1}, childIDs(t, api, s1, b2))
+		assert.Empty(t, childIDs(t, api, s1, b1))
+
+		// the losing transaction is stored with its move skipped
+		tx, err := api.store.GetTransaction(&s1, tx2.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(3), tx.Clock)
+	}
+}
+
+func TestApi_LateMoveAfterCompact(t *testing.T) {
+	base := clockTx(1, insertOp(b1, "p1", s1, PositionEnd), insertOp(b2, "p2", s1, PositionEnd))
+	tx1 := clockTx(2, moveOp(b1, s1, b2, PositionEnd))
+	tx2 := clockTx(3, moveOp(b2, s1, b1, PositionEnd))
+
+	_, want := newReplica(t, base, tx1, tx2)
+
+	// the late move undoes the compacted move of tx2
+	api, store := newReplica(t, base, tx2)
+	snapshot, err := api.Compact(s1)
+	require.NoError(t, err)
+	_, err = api.Apply(tx1)
+	require.NoError(t, err)
+	assert.True(t, store.Equals(want))
+
+	// the move log is loaded from the stored snapshot
+	api, store = newReplica(t, base, tx2)
+	_, err = api.Compact(s1)
+	require.NoError(t, err)
+	_, err = NewApi(store).Apply(tx1)
+	require.NoError(t, err)
+	assert.True(t, store.Equals(want))
+
+	// the records are kept in the stored form of the snapshot
+	data, err := snapshot.MarshalJSON()
+	require.NoError(t, err)
+	var stored Snapshot
+	require.NoError(t, stored.UnmarshalJSON(data))
+	require.Len(t, stored.moves, 1)
+	assert.Equal(t, tx2.ID, stored.moves[0].txID)
+	assert.Equal(t, uint64(3), stored.moves[0].clock)
+	assert.Equal(t, snapshot.moves[0].moved, stored.moves[0].moved)
+}
+
+func TestApi_MoveClock(t *testing.T) {
+	api, store := newReplica(t, clockTx(5, insertOp(b1, "p1", s1, PositionEnd)))
+
+	// the transactions without a clock get the next clock of the space
+	tx := createTx(s1, insertOp(b2, "p2", s1, PositionEnd))
+	_, err := api.Apply(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), tx.Clock)
+
+	stored, err := store.GetTransaction(&s1, tx.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), stored.Clock)
+
+	snapshot, err := api.Snapshot(s1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), snapshot.Clock)
+}
+
+func TestApi_LateMoveUndoesLaterMoves(t *testing.T) {
+	base := clockTx(1,
+		insertOp(b1, "p1", s1, PositionEnd),
+		insertOp(b2, "p2", s1, PositionEnd),
+		insertOp(b3, "p3", s1, PositionEnd),
+	)
+	tx1 := clockTx(2, moveOp(b3, s1, b1, PositionEnd))
+	tx2 := clockTx(3, moveOp(b1, s1, b2, PositionEnd))
+	// b2 into b3 creates a cycle once b3 is in b1 and b1 is in b2
+	tx3 := clockTx(4, moveOp(b2, s1, b3, PositionEnd))
+
+	_, want := newReplica(t, base, tx1, tx2, tx3)
+
+	orders := [][]*Transaction{
+		{tx3, tx2, tx1},
+		{tx2, tx3, tx1},
+		{tx3, tx1, tx2},
+		{tx1, tx3, tx2},
+	}
+	for _, order := range orders {
+		api, store := newReplica(t, append([]*Transaction{base}, order...)...)
+		assert.True(t, store.Equals(want))
+		assert.Equal(t, []uuid.UUID{b2}, childIDs(t, api, s1, s1))
+		assert.Equal(t, []uuid.UUID{b1}, childIDs(t, api, s1, b2))
+		assert.Equal(t, []uuid.UUID{b3}, childIDs(t, api, s1, b1))
+	}
+}
+
+func TestApi_ReplayMoves(t *testing.T) {
+	base := clockTx(1,
+		insertOp(b1, "p1", s1, PositionEnd),
+		insertOp(b2, "p2", s1, PositionEnd),
+		insertOp(b3, "p3", s1, PositionEnd),
+	)
+	tx1 := clockTx(2, moveOp(b1, s1, b2, PositionEnd))
+	tx2 := clockTx(3, moveOp(b2, s1, b3, PositionEnd))
+	tx3 := clockTx(4, moveOp(b3, s1, b1, PositionEnd))
+
+	_, want := newReplica(t, base, tx1, tx2, tx3)
+	_, store := newReplica(t, base, tx3, tx2)
+
+	// a new api over the same store loads the move log by replaying the transaction log
+	api := NewApi(store)
+	_, err := api.Apply(tx1)
+	assert.NoError(t, err)
+	assert.True(t, store.Equals(want))
+
+	snapshot, err := api.SnapshotAt(s1, tx1.ID)
+	assert.NoError(t, err)
+	wantSnapshot, err := NewApi(want).Snapshot(s1)
+	assert.NoError(t, err)
+	assert.Equal(t, blockIDs(wantSnapshot.Blocks), blockIDs(snapshot.Blocks))
+}
+
+func TestApi_SharedStoreMoves(t *testing.T) {
+	base := clockTx(1,
+		insertOp(b1, "p1", s1, PositionEnd),
+		insertOp(b2, "p2", s1, PositionEnd),
+		insertOp(b3, "p3", s1, PositionEnd),
+	)
+	tx1 := clockTx(2, moveOp(b3, s1, b1, PositionEnd))
+	tx2 := clockTx(3, moveOp(b1, s1, b2, PositionEnd))
+	tx3 := clockTx(4, moveOp(b2, s1, b3, PositionEnd))
+
+	_, want := newReplica(t, base, tx1, tx2, tx3)
+
+	// two servers share the store, each sees the moves stored by the other
+	server1, store := newReplica(t, base)
+	server2 := NewApi(store)
+	for i, tx := range []*Transaction{tx3, tx2, tx1} {
+		_, err := []*Api{server1, server2}[i%2].Apply(tx)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []uuid.UUID{b2}, childIDs(t, server1, s1, s1))
+	assert.Equal(t, []uuid.UUID{b1}, childIDs(t, server1, s1, b2))
+	assert.Equal(t, []uuid.UUID{b3}, childIDs(t, server1, s1, b1))
+	assert.True(t, store.Equals(want))
+
+	// the clock of a server follows the transactions of the other
+	tx := createTx(s1, insertOp(b4, "p4", s1, PositionEnd))
+	_, err := server2.Apply(tx)
+	require.NoError(t, err)
+	stored, err := store.GetTransaction(&s1, tx.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), stored.Clock)
+}
+
+func TestApi_ReorderRollback(t *testing.T) {
+	base := clockTx(1,
+		insertOp(b1, "p1", s1, PositionEnd),
+		insertOp(b2, "p2", s1, PositionEnd),
+	)
+	api, store := newReplica(t, base, clockTx(3, moveOp(b2, s1, b1, PositionEnd)))
+	latest, err := store.GetLatestTransaction(&s1)
+	require.NoError(t, err)
+
+	// the late move fails after the later move is undone, the undo is rolled back with it
+	_, err = api.Apply(clockTx(2, moveOp(b1, s1, b2, PositionEnd), insertOp(b1, "p1", s1, PositionEnd)))
+	assert.Error(t, err)
+	assert.Equal(t, []uuid.UUID{b1}, childIDs(t, api, s1, s1))
+	assert.Equal(t, []uuid.UUID{b2}, childIDs(t, api, s1, b1))
+	failed, err := store.GetLatestTransaction(&s1)
+	require.NoError(t, err)
+	assert.Equal(t, latest.ID, failed.ID)
+
+	// the move log is untouched, a later move is applied on top of the stored ones
+	_, err = api.Apply(clockTx(4, moveOp(b2, b1, s1, PositionStart)))
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{b2, b1}, childIDs(t, api, s1, s1))
+}
+
+func TestApi_JitteredMoves(t *testing.T) {
+	base := clockTx(1,
+		insertOp(b1, "p1", s1, PositionEnd),
+		insertOp(b2, "p2", s1, PositionEnd),
+		insertOp(b3, "p3", s1, PositionEnd),
+		insertOp(b4, "p4", s1, PositionEnd),
+	)
+	tx2 := clockTx(2, moveOp(b3, s1, b1, PositionAfter))
+	tx3 := clockTx(3, moveOp(b4, s1, b1, PositionAfter))
+	newJittered := func(txs ...*Transaction) (*Api, *MemStore) {
+		api, store := newReplica(t)
+		api.JitterIndices(true)
+		for _, tx := range txs {
+			_, err := api.Apply(tx)
+			require.NoError(t, err)
+		}
+		return api, store
+	}
+
+	// the redone move places the block with the jitter of its transaction
+	api, store := newJittered(base, tx3, tx2)
+	_, want := newJittered(base, tx2, tx3)
+	assert.True(t, store.Equals(want))
+	assert.Equal(t, []uuid.UUID{b1, b4, b3, b2}, childIDs(t, api, s1, s1))
+
+	// a replica that does not jitter applies the logged transactions with their jitter
+	txs, err := store.GetTransactionsSince(&s1, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, txs, 3)
+	assert.Equal(t, tx3.ID[len(tx3.ID)-jitterLength:], txs[1].Jitter)
+	_, replica := newReplica(t, txs...)
+	assert.True(t, replica.Equals(store))
+
+	_, replayed, _, err := api.replay(s1, txs[2].ID)
+	require.NoError(t, err)
+	assert.True(t, replayed.Equals(store))
+}
+
+// TestApi_MovesConverge applies random concurrent moves in random orders, every replica gets the same tree.
+func TestApi_MovesConverge(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	blocks := []uuid.UUID{b1, b2, b3, b4, b5, b6, b7}
+	positions := []PointerPosition{PositionStart, PositionEnd, PositionBefore, PositionAfter}
+
+	for round := 0; round < 50; round++ {
+		base := clockTx(1)
+		for _, id := range blocks {
+			base.Ops = append(base.Ops, insertOp(id, "p", s1, PositionEnd))
+		}
+
+		txs := make([]*Transaction, 0)
+		for i := 0; i < 12; i++ {
+			tx := clockTx(uint64(2 + rng.Intn(6)))
+			for n := rng.Intn(2); n >= 0; n-- {
+				block := blocks[rng.Intn(len(blocks))]
+				position := positions[rng.Intn(len(positions))]
+				at := s1
+				if position == PositionBefore || position == PositionAfter || rng.Intn(3) > 0 {
+					at = blocks[rng.Intn(len(blocks))]
+				}
+				if at == block {
+					continue
+				}
+				tx.Ops = append(tx.Ops, moveOp(block, s1, at, position))
+			}
+			if len(tx.Ops) > 0 {
+				txs = append(txs, tx)
+			}
+		}
+
+		_, want := newReplica(t, append([]*Transaction{base}, txs...)...)
+		for i := 0; i < 4; i++ {
+			order := make([]*Transaction, len(txs))
+			copy(order, txs)
+			rng.Shuffle(len(order), func(i, j int) {
+				order[i], order[j] = order[j], order[i]
+			})
+
+			_, store := newReplica(t, append([]*Transaction{base}, order...)...)
+			if !assert.True(t, store.Equals(want), "round %d diverged", round) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package blocktree
+
+// PageBlockType is the type of the blocks that start a page, the descendants of a page stop at its nested pages.
+const PageBlockType = "page"
+
+// Page is a block with its content down to, but not into, the nested pages.
+type Page struct {
+	// View is the page as a tree with the sub-pages as leaves
+	View *BlockView
+	// Blocks are the page block and its content in tree order, the sub-pages are left out
+	Blocks []*Block
+	// SubPages are the stubs of the nested pages in tree order, without their json content
+	SubPages []*Block
+}
+
+// GetPage returns the page of the block, usually a page or the space block.
+func (a *Api) GetPage(spaceID SpaceID, pageID BlockID) (*Page, error) {
+	blocks, err := a.store.GetDescendantBlocks(&spaceID, pageID)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) == 0 {
+		return nil, ErrBlockNotFound{ID: pageID}
+	}
+
+	// the stores return the nested pages without their descendants
+	for i, block := range blocks {
+		if block.ID != pageID && block.Type == PageBlockType {
+			blocks[i] = pageStub(block)
+		}
+	}
+
+	view, err := blockViewFromBlocks(pageID, blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{
+		View:     view,
+		Blocks:   make([]*Block, 0, len(blocks)),
+		SubPages: make([]*Block, 0),
+	}
+	byID := make(map[BlockID]*Block, len(blocks))
+	for _, block := range blocks {
+		byID[block.ID] = block
+	}
+
+	var walk func(view *BlockView)
+	walk = func(view *BlockView) {
+		block := byID[view.ID]
+		if view.ID != pageID && view.Type == PageBlockType {
+			page.SubPages = append(page.SubPages, block)
+			return
+		}
+
+		page.Blocks = append(page.Blocks, block)
+		for _, child := range view.Children {
+			walk(child)
+		}
+		for _, link := range view.Linked {
+			walk(link)
+		}
+	}
+	walk(view)
+
+	return page, nil
+}
+
+// pageStub returns the sub-page block without its json content, the props keep the title of the page
+func pageStub(block *Block) *Block {
+	stub := block.Clone()
+	stub.Json = nil
+
+	return stub
+}
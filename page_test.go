@@ -0,0 +1,81 @@
+package blocktree
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/emrgen/blocktree/apis/v1"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// createPages creates s1 -> b1 (page) -> [b2 -> b3 (page) -> b4, b5]
+func createPages(t *testing.T) *Api {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	require.NoError(t, err)
+
+	subPage := insertOp(b3, PageBlockType, b2, PositionEnd)
+	subPage.Patch = []byte(`[{"op":"add","path":"/text","value":"sub-page content"}]`)
+	_, err = api.Apply(createTx(s1,
+		insertOp(b1, PageBlockType, s1, PositionEnd),
+		insertOp(b2, "para", b1, PositionEnd),
+		subPage,
+		insertOp(b4, "para", b3, PositionEnd),
+		insertOp(b5, "para", b1, PositionEnd),
+	))
+	require.NoError(t, err)
+
+	return api
+}
+
+func TestApi_GetPage(t *testing.T) {
+	api := createPages(t)
+
+	page, err := api.GetPage(s1, b1)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{b1, b2, b5}, blockIDs(page.Blocks))
+	assert.Equal(t, []uuid.UUID{b3}, blockIDs(page.SubPages))
+	assert.Nil(t, page.SubPages[0].Json, "the sub-page is a stub")
+
+	// the sub-page is a leaf of the page tree
+	require.Len(t, page.View.Children, 2)
+	assert.Equal(t, b2, page.View.Children[0].ID)
+	require.Len(t, page.View.Children[0].Children, 1)
+	assert.Equal(t, b3, page.View.Children[0].Children[0].ID)
+	assert.Empty(t, page.View.Children[0].Children[0].Children)
+
+	page, err = api.GetPage(s1, b3)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{b3, b4}, blockIDs(page.Blocks))
+	assert.Empty(t, page.SubPages)
+	assert.JSONEq(t, `{"text": "sub-page content"}`, page.Blocks[0].Json.String())
+
+	// the space is the root page
+	page, err = api.GetPage(s1, s1)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{s1}, blockIDs(page.Blocks))
+	assert.Equal(t, []uuid.UUID{b1}, blockIDs(page.SubPages))
+
+	_, err = api.GetPage(s1, b9)
+	assert.Equal(t, ErrBlockNotFound{ID: b9}, err)
+}
+
+func TestGrpcApi_GetPage(t *testing.T) {
+	server := newGrpcApi(createPages(t))
+
+	res, err := server.GetPage(context.Background(), &v1.GetBlockPageRequest{BlockId: b1.String()})
+	require.NoError(t, err)
+	assert.Equal(t, []string{b1.String(), b2.String(), b5.String()}, protoBlockIDs(res.Blocks))
+	require.Len(t, res.SubPages, 1)
+	assert.Equal(t, b3.String(), res.SubPages[0].BlockId)
+	assert.Nil(t, res.SubPages[0].Json)
+	assert.Equal(t, b1.String(), res.Block.BlockId)
+	assert.Len(t, res.Block.Children, 2)
+
+	_, err = server.GetPage(context.Background(), &v1.GetBlockPageRequest{BlockId: b9.String()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,233 @@
+//go:build postgres
+
+package blocktree
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var (
+	_ Store = (*PostgresStore)(nil)
+)
+
+// PostgresStore is a blocktree store backed by PostgreSQL.
+// multiple servers can share the database, Apply serializes the transactions of a space with an advisory lock.
+// the store is built with the postgres build tag, its tests run against the database in BLOCKTREE_TEST_POSTGRES.
+type PostgresStore struct {
+	GormStore
+}
+
+// NewPostgresStore connects to the database described by config and migrates the tables.
+func NewPostgresStore(config *DbConfig) (*PostgresStore, error) {
+	db, err := gorm.Open(postgres.Open(config.PostgresDSN()), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	store := &PostgresStore{GormStore: GormStore{db: db}}
+	err = store.Migrate()
+	if err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Close closes the underlying database connections.
+func (p *PostgresStore) Close() error {
+	sqlDB, err := p.db.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.Close()
+}
+
+// Apply applies the transaction while holding the space advisory lock.
+// the change is prepared again under the lock, another server may have changed
+// the space after the given change was staged. the change prepared under the lock is returned.
+func (p *PostgresStore) Apply(tx *Transaction, change *storeChange) (*storeChange, error) {
+	if change == nil {
+		return nil, errors.New("cannot apply nil change to store")
+	}
+
+	var applied *storeChange
+	err := p.db.Transaction(func(db *gorm.DB) error {
+		err := db.Exec("SELECT pg_advisory_xact_lock(?)", spaceLockKey(tx.SpaceID)).Error
+		if err != nil {
+			return err
+		}
+
+		locked := &PostgresStore{GormStore: GormStore{db: db}}
+		applied, err = tx.prepare(locked)
+		if err != nil {
+			return err
+		}
+
+		return locked.apply(tx, applied)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// Atomic runs fn with a store bound to a single database transaction that holds the space advisory lock,
+// the writes of fn are committed together and the other servers wait for them.
+func (p *PostgresStore) Atomic(spaceID SpaceID, fn func(store Store) error) error {
+	return p.db.Transaction(func(db *gorm.DB) error {
+		err := db.Exec("SELECT pg_advisory_xact_lock(?)", spaceLockKey(spaceID)).Error
+		if err != nil {
+			return err
+		}
+
+		return fn(&PostgresStore{GormStore: GormStore{db: db}})
+	})
+}
+
+// spaceLockKey maps a space id to the advisory lock key of the space.
+func spaceLockKey(spaceID SpaceID) int64 {
+	return int64(binary.BigEndian.Uint64(spaceID[:8]) ^ binary.BigEndian.Uint64(spaceID[8:]))
+}
+
+// pgDescendantsQuery walks down from a block, the page blocks are returned but not expanded.
+// the path is compared with the C collation to keep the index byte order.
+// the path is the cursor of the block, the blocks before the start cursor are not expanded
+// unless the start cursor is below them. a null limit is no limit.
+const pgDescendantsQuery = `
+WITH RECURSIVE descendants(id, type, path, depth) AS (
+	SELECT id, type, ''::text, 0 FROM blocks WHERE space_id = @space AND id = @id
+	UNION ALL
+	SELECT b.id, b.type, d.path || b.frac_index || '.' || b.id::text || '/', d.depth + 1
+	FROM blocks b JOIN descendants d ON b.parent_id = d.id
+	WHERE b.space_id = @space AND (d.type != 'page' OR d.id = @id)
+		AND (@max_depth <= 0 OR d.depth < @max_depth)
+		AND d.path COLLATE "C" >= left(@start::text, length(d.path))
+)
+SELECT blocks.*, descendants.path FROM blocks JOIN descendants ON blocks.id = descendants.id
+WHERE descendants.path COLLATE "C" >= @start::text
+ORDER BY descendants.path COLLATE "C"
+LIMIT @limit`
+
+func (p *PostgresStore) GetDescendantBlocks(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	page, err := p.QueryDescendantBlocks(spaceID, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return page.Blocks, nil
+}
+
+// QueryDescendantBlocks runs the descendants query with the depth, the start cursor and the limit of the query.
+func (p *PostgresStore) QueryDescendantBlocks(spaceID *SpaceID, id BlockID, query *BlockQuery) (*BlockPage, error) {
+	pager, err := newBlockPager(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var limit interface{}
+	if fetch := pager.fetch(); fetch > 0 {
+		limit = fetch
+	}
+
+	return p.findDescendantsPage(spaceID, id, p.db.Raw(pgDescendantsQuery, map[string]interface{}{
+		"space":     spaceID,
+		"id":        id,
+		"max_depth": pager.query.MaxDepth,
+		"start":     string(pager.query.Start),
+		"limit":     limit,
+	}), pager)
+}
+
+// pgAncestorsQuery walks up from the blocks until the space block is reached.
+const pgAncestorsQuery = `
+WITH RECURSIVE ancestors(id, parent_id) AS (
+	SELECT id, parent_id FROM blocks WHERE space_id = @space AND id IN @ids
+	UNION
+	SELECT b.id, b.parent_id
+	FROM blocks b JOIN ancestors a ON b.id = a.parent_id
+	WHERE b.space_id = @space AND a.parent_id != @space
+)
+SELECT id, parent_id FROM ancestors WHERE parent_id != @root`
+
+func (p *PostgresStore) GetAncestorEdges(spaceID *SpaceID, ids []BlockID) ([]blockEdge, error) {
+	edges := make([]blockEdge, 0)
+	if len(ids) == 0 {
+		return edges, nil
+	}
+
+	var found []BlockID
+	uniqueIDs := NewSet(ids...).ToSlice()
+	err := p.db.Model(&gormBlock{}).
+		Where("space_id = ? AND id IN (?)", spaceID, uniqueIDs).
+		Pluck("id", &found).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(found) != len(uniqueIDs) {
+		stored := NewSet(found...)
+		for _, id := range uniqueIDs {
+			if !stored.Contains(id) {
+				return nil, fmt.Errorf("non space block has no parent: %w", ErrBlockNotFound{ID: id})
+			}
+		}
+	}
+
+	var rows []*gormBlock
+	err = p.db.Raw(pgAncestorsQuery, map[string]interface{}{
+		"space": spaceID,
+		"ids":   uniqueIDs,
+		"root":  RootBlockID,
+	}).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		edges = append(edges, blockEdge{parentID: row.ParentID, childID: row.ID})
+	}
+
+	return edges, nil
+}
+
+// pgParentWithNextQuery returns the parent, the block and the next sibling in that order.
+const pgParentWithNextQuery = `
+WITH target AS (
+	SELECT id, parent_id, frac_index FROM blocks WHERE space_id = @space AND id = @id
+)
+SELECT * FROM (
+	SELECT b.*, 0 AS ord FROM blocks b JOIN target t ON b.id = t.parent_id AND b.space_id = @space
+	UNION ALL
+	SELECT b.*, 1 AS ord FROM blocks b JOIN target t ON b.id = t.id
+	UNION ALL
+	SELECT * FROM (
+		SELECT b.*, 2 AS ord FROM blocks b JOIN target t ON b.parent_id = t.parent_id
+		WHERE b.space_id = @space
+			AND (b.frac_index > t.frac_index OR (b.frac_index = t.frac_index AND b.id > t.id))
+		ORDER BY b.frac_index ASC, b.id ASC
+		LIMIT 1
+	) AS sibling
+) AS family
+ORDER BY ord`
+
+func (p *PostgresStore) GetParentWithNextBlock(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	blocks, err := p.findBlocks(p.db.Raw(pgParentWithNextQuery, map[string]interface{}{
+		"space": spaceID,
+		"id":    id,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(blocks) < 2 || blocks[1].ID != id {
+		return nil, fmt.Errorf("parent block for %v: %w", id, ErrNotFound)
+	}
+
+	return blocks, nil
+}
@@ -0,0 +1,138 @@
+package blocktree
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrConflict is returned when the precondition of a transaction does not hold, errors.As gives the ConflictError.
+var ErrConflict = fmt.Errorf("transaction conflicts with the space")
+
+// Precondition is the state of the space a transaction is based on, the transaction is applied only while it holds.
+// the version of a block is the seq of the last transaction that changed the block or its children.
+type Precondition struct {
+	// Seq is the seq of the space the transaction is based on, nil skips the check.
+	// the transaction conflicts with the later transactions that changed a block.
+	Seq *uint64
+	// BlockID limits the Seq check to the descendants of the block, uuid.Nil checks the whole space
+	BlockID BlockID
+	// Versions are the versions of the blocks the transaction is based on
+	Versions map[BlockID]uint64
+}
+
+// ConflictError lists the blocks changed after the precondition of a transaction.
+type ConflictError struct {
+	TransactionID TransactionID
+	// Seq is the seq of the latest transaction of the space
+	Seq uint64
+	// BlockIDs are the changed blocks, ordered by id. the space is listed when the whole space changed
+	BlockIDs []BlockID
+}
+
+func (e *ConflictError) Error() string {
+	ids := make([]string, 0, len(e.BlockIDs))
+	for _, id := range e.BlockIDs {
+		ids = append(ids, id.String())
+	}
+
+	return fmt.Sprintf("%v: transaction %v, space at seq %d, changed blocks [%s]", ErrConflict, e.TransactionID, e.Seq, strings.Join(ids, ", "))
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// GetBlockVersions returns the versions of the blocks.
+func (a *Api) GetBlockVersions(spaceID SpaceID, blockIDs ...BlockID) (map[BlockID]uint64, error) {
+	versions := make(map[BlockID]uint64, len(blockIDs))
+	for _, id := range blockIDs {
+		block, err := a.store.GetBlock(&spaceID, id)
+		if err != nil {
+			return nil, err
+		}
+		versions[id] = block.Version
+	}
+
+	return versions, nil
+}
+
+// checkPrecondition returns a ConflictError when a block changed after the precondition of the transaction.
+// the versions kept with the blocks are compared, the transaction log is not read.
+// a whole space check compares the seq of the latest transaction and reports the space as the changed block.
+func (a *Api) checkPrecondition(tx *Transaction) error {
+	pre := tx.Precondition
+	if pre == nil || (pre.Seq == nil && len(pre.Versions) == 0) {
+		return nil
+	}
+
+	latest, err := a.store.GetLatestTransaction(&tx.SpaceID)
+	if err != nil {
+		return err
+	}
+
+	changed := NewSet[BlockID]()
+	for id, version := range pre.Versions {
+		block, err := a.store.GetBlock(&tx.SpaceID, id)
+		if errors.As(err, &ErrBlockNotFound{}) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if block.Version > version {
+			changed.Add(id)
+		}
+	}
+
+	if pre.Seq != nil && pre.BlockID == uuid.Nil {
+		if latest.Seq > *pre.Seq {
+			changed.Add(tx.SpaceID)
+		}
+	} else if pre.Seq != nil {
+		// the subtree is taken from the current tree, the blocks moved out of it changed their old parent
+		blocks, err := a.store.GetDescendantBlocks(&tx.SpaceID, pre.BlockID)
+		if err != nil {
+			return err
+		}
+		block, err := a.store.GetBlock(&tx.SpaceID, pre.BlockID)
+		if err != nil {
+			return err
+		}
+
+		for _, block := range append(blocks, block) {
+			if block.Version > *pre.Seq {
+				changed.Add(block.ID)
+			}
+		}
+	}
+	if changed.Size() == 0 {
+		return nil
+	}
+
+	blockIDs := changed.ToSlice()
+	sort.Slice(blockIDs, func(i, j int) bool {
+		return blockIDs[i].String() < blockIDs[j].String()
+	})
+
+	return &ConflictError{
+		TransactionID: tx.ID,
+		Seq:           latest.Seq,
+		BlockIDs:      blockIDs,
+	}
+}
+
+// changedBlocks returns the blocks changed by the transaction, the parents with changed children included
+func (tx *Transaction) changedBlocks() []BlockID {
+	if tx.changes == nil {
+		return nil
+	}
+
+	changed := tx.changes.dirty()
+	changed.Extend(tx.changes.children.ToSlice())
+
+	return changed.ToSlice()
+}
@@ -0,0 +1,122 @@
+package blocktree
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seqOf(seq uint64) *uint64 {
+	return &seq
+}
+
+func TestApi_ApplyPrecondition(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	require.NoError(t, err)
+
+	// s1 -> b1 -> b2, s1 -> b3
+	tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionEnd), insertOp(b2, "p2", b1, PositionEnd), insertOp(b3, "p3", s1, PositionEnd))
+	tx2 := createTx(s1, updateOp(b2, []byte(`[{"op":"add","path":"/name","value":"John Doe"}]`)))
+	_, err = api.Apply(tx1, tx2)
+	require.NoError(t, err)
+
+	// the space changed after seq 1
+	tx3 := createTx(s1, insertOp(b4, "p4", b1, PositionEnd))
+	tx3.Precondition = &Precondition{Seq: seqOf(1)}
+	_, err = api.Apply(tx3)
+	assert.ErrorIs(t, err, ErrConflict)
+	var conflict *ConflictError
+	require.True(t, errors.As(err, &conflict))
+	assert.Equal(t, tx3.ID, conflict.TransactionID)
+	assert.Equal(t, uint64(2), conflict.Seq)
+	assert.Equal(t, []BlockID{s1}, conflict.BlockIDs)
+
+	// the conflicting transaction is not applied
+	_, err = api.GetBlock(s1, b4)
+	assert.Error(t, err)
+
+	// the subtree of b3 did not change
+	tx3.Precondition = &Precondition{Seq: seqOf(1), BlockID: b3}
+	_, err = api.Apply(tx3)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{b2, b4}, childIDs(t, api, s1, b1))
+
+	// the subtree of b1 changed by the insert of b4
+	tx4 := createTx(s1, insertOp(b5, "p5", b1, PositionEnd))
+	tx4.Precondition = &Precondition{Seq: seqOf(2), BlockID: b1}
+	_, err = api.Apply(tx4)
+	require.True(t, errors.As(err, &conflict))
+	assert.Equal(t, []BlockID{b1, b4}, conflict.BlockIDs)
+}
+
+func TestApi_ApplySubPagePrecondition(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	require.NoError(t, err)
+
+	// s1 -> b1 -> b2 (page) -> b3
+	tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionEnd), insertOp(b2, "page", b1, PositionEnd), insertOp(b3, "p3", b2, PositionEnd))
+	tx2 := createTx(s1, updateOp(b3, []byte(`[{"op":"add","path":"/name","value":"John Doe"}]`)))
+	_, err = api.Apply(tx1, tx2)
+	require.NoError(t, err)
+
+	// the change inside the sub page is a change of the space
+	tx3 := createTx(s1, insertOp(b4, "p4", s1, PositionEnd))
+	tx3.Precondition = &Precondition{Seq: seqOf(1)}
+	_, err = api.Apply(tx3)
+	var conflict *ConflictError
+	require.True(t, errors.As(err, &conflict))
+	assert.Equal(t, uint64(2), conflict.Seq)
+	assert.Equal(t, []BlockID{s1}, conflict.BlockIDs)
+
+	tx3.Precondition = &Precondition{Seq: seqOf(2)}
+	_, err = api.Apply(tx3)
+	assert.NoError(t, err)
+}
+
+func TestApi_ApplyBlockVersions(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	require.NoError(t, err)
+
+	tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionEnd), insertOp(b2, "p2", s1, PositionEnd))
+	tx2 := createTx(s1, updateOp(b2, []byte(`[{"op":"add","path":"/name","value":"John Doe"}]`)))
+	_, err = api.Apply(tx1, tx2)
+	require.NoError(t, err)
+
+	versions, err := api.GetBlockVersions(s1, b1, b2)
+	require.NoError(t, err)
+	assert.Equal(t, map[BlockID]uint64{b1: 1, b2: 2}, versions)
+
+	tx3 := createTx(s1, updateOp(b1, []byte(`[{"op":"add","path":"/name","value":"Jane Doe"}]`)))
+	tx3.Precondition = &Precondition{Versions: versions}
+	_, err = api.Apply(tx3)
+	assert.NoError(t, err)
+
+	// b1 changed after the versions
+	tx4 := createTx(s1, updateOp(b1, []byte(`[{"op":"add","path":"/name","value":"John Doe"}]`)))
+	tx4.Precondition = &Precondition{Versions: versions}
+	_, err = api.Apply(tx4)
+	var conflict *ConflictError
+	require.True(t, errors.As(err, &conflict))
+	assert.Equal(t, []BlockID{b1}, conflict.BlockIDs)
+
+	// the versions are kept with the blocks after the log is compacted
+	_, err = api.Compact(s1)
+	require.NoError(t, err)
+	_, err = api.Apply(tx4)
+	require.True(t, errors.As(err, &conflict))
+	assert.Equal(t, []BlockID{b1}, conflict.BlockIDs)
+
+	versions, err = api.GetBlockVersions(s1, b1, b2)
+	require.NoError(t, err)
+	assert.Equal(t, map[BlockID]uint64{b1: 3, b2: 2}, versions)
+
+	block, err := api.GetBlock(s1, b1)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), BlockToProtoV1(block).GetVersion())
+}
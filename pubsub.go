@@ -1,11 +1,21 @@
 package blocktree
 
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
 type PublishSyncBlocks interface {
 	Publish(*SyncBlocks) error
 }
 
 type SubscribeSyncBlocks interface {
-	Subscribe() <-chan *SyncBlocks
+	// Subscribe returns the channel of the space changes and a function to cancel the subscription.
+	Subscribe(spaceID SpaceID) (<-chan *SyncBlocks, func())
 }
 
 type NullPublisher struct {
@@ -18,3 +28,149 @@ func NewNullPublisher() *NullPublisher {
 func (n *NullPublisher) Publish(*SyncBlocks) error {
 	return nil
 }
+
+var (
+	_ PublishSyncBlocks   = (*Broker)(nil)
+	_ SubscribeSyncBlocks = (*Broker)(nil)
+)
+
+// defaultBrokerBufferSize is the number of changes a subscriber can fall behind before it is dropped.
+const defaultBrokerBufferSize = 64
+
+// Broker fans out the published SyncBlocks to the subscribers of the space, in process.
+// a subscriber that falls behind is dropped and its channel is closed, it can resume
+// from the last transaction it has seen.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[SpaceID]map[*brokerSubscriber]struct{}
+	bufferSize  int
+}
+
+type brokerSubscriber struct {
+	ch     chan *SyncBlocks
+	closed bool
+}
+
+// NewBroker creates a new in-process broker.
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[SpaceID]map[*brokerSubscriber]struct{}),
+		bufferSize:  defaultBrokerBufferSize,
+	}
+}
+
+// Publish sends the changes to the subscribers of the changed space, it never blocks.
+func (b *Broker) Publish(sb *SyncBlocks) error {
+	if sb == nil || sb.spaceID == uuid.Nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers[sb.spaceID] {
+		select {
+		case sub.ch <- sb:
+		default:
+			logrus.Warnf("dropping slow subscriber of space %v", sb.spaceID)
+			b.remove(sb.spaceID, sub)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe registers a subscriber for the space changes.
+func (b *Broker) Subscribe(spaceID SpaceID) (<-chan *SyncBlocks, func()) {
+	sub := &brokerSubscriber{ch: make(chan *SyncBlocks, b.bufferSize)}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subs, ok := b.subscribers[spaceID]
+	if !ok {
+		subs = make(map[*brokerSubscriber]struct{})
+		b.subscribers[spaceID] = subs
+	}
+	subs[sub] = struct{}{}
+
+	return sub.ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(spaceID, sub)
+	}
+}
+
+// remove closes the subscriber channel, the caller must hold the lock.
+func (b *Broker) remove(spaceID SpaceID, sub *brokerSubscriber) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.ch)
+
+	subs := b.subscribers[spaceID]
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.subscribers, spaceID)
+	}
+}
+
+var (
+	_ PublishSyncBlocks = (MultiPublisher)(nil)
+)
+
+// MultiPublisher publishes the changes with each of the publishers.
+type MultiPublisher []PublishSyncBlocks
+
+// NewMultiPublisher creates a publisher that publishes with each of the publishers in order.
+func NewMultiPublisher(publishers ...PublishSyncBlocks) MultiPublisher {
+	return publishers
+}
+
+func (m MultiPublisher) Publish(sb *SyncBlocks) error {
+	var errs []error
+	for _, publisher := range m {
+		err := publisher.Publish(sb)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// syncBlocksMessage is the message bus form of the changes of a transaction.
+// the node id lets a server skip its own changes when they come back from the bus.
+type syncBlocksMessage struct {
+	NodeID        uuid.UUID     `json:"node_id"`
+	SpaceID       SpaceID       `json:"space_id"`
+	TransactionID TransactionID `json:"transaction_id"`
+	Changes       *SyncBlocks   `json:"changes"`
+}
+
+func encodeSyncBlocks(nodeID uuid.UUID, sb *SyncBlocks) ([]byte, error) {
+	return json.Marshal(&syncBlocksMessage{
+		NodeID:        nodeID,
+		SpaceID:       sb.spaceID,
+		TransactionID: sb.transactionID,
+		Changes:       sb,
+	})
+}
+
+// decodeSyncBlocks returns the changes in the message and the node that published them.
+func decodeSyncBlocks(data []byte) (uuid.UUID, *SyncBlocks, error) {
+	var msg syncBlocksMessage
+	err := json.Unmarshal(data, &msg)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	if msg.Changes == nil {
+		return uuid.Nil, nil, errors.New("sync blocks message has no changes")
+	}
+
+	msg.Changes.spaceID = msg.SpaceID
+	msg.Changes.transactionID = msg.TransactionID
+
+	return msg.NodeID, msg.Changes, nil
+}
@@ -0,0 +1,102 @@
+package blocktree
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	_ PublishSyncBlocks = (*RedisBus)(nil)
+)
+
+// defaultRedisChannelPrefix is the prefix of the redis channel of a space, the channel is prefix + space id.
+const defaultRedisChannelPrefix = "blocktree:sync:"
+
+// RedisBus shares the space changes between blocktree servers over redis pub/sub.
+// Publish sends the changes of this server to redis, Forward feeds the changes
+// of the other servers into the local broker.
+type RedisBus struct {
+	client redis.UniversalClient
+	prefix string
+	nodeID uuid.UUID
+}
+
+// NewRedisBus creates a redis bus, every server needs its own bus.
+func NewRedisBus(client redis.UniversalClient) *RedisBus {
+	return &RedisBus{
+		client: client,
+		prefix: defaultRedisChannelPrefix,
+		nodeID: uuid.New(),
+	}
+}
+
+// NewRedisBusFromURL connects to the redis server at the url, e.g. redis://localhost:6379/0
+func NewRedisBusFromURL(url string) (*RedisBus, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRedisBus(redis.NewClient(options)), nil
+}
+
+// Publish sends the changes to the channel of the space.
+func (r *RedisBus) Publish(sb *SyncBlocks) error {
+	if sb == nil || sb.spaceID == uuid.Nil {
+		return nil
+	}
+
+	data, err := encodeSyncBlocks(r.nodeID, sb)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Publish(context.Background(), r.prefix+sb.spaceID.String(), data).Err()
+}
+
+// Forward publishes the changes of the other servers to the broker until the context is done.
+// the changes published by this bus are skipped, the local broker has them already.
+func (r *RedisBus) Forward(ctx context.Context, broker PublishSyncBlocks) error {
+	pubsub := r.client.PSubscribe(ctx, r.prefix+"*")
+	defer pubsub.Close()
+
+	// wait for the subscription, the changes published before it are not forwarded
+	_, err := pubsub.Receive(ctx)
+	if err != nil {
+		return err
+	}
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+
+			nodeID, sb, err := decodeSyncBlocks([]byte(msg.Payload))
+			if err != nil {
+				logrus.Errorf("failed to decode sync blocks from %v: %v", msg.Channel, err)
+				continue
+			}
+			if nodeID == r.nodeID {
+				continue
+			}
+
+			err = broker.Publish(sb)
+			if err != nil {
+				logrus.Errorf("failed to forward sync blocks of space %v: %v", sb.spaceID, err)
+			}
+		}
+	}
+}
+
+// Close closes the redis client.
+func (r *RedisBus) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,93 @@
+package blocktree
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncBlocksMessage(t *testing.T) {
+	sb := NewSyncBlocks()
+	sb.spaceID = s1
+	sb.transactionID = b3
+	sb.children.Add(s1)
+	sb.inserted.Add(b1)
+	sb.props.Add(b2)
+
+	nodeID := uuid.New()
+	data, err := encodeSyncBlocks(nodeID, sb)
+	assert.NoError(t, err)
+
+	from, decoded, err := decodeSyncBlocks(data)
+	assert.NoError(t, err)
+	assert.Equal(t, nodeID, from)
+	assert.Equal(t, s1, decoded.spaceID)
+	assert.Equal(t, b3, decoded.transactionID)
+	assert.True(t, decoded.children.Equals(sb.children))
+	assert.True(t, decoded.inserted.Equals(sb.inserted))
+	assert.True(t, decoded.props.Equals(sb.props))
+
+	_, _, err = decodeSyncBlocks([]byte(`{"node_id":"` + nodeID.String() + `"}`))
+	assert.Error(t, err)
+}
+
+func TestRedisBus_Forward(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// two servers share the store and the redis server
+	store := NewMemStore()
+	newServerApi := func() *Api {
+		broker := NewBroker()
+		bus := NewRedisBus(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+		t.Cleanup(func() {
+			_ = bus.Close()
+		})
+		go func() {
+			_ = bus.Forward(ctx, broker)
+		}()
+
+		return NewApiWithPubSub(store, NewMultiPublisher(broker, bus), broker)
+	}
+	api1 := newServerApi()
+	api2 := newServerApi()
+	assert.Eventually(t, func() bool {
+		return mr.PubSubNumPat() == 2
+	}, time.Second, 10*time.Millisecond)
+
+	err := api1.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	updates1, err := api1.Subscribe(ctx, s1, uuid.Nil)
+	assert.NoError(t, err)
+	updates2, err := api2.Subscribe(ctx, s1, uuid.Nil)
+	assert.NoError(t, err)
+
+	tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionEnd))
+	_, err = api1.Apply(tx1)
+	assert.NoError(t, err)
+
+	tx2 := createTx(s1, insertOp(b2, "p2", s1, PositionEnd))
+	_, err = api2.Apply(tx2)
+	assert.NoError(t, err)
+
+	// each server sees both changes once, its own from the broker and the other one from redis
+	for _, updates := range []<-chan *BlockUpdates{updates1, updates2} {
+		ids := make([]TransactionID, 0)
+		for len(ids) < 2 {
+			select {
+			case update := <-updates:
+				ids = append(ids, update.TransactionID)
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for updates")
+			}
+		}
+		assert.ElementsMatch(t, []TransactionID{tx1.ID, tx2.ID}, ids)
+	}
+}
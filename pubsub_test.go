@@ -0,0 +1,47 @@
+package blocktree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroker_PublishToSpace(t *testing.T) {
+	broker := NewBroker()
+	ch1, cancel1 := broker.Subscribe(s1)
+	ch2, cancel2 := broker.Subscribe(s2)
+	defer cancel2()
+
+	sb := NewSyncBlocks()
+	sb.spaceID = s1
+	sb.inserted.Add(b1)
+	assert.NoError(t, broker.Publish(sb))
+
+	assert.Equal(t, sb, <-ch1)
+	assert.Equal(t, 0, len(ch2))
+
+	// cancel closes the channel and can be called again
+	cancel1()
+	cancel1()
+	_, ok := <-ch1
+	assert.False(t, ok)
+	assert.NoError(t, broker.Publish(sb))
+}
+
+func TestBroker_DropSlowSubscriber(t *testing.T) {
+	broker := NewBroker()
+	ch, cancel := broker.Subscribe(s1)
+	defer cancel()
+
+	sb := NewSyncBlocks()
+	sb.spaceID = s1
+	for i := 0; i <= defaultBrokerBufferSize; i++ {
+		assert.NoError(t, broker.Publish(sb))
+	}
+
+	count := 0
+	for range ch {
+		count++
+	}
+	assert.Equal(t, defaultBrokerBufferSize, count)
+}
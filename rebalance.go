@@ -0,0 +1,211 @@
+package blocktree
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// errMovedAway is returned for a reindex move of a block that is no longer a child of the At block, the move is skipped
+var errMovedAway = fmt.Errorf("block moved away from the parent")
+
+// maxTrackedParents bounds the parents an api tracks the index keys of, the parent with the shortest keys
+// is dropped for a new one.
+const maxTrackedParents = 4096
+
+// serveIndexMetrics writes the index metrics of the api in the form of /debug/vars,
+// the other expvars, e.g. the cmdline, are left out
+func (a *Api) serveIndexMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	fmt.Fprintf(w, "{%q: %s}\n", "blocktree_max_index_length", a.rebalancing.metrics.String())
+}
+
+// rebalancing tracks the longest index key placed under each parent since its last rebalance.
+type rebalancing struct {
+	mu sync.Mutex
+	// above is the key length that makes the api rebalance the children of a parent, zero turns it off
+	above   int
+	lengths map[parentKey]int
+	// metrics holds the lengths as "space/parent" keys, it is not published with the other expvars,
+	// the server serves it alone on the metrics listener
+	metrics *expvar.Map
+}
+
+// parentKey identifies a parent block in a space
+type parentKey struct {
+	spaceID  SpaceID
+	parentID BlockID
+}
+
+func (k parentKey) String() string {
+	return k.spaceID.String() + "/" + k.parentID.String()
+}
+
+func newRebalancing() *rebalancing {
+	return &rebalancing{
+		lengths: make(map[parentKey]int),
+		metrics: new(expvar.Map).Init(),
+	}
+}
+
+// placed records the index keys of the inserted and moved blocks and returns the parents due for rebalancing
+func (r *rebalancing) placed(spaceID SpaceID, change *blockChange) []BlockID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	due := NewSet[BlockID]()
+	track := func(block *Block) bool {
+		if block.Index == nil || block.ParentID == uuid.Nil {
+			return true
+		}
+
+		key := parentKey{spaceID: spaceID, parentID: block.ParentID}
+		length := len(block.Index.Bytes())
+		if _, ok := r.lengths[key]; !ok && len(r.lengths) >= maxTrackedParents {
+			r.evict()
+		}
+		if length > r.lengths[key] {
+			r.lengths[key] = length
+			r.metrics.Set(key.String(), intVar(length))
+		}
+		if r.above > 0 && r.lengths[key] > r.above {
+			due.Add(block.ParentID)
+		}
+		return true
+	}
+	change.inserted.ForEach(track)
+	change.updated.ForEach(track)
+
+	return due.ToSlice()
+}
+
+// evict stops tracking the parent with the shortest keys
+func (r *rebalancing) evict() {
+	var shortest parentKey
+	length := -1
+	for key, l := range r.lengths {
+		if length < 0 || l < length {
+			shortest, length = key, l
+		}
+	}
+
+	delete(r.lengths, shortest)
+	r.metrics.Delete(shortest.String())
+}
+
+// rebalanced resets the longest key of the parent to the longest of its new keys
+func (r *rebalancing) rebalanced(spaceID SpaceID, parentID BlockID, length int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := parentKey{spaceID: spaceID, parentID: parentID}
+	r.lengths[key] = length
+	r.metrics.Set(key.String(), intVar(length))
+}
+
+// MaxKeyLength returns the longest index key placed under the parent since it was last rebalanced,
+// zero when the api has not placed a block under the parent.
+func (a *Api) MaxKeyLength(spaceID SpaceID, parentID BlockID) int {
+	a.rebalancing.mu.Lock()
+	defer a.rebalancing.mu.Unlock()
+
+	return a.rebalancing.lengths[parentKey{spaceID: spaceID, parentID: parentID}]
+}
+
+// RebalanceAbove makes the api rebalance the children of a parent once a block is placed under it
+// with an index key longer than n bytes, zero turns it off.
+func (a *Api) RebalanceAbove(n int) {
+	a.rebalancing.mu.Lock()
+	defer a.rebalancing.mu.Unlock()
+
+	a.rebalancing.above = n
+}
+
+// RebalanceChildren spreads the indices of the children of the parent evenly again, the keys grown by
+// inserting at the same spot become short. the linked blocks share the index space with the children
+// and are spread with them.
+//
+// the change is applied as a transaction of reindex moves, the move ops inside the parent with an index,
+// so the replicas apply the same change. a reindex move of a block that was moved to another parent
+// by an earlier move is skipped. the transaction is based on the version of the parent, a concurrent
+// change of the children fails it with a ConflictError. nil is returned when the keys are already spread.
+func (a *Api) RebalanceChildren(spaceID SpaceID, parentID BlockID) (*Transaction, error) {
+	versions, err := a.GetBlockVersions(spaceID, parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := a.store.GetChildrenBlocks(&spaceID, parentID)
+	if err != nil {
+		return nil, err
+	}
+	linked, err := a.store.GetLinkedBlocks(&spaceID, parentID)
+	if err != nil {
+		return nil, err
+	}
+	children = append(children, linked...)
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].Less(children[j])
+	})
+
+	indices := NewSpread(len(children))
+	ops := make([]Op, 0, len(children))
+	longest := 0
+	for i, child := range children {
+		longest = max(longest, len(indices[i].Bytes()))
+		if child.Index.Equals(indices[i]) {
+			continue
+		}
+
+		parent := parentID
+		ops = append(ops, Op{
+			Table:    child.Table,
+			Type:     OpTypeMove,
+			BlockID:  child.ID,
+			ParentID: &parent,
+			At:       &Pointer{BlockID: parentID, Position: PositionInside},
+			Index:    indices[i].Bytes(),
+		})
+	}
+	if len(ops) == 0 {
+		return nil, nil
+	}
+
+	tx := &Transaction{
+		ID:           uuid.New(),
+		SpaceID:      spaceID,
+		Time:         time.Now(),
+		Precondition: &Precondition{Versions: versions},
+		Ops:          ops,
+	}
+	_, err = a.Apply(tx)
+	if err != nil {
+		return nil, err
+	}
+	a.rebalancing.rebalanced(spaceID, parentID, longest)
+
+	return tx, nil
+}
+
+// rebalance rebalances the children of the parents, the failures are logged and the keys stay long
+func (a *Api) rebalance(spaceID SpaceID, parentIDs []BlockID) {
+	for _, parentID := range parentIDs {
+		_, err := a.RebalanceChildren(spaceID, parentID)
+		if err != nil {
+			logrus.Errorf("failed to rebalance the children of %v in space %v: %v", parentID, spaceID, err)
+		}
+	}
+}
+
+// intVar returns an expvar integer with the value
+func intVar(value int) *expvar.Int {
+	v := new(expvar.Int)
+	v.Set(int64(value))
+	return v
+}
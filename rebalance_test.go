@@ -0,0 +1,184 @@
+package blocktree
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/emrgen/blocktree/apis/v1"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// insertAfterFirst inserts n blocks after b1 in s1 one by one, each insert grows the index key.
+// returns the children of s1 in order.
+func insertAfterFirst(t *testing.T, api *Api, n int) []uuid.UUID {
+	_, err := api.Apply(createTx(s1, insertOp(b1, "para", s1, PositionEnd), insertOp(b2, "para", s1, PositionEnd)))
+	require.NoError(t, err)
+
+	order := []uuid.UUID{b1}
+	for i := 0; i < n; i++ {
+		id := uuid.New()
+		_, err := api.Apply(createTx(s1, insertOp(id, "para", b1, PositionAfter)))
+		require.NoError(t, err)
+		order = append([]uuid.UUID{b1, id}, order[1:]...)
+	}
+
+	return append(order, b2)
+}
+
+func TestApi_RebalanceChildren(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	require.NoError(t, err)
+
+	order := insertAfterFirst(t, api, 200)
+	assert.Greater(t, api.MaxKeyLength(s1, s1), 3)
+
+	tx, err := api.RebalanceChildren(s1, s1)
+	require.NoError(t, err)
+	require.NotNil(t, tx)
+	assert.True(t, tx.reindexes())
+
+	assert.Equal(t, order, childIDs(t, api, s1, s1))
+	assert.Equal(t, 3, api.MaxKeyLength(s1, s1))
+	children, err := api.GetChildrenBlocks(s1, s1)
+	require.NoError(t, err)
+	for _, child := range children {
+		assert.Len(t, child.Index.Bytes(), 3)
+	}
+
+	// the spread children are left as they are
+	tx, err = api.RebalanceChildren(s1, s1)
+	require.NoError(t, err)
+	assert.Nil(t, tx)
+
+	// the new keys go between the spread ones
+	_, err = api.Apply(createTx(s1, insertOp(b3, "para", b1, PositionAfter)))
+	require.NoError(t, err)
+	assert.Equal(t, append([]uuid.UUID{b1, b3}, order[1:]...), childIDs(t, api, s1, s1))
+	assert.Equal(t, 3, api.MaxKeyLength(s1, s1))
+}
+
+func TestApi_RebalanceAbove(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	require.NoError(t, err)
+	api.RebalanceAbove(3)
+
+	order := insertAfterFirst(t, api, 200)
+	assert.Equal(t, order, childIDs(t, api, s1, s1))
+	assert.LessOrEqual(t, api.MaxKeyLength(s1, s1), 3)
+
+	children, err := api.GetChildrenBlocks(s1, s1)
+	require.NoError(t, err)
+	for _, child := range children {
+		assert.LessOrEqual(t, len(child.Index.Bytes()), 3)
+	}
+}
+
+func TestApi_ReindexMovedAway(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	require.NoError(t, err)
+
+	_, err = api.Apply(createTx(s1,
+		insertOp(b1, "para", s1, PositionEnd),
+		insertOp(b2, "para", s1, PositionEnd),
+		insertOp(b3, "para", s1, PositionEnd),
+	))
+	require.NoError(t, err)
+
+	reindex := moveOp(b3, s1, s1, PositionInside)
+	reindex.Index = NewSpread(1)[0].Bytes()
+
+	// the reindex is skipped once the block is moved to another parent
+	_, err = api.Apply(createTx(s1, moveOp(b3, s1, b1, PositionEnd)))
+	require.NoError(t, err)
+	_, err = api.Apply(createTx(s1, reindex))
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{b1, b2}, childIDs(t, api, s1, s1))
+	assert.Equal(t, []uuid.UUID{b3}, childIDs(t, api, s1, b1))
+
+	// the reindex of a child moves it among its siblings
+	first, err := api.GetBlock(s1, b1)
+	require.NoError(t, err)
+	reindex = moveOp(b2, s1, s1, PositionInside)
+	reindex.Index = NewBefore(first.Index).Bytes()
+	tx := createTx(s1, reindex)
+	_, err = api.Apply(tx)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{b2, b1}, childIDs(t, api, s1, s1))
+
+	inverse, err := api.Invert(s1, tx.ID)
+	require.NoError(t, err)
+	_, err = api.Apply(inverse)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{b1, b2}, childIDs(t, api, s1, s1))
+
+	reindex.Index = []byte{0x80, 0x01}
+	_, err = api.Apply(createTx(s1, reindex))
+	assert.ErrorIs(t, err, ErrInvalidOp)
+}
+
+func TestRebalancing_Bounded(t *testing.T) {
+	api := NewApi(NewMemStore())
+	r := api.rebalancing
+	track := func(parentID BlockID, index *FracIndex) {
+		change := newBlockChange()
+		change.addInserted(&Block{ID: uuid.New(), ParentID: parentID, Index: index})
+		r.placed(s1, change)
+	}
+
+	long := FracIndexFromBytes([]byte{0x80, 0x80, 0x80, 0x01})
+	track(b1, long)
+	for i := 0; i < maxTrackedParents; i++ {
+		track(uuid.New(), DefaultFracIndex())
+	}
+
+	// the parents with the shortest keys make room for the new ones
+	assert.Len(t, r.lengths, maxTrackedParents)
+	assert.Equal(t, 4, r.lengths[parentKey{spaceID: s1, parentID: b1}])
+
+	// only the index metrics are served, the other expvars are left out
+	recorder := httptest.NewRecorder()
+	api.serveIndexMetrics(recorder, httptest.NewRequest("GET", "/debug/vars", nil))
+	var vars map[string]map[string]int
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &vars))
+	assert.Len(t, vars, 1)
+	assert.Len(t, vars["blocktree_max_index_length"], maxTrackedParents)
+	assert.Equal(t, 4, vars["blocktree_max_index_length"][parentKey{spaceID: s1, parentID: b1}.String()])
+
+	// the metrics belong to the api, another api in the process serves its own
+	recorder = httptest.NewRecorder()
+	NewApi(NewMemStore()).serveIndexMetrics(recorder, httptest.NewRequest("GET", "/debug/vars", nil))
+	var other map[string]map[string]int
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &other))
+	assert.Empty(t, other["blocktree_max_index_length"])
+}
+
+func TestOpFromProtoV1_Index(t *testing.T) {
+	index := NewSpread(2)[1]
+	key := index.Key()
+	op, err := OpFromProtoV1(&v1.Op{
+		Table:   "block",
+		BlockId: b1.String(),
+		Type:    v1.OpType_OP_TYPE_MOVE,
+		At:      &v1.Pointer{BlockId: s1.String(), Position: v1.PointerPosition_POINTER_POSITION_INSIDE},
+		Index:   &key,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, index.Bytes(), op.Index)
+
+	// the index bytes are not a key
+	bytes := string(index.Bytes())
+	_, err = OpFromProtoV1(&v1.Op{
+		Table:   "block",
+		BlockId: b1.String(),
+		Type:    v1.OpType_OP_TYPE_MOVE,
+		At:      &v1.Pointer{BlockId: s1.String(), Position: v1.PointerPosition_POINTER_POSITION_INSIDE},
+		Index:   &bytes,
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,106 @@
+package blocktree
+
+import (
+	"errors"
+	"sort"
+)
+
+// ResultCode is the outcome of a transaction applied by Api.ApplyEach
+type ResultCode string
+
+const (
+	ResultOK       ResultCode = "ok"
+	ResultNotFound ResultCode = "not_found"
+	// ResultCycle is set on a transaction applied with the moves that create a cycle skipped,
+	// or on a failed transaction with a cycle
+	ResultCycle            ResultCode = "cycle"
+	ResultInvalidPosition  ResultCode = "invalid_position"
+	ResultInvalidOp        ResultCode = "invalid_op"
+	ResultSchemaViolation  ResultCode = "schema_violation"
+	ResultDuplicate        ResultCode = "duplicate"
+	ResultConflict         ResultCode = "conflict"
+	ResultSnapshotRequired ResultCode = "snapshot_required"
+	ResultInternal         ResultCode = "internal"
+)
+
+// TransactionResult is the result of a transaction applied by Api.ApplyEach
+type TransactionResult struct {
+	TransactionID TransactionID
+	SpaceID       SpaceID
+	Code          ResultCode
+	// Err is the error of the failed transaction
+	Err error
+	// Skipped are the indexes of the move ops skipped because they create a cycle
+	Skipped []int
+}
+
+// Success returns true if the transaction is applied
+func (r *TransactionResult) Success() bool {
+	return r.Err == nil
+}
+
+// Rejected returns true if the transaction failed for good, it fails the same way when it is applied again.
+// the other failures, e.g. ResultInternal or ResultSnapshotRequired, can pass on a retry.
+func (r *TransactionResult) Rejected() bool {
+	if r.Err == nil {
+		return false
+	}
+
+	switch r.Code {
+	case ResultNotFound, ResultCycle, ResultInvalidPosition, ResultInvalidOp, ResultSchemaViolation, ResultDuplicate, ResultConflict:
+		return true
+	default:
+		return false
+	}
+}
+
+// newTransactionResult returns the result of the transaction applied with the change or failed with the error
+func newTransactionResult(tx *Transaction, change *storeChange, err error) *TransactionResult {
+	result := &TransactionResult{
+		TransactionID: tx.ID,
+		SpaceID:       tx.SpaceID,
+		Code:          ResultOK,
+		Err:           err,
+	}
+
+	if err != nil {
+		result.Code = resultCode(err)
+		return result
+	}
+
+	if change != nil && change.tx != nil {
+		for i := range change.tx.skip {
+			result.Skipped = append(result.Skipped, i)
+		}
+		sort.Ints(result.Skipped)
+	}
+	if len(result.Skipped) > 0 {
+		result.Code = ResultCycle
+	}
+
+	return result
+}
+
+// resultCode returns the result code of the error
+func resultCode(err error) ResultCode {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return ResultNotFound
+	case errors.Is(err, ErrCreatesCycle) || errors.Is(err, ErrDetectedCycle):
+		return ResultCycle
+	case errors.Is(err, ErrInvalidPosition):
+		return ResultInvalidPosition
+	case errors.Is(err, ErrInvalidOp):
+		return ResultInvalidOp
+	case errors.Is(err, ErrSchemaViolation):
+		return ResultSchemaViolation
+	case errors.Is(err, ErrDuplicate):
+		return ResultDuplicate
+	case errors.Is(err, ErrConflict):
+		return ResultConflict
+	case errors.Is(err, ErrSnapshotRequired):
+		return ResultSnapshotRequired
+	default:
+		return ResultInternal
+	}
+}
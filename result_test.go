@@ -0,0 +1,72 @@
+package blocktree
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApi_ApplyEach(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	require.NoError(t, err)
+
+	tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionEnd), insertOp(b2, "p2", b1, PositionEnd))
+	// the parent b9 does not exist
+	tx2 := createTx(s1, insertOp(b3, "p3", b9, PositionEnd))
+	tx3 := createTx(s1, insertOp(b1, "p1", s1, PositionEnd))
+	tx4 := createTx(s1, insertOp(b4, "p4", b1, PositionInside))
+	// b1 into its child b2 creates a cycle, the insert of b5 is applied
+	tx5 := createTx(s1, moveOp(b1, s1, b2, PositionEnd), insertOp(b5, "p5", s1, PositionEnd))
+	tx6 := createTx(s1, insertOp(b6, "p6", s1, PositionEnd))
+	tx6.Precondition = &Precondition{Seq: seqOf(1)}
+	tx7 := createTx(s1, insertOp(b7, "p7", b2, PositionEnd))
+
+	results, changes := api.ApplyEach(tx1, tx2, tx3, tx4, tx5, tx6, tx7)
+	require.Len(t, results, 7)
+
+	codes := make([]ResultCode, 0, len(results))
+	for i, result := range results {
+		codes = append(codes, result.Code)
+		assert.Equal(t, []*Transaction{tx1, tx2, tx3, tx4, tx5, tx6, tx7}[i].ID, result.TransactionID)
+	}
+	assert.Equal(t, []ResultCode{
+		ResultOK,
+		ResultNotFound,
+		ResultDuplicate,
+		ResultInvalidPosition,
+		ResultCycle,
+		ResultConflict,
+		ResultOK,
+	}, codes)
+
+	assert.True(t, results[4].Success())
+	assert.Equal(t, []int{0}, results[4].Skipped)
+	assert.False(t, results[5].Success())
+	assert.ErrorIs(t, results[5].Err, ErrConflict)
+
+	// the failures do not stop the transactions after them
+	assert.Equal(t, []uuid.UUID{b1, b5}, childIDs(t, api, s1, s1))
+	assert.Equal(t, []uuid.UUID{b2}, childIDs(t, api, s1, b1))
+	assert.Equal(t, []uuid.UUID{b7}, childIDs(t, api, s1, b2))
+	assert.True(t, changes.inserted.Contains(b7))
+	assert.False(t, changes.inserted.Contains(b3))
+}
+
+func TestApi_ApplyDuplicateInsert(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	require.NoError(t, err)
+
+	_, err = api.Apply(createTx(s1, insertOp(b1, "p1", s1, PositionEnd)))
+	require.NoError(t, err)
+
+	_, err = api.Apply(createTx(s1, insertOp(b1, "p1", s1, PositionEnd)))
+	assert.ErrorIs(t, err, ErrDuplicate)
+	_, err = api.Apply(createTx(s1, insertOp(b2, "p2", s1, PositionEnd), insertOp(b2, "p2", b1, PositionEnd)))
+	assert.ErrorIs(t, err, ErrDuplicate)
+
+	assert.Equal(t, []uuid.UUID{b1}, childIDs(t, api, s1, s1))
+}
@@ -0,0 +1,298 @@
+package blocktree
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// ErrSchemaViolation is returned when an op breaks the schema of a block type, errors.As gives the SchemaError.
+var ErrSchemaViolation = fmt.Errorf("schema violation")
+
+// BlockSchema declares the content of a block type and the blocks it can hold.
+type BlockSchema struct {
+	// Type is the block type, the Object of the insert ops
+	Type string `json:"type"`
+	// Props is the JSON Schema of the block props, empty accepts any props
+	Props json.RawMessage `json:"props,omitempty"`
+	// Json is the JSON Schema of the block json content, empty accepts any content
+	Json json.RawMessage `json:"json,omitempty"`
+	// Children are the types of the child blocks, nil accepts any type and an empty list none
+	Children []string `json:"children"`
+	// Linkable blocks can be linked into other blocks
+	Linkable bool `json:"linkable"`
+}
+
+// SchemaError is the op of a transaction that breaks the schema of a block type.
+type SchemaError struct {
+	BlockID BlockID
+	Type    string
+	Reason  string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%v: block %v of type %q: %s", ErrSchemaViolation, e.BlockID, e.Type, e.Reason)
+}
+
+func (e *SchemaError) Is(target error) bool {
+	return target == ErrSchemaViolation
+}
+
+// compiledSchema is a block schema with its JSON Schemas compiled
+type compiledSchema struct {
+	*BlockSchema
+	props    *jsonschema.Schema
+	json     *jsonschema.Schema
+	children *Set[string]
+}
+
+// SchemaRegistry holds the schemas of the block types, the blocks of the types without a schema are not checked.
+// the children of a block with a schema are checked even when they have no schema.
+type SchemaRegistry struct {
+	mu      sync.RWMutex
+	schemas map[string]*compiledSchema
+}
+
+// NewSchemaRegistry creates an empty schema registry
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{
+		schemas: make(map[string]*compiledSchema),
+	}
+}
+
+// LoadSchemaRegistry reads the registry from a json file with a list of block schemas
+func LoadSchemaRegistry(path string) (*SchemaRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var schemas []*BlockSchema
+	err = json.Unmarshal(data, &schemas)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema file %v: %w", path, err)
+	}
+
+	registry := NewSchemaRegistry()
+	err = registry.Register(schemas...)
+	if err != nil {
+		return nil, err
+	}
+
+	return registry, nil
+}
+
+// Register adds the schemas to the registry, a schema replaces the earlier schema of its type.
+// none of the schemas are added when one of them does not compile.
+func (r *SchemaRegistry) Register(schemas ...*BlockSchema) error {
+	compiled := make([]*compiledSchema, 0, len(schemas))
+	for _, schema := range schemas {
+		if schema.Type == "" {
+			return fmt.Errorf("block schema without type")
+		}
+
+		c := &compiledSchema{BlockSchema: schema}
+		var err error
+		c.props, err = compileSchema(schema.Type, "props", schema.Props)
+		if err != nil {
+			return err
+		}
+		c.json, err = compileSchema(schema.Type, "json", schema.Json)
+		if err != nil {
+			return err
+		}
+		if schema.Children != nil {
+			c.children = NewSet[string](schema.Children...)
+		}
+		compiled = append(compiled, c)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range compiled {
+		r.schemas[c.Type] = c
+	}
+
+	return nil
+}
+
+// Get returns the schema of the block type
+func (r *SchemaRegistry) Get(blockType string) (*BlockSchema, bool) {
+	schema, ok := r.schema(blockType)
+	if !ok {
+		return nil, false
+	}
+
+	return schema.BlockSchema, true
+}
+
+func (r *SchemaRegistry) schema(blockType string) (*compiledSchema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schema, ok := r.schemas[blockType]
+	return schema, ok
+}
+
+// compileSchema compiles the JSON Schema of a part of the block type, an empty schema is nil
+func compileSchema(blockType, part string, schema json.RawMessage) (*jsonschema.Schema, error) {
+	if len(bytes.TrimSpace(schema)) == 0 {
+		return nil, nil
+	}
+
+	url := fmt.Sprintf("blocktree:///schemas/%s/%s.json", blockType, part)
+	compiled, err := jsonschema.CompileString(url, string(schema))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s schema of block type %q: %w", part, blockType, err)
+	}
+
+	return compiled, nil
+}
+
+// check returns a SchemaError when an op of the transaction breaks the schema of a block type.
+// the blocks are checked as the stage left them, after all the ops of the transaction.
+func (r *SchemaRegistry) check(store Store, tx *Transaction, stage *stageTable) error {
+	for _, op := range tx.ops() {
+		switch op.Type {
+		case OpTypeInsert, OpTypeMove:
+			block, err := r.staged(store, tx.SpaceID, stage, op.BlockID)
+			if err != nil {
+				return err
+			}
+			// the space blocks are not inserted by transactions
+			if block.Type == "space" {
+				continue
+			}
+			if op.Type == OpTypeInsert {
+				if err := r.checkContent(block, block.Props, "props"); err != nil {
+					return err
+				}
+				if err := r.checkContent(block, block.Json, "json"); err != nil {
+					return err
+				}
+			}
+			if block.Linked {
+				if err := r.checkLinkable(block); err != nil {
+					return err
+				}
+				continue
+			}
+
+			parent, err := r.staged(store, tx.SpaceID, stage, block.ParentID)
+			if err != nil {
+				return err
+			}
+			if err := r.checkChild(parent, block); err != nil {
+				return err
+			}
+		case OpTypeUpdate, OpTypePatch, OpTypeText:
+			block, err := r.staged(store, tx.SpaceID, stage, op.BlockID)
+			if err != nil {
+				return err
+			}
+			if op.Type == OpTypeUpdate {
+				err = r.checkContent(block, block.Props, "props")
+			} else {
+				err = r.checkContent(block, block.Json, "json")
+			}
+			if err != nil {
+				return err
+			}
+		case OpTypeLink:
+			block, err := r.staged(store, tx.SpaceID, stage, op.BlockID)
+			if err != nil {
+				return err
+			}
+			if err := r.checkLinkable(block); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// staged returns the block from the stage, the moved blocks staged without their type are read from the store
+func (r *SchemaRegistry) staged(store Store, spaceID SpaceID, stage *stageTable, id BlockID) (*Block, error) {
+	block, ok := stage.block(id)
+	if ok && block.Type != "" {
+		return block, nil
+	}
+
+	stored, err := store.GetBlock(&spaceID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return stored, nil
+	}
+
+	// the staged block keeps its new position
+	staged := block.Clone()
+	staged.Type = stored.Type
+	staged.Linked = stored.Linked
+
+	return staged, nil
+}
+
+// checkContent validates the props or the json content of the block
+func (r *SchemaRegistry) checkContent(block *Block, doc *JsonDoc, part string) error {
+	schema, ok := r.schema(block.Type)
+	if !ok {
+		return nil
+	}
+
+	compiled := schema.props
+	if part == "json" {
+		compiled = schema.json
+	}
+	if compiled == nil {
+		return nil
+	}
+
+	content := []byte(`{}`)
+	if doc != nil && len(doc.Content) > 0 {
+		content = doc.Content
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return &SchemaError{BlockID: block.ID, Type: block.Type, Reason: fmt.Sprintf("invalid %s: %v", part, err)}
+	}
+
+	if err := compiled.Validate(value); err != nil {
+		return &SchemaError{BlockID: block.ID, Type: block.Type, Reason: fmt.Sprintf("invalid %s: %v", part, err)}
+	}
+
+	return nil
+}
+
+// checkChild returns a SchemaError when the parent type does not allow the child type
+func (r *SchemaRegistry) checkChild(parent, child *Block) error {
+	schema, ok := r.schema(parent.Type)
+	if !ok || schema.children == nil {
+		return nil
+	}
+
+	if !schema.children.Contains(child.Type) {
+		return &SchemaError{BlockID: child.ID, Type: child.Type, Reason: fmt.Sprintf("not allowed inside %q block %v", parent.Type, parent.ID)}
+	}
+
+	return nil
+}
+
+// checkLinkable returns a SchemaError when the block type cannot be linked
+func (r *SchemaRegistry) checkLinkable(block *Block) error {
+	schema, ok := r.schema(block.Type)
+	if !ok || schema.Linkable {
+		return nil
+	}
+
+	return &SchemaError{BlockID: block.ID, Type: block.Type, Reason: "cannot be linked"}
+}
@@ -0,0 +1,165 @@
+package blocktree
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSchemas(t *testing.T) *SchemaRegistry {
+	schemas := NewSchemaRegistry()
+	err := schemas.Register(
+		&BlockSchema{Type: "table-cell", Children: []string{"text"}},
+		&BlockSchema{Type: "text", Children: []string{}},
+		&BlockSchema{
+			Type:  "heading",
+			Props: []byte(`{"type": "object", "properties": {"level": {"type": "integer", "minimum": 1, "maximum": 6}}}`),
+		},
+		&BlockSchema{
+			Type: "code",
+			Json: []byte(`{"type": "object", "properties": {"language": {"type": "string"}}}`),
+		},
+		&BlockSchema{
+			Type:  "callout",
+			Props: []byte(`{"type": "object", "required": ["kind"]}`),
+		},
+		&BlockSchema{Type: "para"},
+		&BlockSchema{Type: "page", Linkable: true},
+	)
+	require.NoError(t, err)
+
+	return schemas
+}
+
+func assertSchemaError(t *testing.T, err error, blockID BlockID) {
+	t.Helper()
+	assert.ErrorIs(t, err, ErrSchemaViolation)
+	var schemaErr *SchemaError
+	require.True(t, errors.As(err, &schemaErr))
+	assert.Equal(t, blockID, schemaErr.BlockID)
+}
+
+func TestApi_SchemaChildren(t *testing.T) {
+	api := NewApi(NewMemStore())
+	api.SetSchemas(testSchemas(t))
+	err := api.CreateSpace(s1, "test-1")
+	require.NoError(t, err)
+
+	_, err = api.Apply(createTx(s1, insertOp(b1, "table-cell", s1, PositionEnd), insertOp(b2, "text", b1, PositionEnd)))
+	require.NoError(t, err)
+
+	// a para is not allowed inside a table-cell
+	_, err = api.Apply(createTx(s1, insertOp(b3, "para", b1, PositionEnd)))
+	assertSchemaError(t, err, b3)
+	_, err = api.Apply(createTx(s1, insertOp(b3, "para", b2, PositionAfter)))
+	assertSchemaError(t, err, b3)
+
+	// the text allows no children
+	_, err = api.Apply(createTx(s1, insertOp(b3, "text", b2, PositionEnd)))
+	assertSchemaError(t, err, b3)
+
+	_, err = api.Apply(createTx(s1, insertOp(b3, "para", s1, PositionEnd)))
+	require.NoError(t, err)
+	_, err = api.Apply(createTx(s1, moveOp(b3, s1, b1, PositionStart)))
+	assertSchemaError(t, err, b3)
+	_, err = api.Apply(createTx(s1, moveOp(b2, b1, b3, PositionAfter)))
+	require.NoError(t, err)
+
+	assert.Equal(t, []uuid.UUID{b1, b3, b2}, childIDs(t, api, s1, s1))
+	assert.Empty(t, childIDs(t, api, s1, b1))
+}
+
+func TestApi_SchemaContent(t *testing.T) {
+	api := NewApi(NewMemStore())
+	api.SetSchemas(testSchemas(t))
+	err := api.CreateSpace(s1, "test-1")
+	require.NoError(t, err)
+
+	_, err = api.Apply(createTx(s1, insertOp(b1, "heading", s1, PositionEnd)))
+	require.NoError(t, err)
+
+	_, err = api.Apply(createTx(s1, updateOp(b1, []byte(`[{"op":"add","path":"/level","value":2}]`))))
+	require.NoError(t, err)
+	_, err = api.Apply(createTx(s1, updateOp(b1, []byte(`[{"op":"replace","path":"/level","value":7}]`))))
+	assertSchemaError(t, err, b1)
+
+	block, err := api.GetBlock(s1, b1)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"level": 2}`, block.Props.String())
+
+	code := insertOp(b2, "code", s1, PositionEnd)
+	code.Patch = []byte(`[{"op":"add","path":"/language","value":1}]`)
+	_, err = api.Apply(createTx(s1, code))
+	assertSchemaError(t, err, b2)
+
+	code.Patch = []byte(`[{"op":"add","path":"/language","value":"go"}]`)
+	_, err = api.Apply(createTx(s1, code))
+	require.NoError(t, err)
+	_, err = api.Apply(createTx(s1, patchOp(b2, []byte(`[{"op":"replace","path":"/language","value":false}]`))))
+	assertSchemaError(t, err, b2)
+
+	// the props of an inserted block are checked, they are set by the updates of the transaction
+	_, err = api.Apply(createTx(s1, insertOp(b3, "callout", s1, PositionEnd)))
+	assertSchemaError(t, err, b3)
+	_, err = api.Apply(createTx(s1,
+		insertOp(b3, "callout", s1, PositionEnd),
+		updateOp(b3, []byte(`[{"op":"add","path":"/kind","value":"info"}]`)),
+	))
+	require.NoError(t, err)
+}
+
+func TestApi_SchemaLinks(t *testing.T) {
+	api := NewApi(NewMemStore())
+	api.SetSchemas(testSchemas(t))
+	err := api.CreateSpace(s1, "test-1")
+	require.NoError(t, err)
+
+	_, err = api.Apply(createTx(s1, insertOp(b1, "para", s1, PositionEnd), insertOp(b2, "page", s1, PositionEnd), insertOp(b3, "para", s1, PositionEnd)))
+	require.NoError(t, err)
+
+	_, err = api.Apply(createTx(s1, linkInsertOp(b4, "para", b1)))
+	assertSchemaError(t, err, b4)
+	_, err = api.Apply(createTx(s1, linkInsertOp(b4, "page", b1)))
+	require.NoError(t, err)
+
+	_, err = api.Apply(createTx(s1, linkOp(b3, b1)))
+	assertSchemaError(t, err, b3)
+	_, err = api.Apply(createTx(s1, linkOp(b2, b1)))
+	assert.NoError(t, err)
+}
+
+func TestSchemaRegistry_Register(t *testing.T) {
+	schemas := NewSchemaRegistry()
+	err := schemas.Register(
+		&BlockSchema{Type: "text"},
+		&BlockSchema{Type: "heading", Props: []byte(`{"type": 1}`)},
+	)
+	assert.Error(t, err)
+	_, ok := schemas.Get("text")
+	assert.False(t, ok, "no schema is added when one of them is invalid")
+
+	err = schemas.Register(&BlockSchema{})
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "schemas.json")
+	err = os.WriteFile(path, []byte(`[
+		{"type": "table-cell", "children": ["text"]},
+		{"type": "heading", "props": {"type": "object", "required": ["level"]}, "linkable": true}
+	]`), 0o644)
+	require.NoError(t, err)
+
+	schemas, err = LoadSchemaRegistry(path)
+	require.NoError(t, err)
+	cell, ok := schemas.Get("table-cell")
+	require.True(t, ok)
+	assert.Equal(t, []string{"text"}, cell.Children)
+	heading, ok := schemas.Get("heading")
+	require.True(t, ok)
+	assert.Nil(t, heading.Children)
+	assert.True(t, heading.Linkable)
+}
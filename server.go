@@ -26,6 +26,7 @@ import (
 type Server struct {
 	Config *Config
 	store  Store
+	broker *Broker
 }
 
 // NewServer creates a new server
@@ -33,6 +34,7 @@ func NewServer(store Store, config *Config) *Server {
 	return &Server{
 		store:  store,
 		Config: config,
+		broker: NewBroker(),
 	}
 }
 
@@ -62,7 +64,35 @@ func (s *Server) Start() error {
 	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
 	endpoint := "localhost" + grpcPort
 
-	api := NewApiWithPublisher(s.store, NewNullPublisher())
+	api := NewApiWithBroker(s.store, s.broker)
+	if s.Config.RedisURL != "" {
+		bus, err := NewRedisBusFromURL(s.Config.RedisURL)
+		if err != nil {
+			return err
+		}
+		defer bus.Close()
+
+		// the changes of the other servers reach the local subscribers through the bus
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			if err := bus.Forward(ctx, s.broker); err != nil {
+				logrus.Errorf("redis bus stopped: %v", err)
+			}
+		}()
+
+		api = NewApiWithPubSub(s.store, NewMultiPublisher(s.broker, bus), s.broker)
+	}
+	api.CompactEvery(s.Config.CompactEvery)
+	api.RebalanceAbove(s.Config.RebalanceAbove)
+	api.JitterIndices(s.Config.JitterIndices)
+	if s.Config.SchemaFile != "" {
+		schemas, err := LoadSchemaRegistry(s.Config.SchemaFile)
+		if err != nil {
+			return err
+		}
+		api.SetSchemas(schemas)
+	}
 
 	// Register the server with the gRPC server
 	v1.RegisterBlocktreeServer(grpcServer, newGrpcApi(api))
@@ -76,13 +106,15 @@ func (s *Server) Start() error {
 	openAPIBox := packr.NewBox("docs/v1")
 	docsPath := "/v1/docs/"
 	apiMux.Handle(docsPath, http.StripPrefix(docsPath, http.FileServer(openAPIBox)))
+	// Subscribe has no gateway route, the http clients subscribe with server-sent events
+	apiMux.Handle(eventsPath, newSseApi(api))
 	apiMux.Handle("/", mux)
 
 	// Add CORS support
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
-		AllowedHeaders:   []string{"Authorization"},
+		AllowedHeaders:   []string{"Authorization", "Last-Event-ID"},
 		AllowCredentials: true,
 	})
 
@@ -117,6 +149,31 @@ func (s *Server) Start() error {
 		logrus.Infof("rest gateway stopped")
 	}()
 
+	// the metrics, e.g. the max index key length per parent, are served on localhost only
+	var metricsServer *http.Server
+	if s.Config.MetricsPort != 0 {
+		metricsMux := http.NewServeMux()
+		metricsMux.HandleFunc("/debug/vars", api.serveIndexMetrics)
+		metricsServer = &http.Server{
+			Addr:    fmt.Sprintf("127.0.0.1:%d", s.Config.MetricsPort),
+			Handler: metricsMux,
+		}
+
+		ml, err := net.Listen("tcp", metricsServer.Addr)
+		if err != nil {
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logrus.Info("starting metrics on: ", metricsServer.Addr)
+			if err := metricsServer.Serve(ml); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logrus.Errorf("error starting metrics: %v", err)
+			}
+		}()
+	}
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -144,6 +201,12 @@ func (s *Server) Start() error {
 	if err != nil {
 		logrus.Errorf("error stopping rest gateway: %v", err)
 	}
+	if metricsServer != nil {
+		err = metricsServer.Shutdown(context.Background())
+		if err != nil {
+			logrus.Errorf("error stopping metrics: %v", err)
+		}
+	}
 
 	// wait for the servers to stop
 	wg.Wait()
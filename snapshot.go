@@ -0,0 +1,564 @@
+package blocktree
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// replayBatchSize is the number of transactions loaded at once while replaying the log
+const replayBatchSize = 100
+
+// ErrSnapshotRequired is returned for the transactions removed from the log by a compaction,
+// the client has to re-download the space snapshot and continue from the snapshot transaction.
+var ErrSnapshotRequired = fmt.Errorf("snapshot required, the transaction log is compacted: re-download the space snapshot")
+
+// Snapshot is the tree of a space as it was after a transaction.
+type Snapshot struct {
+	SpaceID       SpaceID
+	TransactionID TransactionID
+	// Time is the time of the last transaction in the snapshot
+	Time time.Time
+	// Clock is the clock of the last transaction in the snapshot
+	Clock uint64
+	// Seq and HLC are the seq and the timestamp of the last transaction in the snapshot
+	Seq uint64
+	HLC HLC
+	// Blocks holds the space block first, then every block after its parent
+	// with the children of a block in index order.
+	Blocks []*Block
+	// BackLinks maps a linked block to the blocks it is linked at
+	BackLinks map[BlockID][]BlockID
+	// moves are the records of the move log, a compaction keeps them so the moves that arrive late
+	// undo and redo the compacted moves too
+	moves []*moveRecord
+}
+
+// snapshotJSON is the stored form of a snapshot
+type snapshotJSON struct {
+	SpaceID       SpaceID               `json:"space_id"`
+	TransactionID TransactionID         `json:"transaction_id"`
+	Time          time.Time             `json:"time"`
+	Clock         uint64                `json:"clock,omitempty"`
+	Seq           uint64                `json:"seq,omitempty"`
+	HLC           HLC                   `json:"hlc,omitempty"`
+	Blocks        []snapshotBlockJSON   `json:"blocks"`
+	BackLinks     map[BlockID][]BlockID `json:"back_links,omitempty"`
+	Moves         []snapshotMoveJSON    `json:"moves,omitempty"`
+}
+
+// snapshotMoveJSON is the stored form of a move record
+type snapshotMoveJSON struct {
+	Clock         uint64              `json:"clock"`
+	TransactionID TransactionID       `json:"transaction_id"`
+	Ops           []Op                `json:"ops"`
+	Jitter        []byte              `json:"jitter,omitempty"`
+	Moved         []snapshotPlaceJSON `json:"moved,omitempty"`
+}
+
+// snapshotPlaceJSON is the place of a block before a move
+type snapshotPlaceJSON struct {
+	ID       BlockID `json:"id"`
+	ParentID BlockID `json:"parent_id"`
+	Index    []byte  `json:"index"`
+}
+
+type snapshotBlockJSON struct {
+	ID       BlockID `json:"id"`
+	Type     string  `json:"type"`
+	Table    string  `json:"table,omitempty"`
+	ParentID BlockID `json:"parent_id"`
+	Index    []byte  `json:"index"`
+	Props    []byte  `json:"props,omitempty"`
+	Json     []byte  `json:"json,omitempty"`
+	Deleted  bool    `json:"deleted,omitempty"`
+	Erased   bool    `json:"erased,omitempty"`
+	Linked   bool    `json:"linked,omitempty"`
+	Version  uint64  `json:"version,omitempty"`
+}
+
+func (s *Snapshot) MarshalJSON() ([]byte, error) {
+	blocks := make([]snapshotBlockJSON, 0, len(s.Blocks))
+	for _, block := range s.Blocks {
+		blocks = append(blocks, snapshotBlockJSON{
+			ID:       block.ID,
+			Type:     block.Type,
+			Table:    block.Table,
+			ParentID: block.ParentID,
+			Index:    block.Index.Bytes(),
+			Props:    jsonDocBytes(block.Props),
+			Json:     jsonDocBytes(block.Json),
+			Deleted:  block.Deleted,
+			Erased:   block.Erased,
+			Linked:   block.Linked,
+			Version:  block.Version,
+		})
+	}
+
+	moves := make([]snapshotMoveJSON, 0, len(s.moves))
+	for _, record := range s.moves {
+		moved := make([]snapshotPlaceJSON, 0, len(record.moved))
+		for _, block := range record.moved {
+			moved = append(moved, snapshotPlaceJSON{ID: block.ID, ParentID: block.ParentID, Index: block.Index.Bytes()})
+		}
+		moves = append(moves, snapshotMoveJSON{
+			Clock:         record.clock,
+			TransactionID: record.txID,
+			Ops:           record.ops,
+			Jitter:        record.jitter,
+			Moved:         moved,
+		})
+	}
+
+	return json.Marshal(snapshotJSON{
+		SpaceID:       s.SpaceID,
+		TransactionID: s.TransactionID,
+		Time:          s.Time,
+		Clock:         s.Clock,
+		Seq:           s.Seq,
+		HLC:           s.HLC,
+		Blocks:        blocks,
+		BackLinks:     s.BackLinks,
+		Moves:         moves,
+	})
+}
+
+func (s *Snapshot) UnmarshalJSON(data []byte) error {
+	var stored snapshotJSON
+	err := json.Unmarshal(data, &stored)
+	if err != nil {
+		return err
+	}
+
+	*s = Snapshot{
+		SpaceID:       stored.SpaceID,
+		TransactionID: stored.TransactionID,
+		Time:          stored.Time,
+		Clock:         stored.Clock,
+		Seq:           stored.Seq,
+		HLC:           stored.HLC,
+		Blocks:        make([]*Block, 0, len(stored.Blocks)),
+		BackLinks:     stored.BackLinks,
+	}
+	for _, b := range stored.Blocks {
+		if len(b.Index) == 0 {
+			return fmt.Errorf("index is empty for snapshot block %v", b.ID)
+		}
+
+		block := &Block{
+			ID:       b.ID,
+			ParentID: b.ParentID,
+			Type:     b.Type,
+			Table:    b.Table,
+			Index:    FracIndexFromBytes(b.Index),
+			Deleted:  b.Deleted,
+			Erased:   b.Erased,
+			Linked:   b.Linked,
+			Version:  b.Version,
+		}
+		if b.Props != nil {
+			block.Props = NewJsonDoc(b.Props)
+		}
+		if b.Json != nil {
+			block.Json = NewJsonDoc(b.Json)
+		}
+		s.Blocks = append(s.Blocks, block)
+	}
+
+	for _, m := range stored.Moves {
+		record := &moveRecord{
+			clock:  m.Clock,
+			txID:   m.TransactionID,
+			ops:    m.Ops,
+			jitter: m.Jitter,
+			moved:  make([]*Block, 0, len(m.Moved)),
+		}
+		for _, place := range m.Moved {
+			record.moved = append(record.moved, &Block{ID: place.ID, ParentID: place.ParentID, Index: FracIndexFromBytes(place.Index)})
+		}
+		s.moves = append(s.moves, record)
+	}
+
+	return nil
+}
+
+// Snapshot returns the tree of the space after its latest transaction.
+func (a *Api) Snapshot(spaceID SpaceID) (*Snapshot, error) {
+	latest, err := a.store.GetLatestTransaction(&spaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.SnapshotAt(spaceID, latest.ID)
+}
+
+// SnapshotAt returns the tree of the space as it was after the given transaction.
+// the tree is rebuilt by replaying the transaction log, uuid.Nil is the empty space.
+func (a *Api) SnapshotAt(spaceID SpaceID, txID TransactionID) (*Snapshot, error) {
+	_, store, tx, err := a.replay(spaceID, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.snapshot(spaceID, tx), nil
+}
+
+// Compact stores a snapshot of the space after its latest transaction and removes the older transactions from the log.
+// the clients asking for updates after a removed transaction get ErrSnapshotRequired.
+// the snapshot keeps the move log, an earlier move that arrives later still undoes and redoes the compacted moves.
+func (a *Api) Compact(spaceID SpaceID) (*Snapshot, error) {
+	moves := a.moves.space(spaceID)
+	defer moves.mu.Unlock()
+
+	latest, err := a.store.GetLatestTransaction(&spaceID)
+	if err != nil {
+		return nil, err
+	}
+	replayer, store, tx, err := a.replay(spaceID, latest.ID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := store.snapshot(spaceID, tx)
+	snapshot.moves = replayer.moves.snapshotRecords(spaceID)
+
+	err = a.store.PutSnapshot(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	// the compacted transactions cannot be inverted, they are dropped from the undo history
+	a.history.prune(spaceID, func(txID TransactionID) bool {
+		_, err := a.store.GetTransaction(&spaceID, txID)
+		return err == nil
+	})
+
+	return snapshot, nil
+}
+
+// CompactEvery makes the api compact a space after every n applied transactions, zero turns it off.
+func (a *Api) CompactEvery(n int) {
+	a.compaction.mu.Lock()
+	defer a.compaction.mu.Unlock()
+
+	a.compaction.every = n
+}
+
+// GetDescendantsAt returns the descendant blocks of the block as they were after the given transaction.
+func (a *Api) GetDescendantsAt(spaceID SpaceID, blockID BlockID, txID TransactionID) ([]*Block, error) {
+	_, store, _, err := a.replay(spaceID, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.GetDescendantBlocks(&spaceID, blockID)
+}
+
+// GetTransactionAt returns the last transaction of the space applied at or before the given time.
+// the genesis transaction is returned when no transaction is that old.
+// the transactions before the last compaction are not in the log, older times get ErrSnapshotRequired.
+func (a *Api) GetTransactionAt(spaceID SpaceID, at time.Time) (*Transaction, error) {
+	head, snapshot, err := a.logHead(spaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := a.store.GetTransaction(&spaceID, head)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot != nil && found.Time.After(at) {
+		return nil, ErrSnapshotRequired
+	}
+
+	err = a.scanTransactions(spaceID, func(tx *Transaction) bool {
+		if tx.Time.After(at) {
+			return false
+		}
+		found = tx
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
+
+// GetBlockHistory returns the transactions with ops on the block, oldest first.
+// the transactions carry the user and time of each change for auditing, the history starts at the last compaction.
+func (a *Api) GetBlockHistory(spaceID SpaceID, blockID BlockID) ([]*Transaction, error) {
+	txs := make([]*Transaction, 0)
+	err := a.scanTransactions(spaceID, func(tx *Transaction) bool {
+		for _, op := range tx.Ops {
+			if op.BlockID == blockID {
+				txs = append(txs, tx)
+				break
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+// replay rebuilds the space in a MemStore by applying the transaction log up to and including the given transaction.
+// the replay starts from the stored snapshot of a compacted space. the transactions are applied by
+// the returned api, its move log orders the moves the same way the log was applied.
+func (a *Api) replay(spaceID SpaceID, txID TransactionID) (*Api, *MemStore, *Transaction, error) {
+	head, snapshot, err := a.logHead(spaceID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	target, err := a.store.GetTransaction(&spaceID, txID)
+	if err != nil {
+		if snapshot != nil {
+			return nil, nil, nil, fmt.Errorf("%w: %v", ErrSnapshotRequired, txID)
+		}
+		return nil, nil, nil, err
+	}
+
+	store := NewMemStore()
+	replayer := NewApi(store)
+	if snapshot != nil {
+		store.restoreSnapshot(snapshot)
+		replayer.moves.init(spaceID, snapshot.Seq, snapshot.Clock, snapshot.HLC, snapshot.moves)
+	} else {
+		spaceBlock, err := a.store.GetBlock(&spaceID, spaceID)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		err = store.CreateSpace(&Space{ID: spaceID, Name: spaceName(spaceBlock)})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		replayer.moves.init(spaceID, 0, 0, 0, nil)
+	}
+
+	if txID == head {
+		return replayer, store, target, nil
+	}
+
+	var applyErr error
+	err = a.scanTransactions(spaceID, func(tx *Transaction) bool {
+		replayed := &Transaction{
+			ID:      tx.ID,
+			SpaceID: spaceID,
+			UserID:  tx.UserID,
+			Time:    tx.Time,
+			Clock:   tx.Clock,
+			HLC:     tx.HLC,
+			Jitter:  tx.Jitter,
+			Ops:     tx.Ops,
+		}
+
+		_, _, err := replayer.applyOrdered(replayed)
+		if err != nil {
+			applyErr = fmt.Errorf("failed to replay transaction %v: %w", tx.ID, err)
+			return false
+		}
+
+		return tx.ID != txID
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if applyErr != nil {
+		return nil, nil, nil, applyErr
+	}
+
+	return replayer, store, target, nil
+}
+
+// scanTransactions calls fn with the transactions of the space after the log head, oldest first, until fn returns false.
+func (a *Api) scanTransactions(spaceID SpaceID, fn func(tx *Transaction) bool) error {
+	head, _, err := a.logHead(spaceID)
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	for {
+		txs, err := a.store.GetNextTransactions(&spaceID, head, offset, replayBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(txs) == 0 {
+			return nil
+		}
+
+		for _, tx := range txs {
+			if !fn(tx) {
+				return nil
+			}
+		}
+		offset += len(txs)
+	}
+}
+
+// logHead returns the first transaction in the log of the space, the genesis or the transaction of the stored snapshot.
+func (a *Api) logHead(spaceID SpaceID) (TransactionID, *Snapshot, error) {
+	snapshot, err := a.store.GetSnapshot(&spaceID)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	if snapshot == nil {
+		return uuid.Nil, nil, nil
+	}
+
+	return snapshot.TransactionID, snapshot, nil
+}
+
+// checkCompacted returns ErrSnapshotRequired when the transaction was removed from the log by a compaction.
+func (a *Api) checkCompacted(spaceID SpaceID, txID TransactionID) error {
+	_, err := a.store.GetTransaction(&spaceID, txID)
+	if err == nil {
+		return nil
+	}
+
+	snapshot, err := a.store.GetSnapshot(&spaceID)
+	if err != nil {
+		return err
+	}
+	if snapshot != nil {
+		return fmt.Errorf("%w: %v", ErrSnapshotRequired, txID)
+	}
+
+	return nil
+}
+
+// compaction counts the applied transactions of the spaces since their last compaction
+type compaction struct {
+	mu     sync.Mutex
+	every  int
+	counts map[SpaceID]int
+}
+
+func newCompaction() *compaction {
+	return &compaction{
+		counts: make(map[SpaceID]int),
+	}
+}
+
+// applied counts a transaction of the space and reports whether the space is due for compaction.
+func (c *compaction) applied(spaceID SpaceID) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.every <= 0 {
+		return false
+	}
+
+	c.counts[spaceID]++
+	if c.counts[spaceID] < c.every {
+		return false
+	}
+
+	delete(c.counts, spaceID)
+	return true
+}
+
+// spaceName returns the name stored in the props of the space block
+func spaceName(block *Block) string {
+	if block.Props == nil {
+		return ""
+	}
+
+	var props struct {
+		Name string `json:"name"`
+	}
+	_ = json.Unmarshal(block.Props.Content, &props)
+
+	return props.Name
+}
+
+// snapshot returns the current tree of the space as the snapshot of the transaction.
+func (ms *MemStore) snapshot(spaceID SpaceID, tx *Transaction) *Snapshot {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	backLinks := make(map[BlockID][]BlockID)
+	if space, ok := ms.spaces[spaceID]; ok {
+		for id, parents := range space.backLinks {
+			if parents.Size() > 0 {
+				backLinks[id] = parents.ToSlice()
+			}
+		}
+	}
+
+	return &Snapshot{
+		SpaceID:       spaceID,
+		TransactionID: tx.ID,
+		Time:          tx.Time,
+		Clock:         tx.Clock,
+		Seq:           tx.Seq,
+		HLC:           tx.HLC,
+		Blocks:        ms.spaceBlocks(spaceID),
+		BackLinks:     backLinks,
+	}
+}
+
+// restoreSnapshot replaces the space with the snapshot, the transaction log starts at the snapshot transaction.
+func (ms *MemStore) restoreSnapshot(snapshot *Snapshot) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	space := newSpaceStore()
+	space.txs = []*Transaction{{
+		ID:      snapshot.TransactionID,
+		SpaceID: snapshot.SpaceID,
+		Time:    snapshot.Time,
+		Clock:   snapshot.Clock,
+		Seq:     snapshot.Seq,
+		HLC:     snapshot.HLC,
+	}}
+	space.snapshot = snapshot
+
+	for _, block := range snapshot.Blocks {
+		space.AddBlock(block)
+		ms.blockSpace[block.ID] = snapshot.SpaceID
+	}
+
+	for id, parents := range snapshot.BackLinks {
+		links := NewSet[BlockID]()
+		links.Extend(parents)
+		space.backLinks[id] = *links
+	}
+
+	ms.spaces[snapshot.SpaceID] = space
+}
+
+// spaceBlocks returns all the blocks of the space, parents before children.
+func (ms *MemStore) spaceBlocks(spaceID SpaceID) []*Block {
+	space, ok := ms.spaces[spaceID]
+	if !ok {
+		return []*Block{}
+	}
+
+	blocks := make([]*Block, 0, len(space.blocks))
+	var walk func(id BlockID)
+	walk = func(id BlockID) {
+		block, ok := space.blocks[id]
+		if !ok {
+			return
+		}
+		blocks = append(blocks, block.Clone())
+
+		if children, ok := space.children[id]; ok {
+			children.Ascend(func(item *Block) bool {
+				walk(item.ID)
+				return true
+			})
+		}
+	}
+	walk(spaceID)
+
+	return blocks
+}
@@ -0,0 +1,225 @@
+package blocktree
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApi_GetDescendantsAt(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionEnd), insertOp(b2, "p2", b1, PositionEnd))
+	tx2 := createTx(s1, insertOp(b3, "p3", b1, PositionStart), updateOp(b2, []byte(`[{"op":"add","path":"/name","value":"John Doe"}]`)))
+	tx3 := createTx(s1, moveOp(b2, b1, s1, PositionEnd), deleteOp(b3))
+	_, err = api.Apply(tx1, tx2, tx3)
+	assert.NoError(t, err)
+
+	blocks, err := api.GetDescendantsAt(s1, b1, tx1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{b1, b2}, blockIDs(blocks))
+	assert.NotContains(t, blocks[1].Props.String(), "John Doe")
+
+	blocks, err = api.GetDescendantsAt(s1, b1, tx2.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{b1, b3, b2}, blockIDs(blocks))
+	assert.Equal(t, `{"name":"John Doe"}`, blocks[2].Props.String())
+
+	blocks, err = api.GetDescendantsAt(s1, b1, tx3.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{b1, b3}, blockIDs(blocks))
+	assert.True(t, blocks[1].Deleted)
+
+	blocks, err = api.GetDescendantsAt(s1, s1, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{s1}, blockIDs(blocks))
+
+	_, err = api.GetDescendantsAt(s1, b1, uuid.New())
+	assert.Error(t, err)
+}
+
+func TestApi_Snapshot(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	tx1 := createTx(s1, insertOp(b1, "page", s1, PositionEnd), insertOp(b2, "p2", b1, PositionEnd))
+	tx2 := createTx(s1, insertOp(b3, "p3", b2, PositionEnd), insertOp(b4, "p4", s1, PositionStart))
+	_, err = api.Apply(tx1, tx2)
+	assert.NoError(t, err)
+
+	snapshot, err := api.Snapshot(s1)
+	assert.NoError(t, err)
+	assert.Equal(t, tx2.ID, snapshot.TransactionID)
+	// the snapshot does not stop at pages
+	assert.Equal(t, []uuid.UUID{s1, b4, b1, b2, b3}, blockIDs(snapshot.Blocks))
+
+	snapshot, err = api.SnapshotAt(s1, tx1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, tx1.ID, snapshot.TransactionID)
+	assert.Equal(t, []uuid.UUID{s1, b1, b2}, blockIDs(snapshot.Blocks))
+
+	space, err := api.GetBlock(s1, s1)
+	assert.NoError(t, err)
+	assert.Equal(t, space.Props.String(), snapshot.Blocks[0].Props.String())
+}
+
+func TestApi_GetTransactionAt(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	yesterday := time.Now().Add(-24 * time.Hour)
+	tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionEnd))
+	tx1.Time = yesterday.Add(-time.Hour)
+	tx2 := createTx(s1, insertOp(b2, "p2", s1, PositionEnd))
+	tx2.Time = time.Now()
+	_, err = api.Apply(tx1, tx2)
+	assert.NoError(t, err)
+
+	tx, err := api.GetTransactionAt(s1, yesterday)
+	assert.NoError(t, err)
+	assert.Equal(t, tx1.ID, tx.ID)
+
+	tx, err = api.GetTransactionAt(s1, yesterday.Add(-48*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, uuid.Nil, tx.ID)
+}
+
+func TestApi_GetBlockHistory(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	u1 := uuid.New()
+	u2 := uuid.New()
+	tx1 := userTx(s1, u1, insertOp(b1, "p1", s1, PositionEnd), insertOp(b2, "p2", s1, PositionEnd))
+	tx2 := userTx(s1, u2, updateOp(b2, []byte(`[{"op":"add","path":"/name","value":"John Doe"}]`)))
+	tx3 := userTx(s1, u2, deleteOp(b1))
+	_, err = api.Apply(tx1, tx2, tx3)
+	assert.NoError(t, err)
+
+	txs, err := api.GetBlockHistory(s1, b2)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{tx1.ID, tx2.ID}, transactionIDs(txs))
+	assert.Equal(t, u2, txs[1].UserID)
+}
+
+func transactionIDs(txs []*Transaction) []TransactionID {
+	ids := make([]TransactionID, 0, len(txs))
+	for _, tx := range txs {
+		ids = append(ids, tx.ID)
+	}
+	return ids
+}
+
+func TestApi_Compact(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionEnd), insertOp(b2, "p2", b1, PositionEnd), insertOp(b3, "p3", s1, PositionEnd))
+	tx2 := createTx(s1, linkInsertOp(b4, "p4", b1))
+	tx3 := createTx(s1, linkOp(b4, b3))
+	_, err = api.Apply(tx1, tx2, tx3)
+	assert.NoError(t, err)
+
+	snapshot, err := api.Compact(s1)
+	assert.NoError(t, err)
+	assert.Equal(t, tx3.ID, snapshot.TransactionID)
+	assert.Equal(t, []uuid.UUID{b3}, snapshot.BackLinks[b4])
+
+	// the compacted transactions need the snapshot
+	_, err = api.GetUpdates(s1, tx1.ID)
+	assert.ErrorIs(t, err, ErrSnapshotRequired)
+	_, err = api.GetUpdates(s1, uuid.Nil)
+	assert.ErrorIs(t, err, ErrSnapshotRequired)
+	_, err = api.SnapshotAt(s1, tx2.ID)
+	assert.ErrorIs(t, err, ErrSnapshotRequired)
+
+	tx4 := createTx(s1, moveOp(b3, s1, b1, PositionStart), updateOp(b1, []byte(`[{"op":"add","path":"/name","value":"John Doe"}]`)))
+	_, err = api.Apply(tx4)
+	assert.NoError(t, err)
+
+	updates, err := api.GetUpdates(s1, tx3.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, tx4.ID, updates.TransactionID)
+	assert.Equal(t, []uuid.UUID{b3, b2}, updates.Children[b1])
+
+	// the replay starts from the stored snapshot
+	blocks, err := api.GetDescendantsAt(s1, s1, tx3.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{s1, b1, b2, b3, b4}, blockIDs(blocks))
+
+	current, err := api.Snapshot(s1)
+	assert.NoError(t, err)
+	assert.Equal(t, tx4.ID, current.TransactionID)
+	assert.Equal(t, []uuid.UUID{s1, b1, b3, b4, b2}, blockIDs(current.Blocks))
+	assert.Equal(t, `{"name":"John Doe"}`, current.Blocks[1].Props.String())
+	assert.Equal(t, []uuid.UUID{b3}, current.BackLinks[b4])
+}
+
+func TestApi_CompactEvery(t *testing.T) {
+	store := NewMemStore()
+	api := NewApi(store)
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+	api.CompactEvery(2)
+
+	tx1 := createTx(s1, insertOp(b1, "p1", s1, PositionEnd))
+	tx2 := createTx(s1, insertOp(b2, "p2", s1, PositionEnd))
+	tx3 := createTx(s1, insertOp(b3, "p3", s1, PositionEnd))
+	_, err = api.Apply(tx1, tx2, tx3)
+	assert.NoError(t, err)
+
+	snapshot, err := store.GetSnapshot(&s1)
+	assert.NoError(t, err)
+	assert.Equal(t, tx2.ID, snapshot.TransactionID)
+
+	txs, err := store.GetNextTransactions(&s1, tx2.ID, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{tx3.ID}, transactionIDs(txs))
+	_, err = store.GetTransaction(&s1, tx1.ID)
+	assert.Error(t, err)
+}
+
+func TestSnapshot_JSON(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	_, err = api.Apply(createTx(s1,
+		insertOp(b1, "p1", s1, PositionEnd),
+		patchOp(b1, []byte(`[{"op":"add","path":"/text","value":"hello"}]`)),
+		insertOp(b2, "p2", s1, PositionEnd),
+	))
+	assert.NoError(t, err)
+	_, err = api.Apply(createTx(s1, linkInsertOp(b3, "p3", b1)))
+	assert.NoError(t, err)
+	_, err = api.Apply(createTx(s1, linkOp(b3, b2)))
+	assert.NoError(t, err)
+
+	snapshot, err := api.Snapshot(s1)
+	assert.NoError(t, err)
+
+	data, err := json.Marshal(snapshot)
+	assert.NoError(t, err)
+
+	var decoded Snapshot
+	err = json.Unmarshal(data, &decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, snapshot.TransactionID, decoded.TransactionID)
+	assert.Equal(t, snapshot.BackLinks, decoded.BackLinks)
+	assert.Equal(t, blockIDs(snapshot.Blocks), blockIDs(decoded.Blocks))
+	for i, block := range snapshot.Blocks {
+		assert.True(t, block.Index.Equals(decoded.Blocks[i].Index))
+		assert.Equal(t, block.Props.String(), decoded.Blocks[i].Props.String())
+	}
+	assert.Equal(t, b1, decoded.Blocks[1].ID)
+	assert.Equal(t, `{"text":"hello"}`, string(decoded.Blocks[1].Json.Content))
+}
@@ -0,0 +1,198 @@
+package blocktree
+
+import (
+	"fmt"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var (
+	_ Store = (*SqliteStore)(nil)
+)
+
+// SqliteStore is a blocktree store backed by an embedded SQLite database.
+// tree queries run in the database with recursive CTEs instead of walking the tree level by level.
+type SqliteStore struct {
+	GormStore
+}
+
+// NewSqliteStore opens (or creates) the SQLite database at path and migrates the tables.
+func NewSqliteStore(path string) (*SqliteStore, error) {
+	db, err := gorm.Open(sqlite.Open(path+"?_busy_timeout=5000&_journal_mode=WAL"), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	// sqlite allows a single writer, sharing one connection avoids busy errors
+	// and keeps in-memory databases alive between queries
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	store := &SqliteStore{GormStore: GormStore{db: db}}
+	err = store.Migrate()
+	if err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Close closes the underlying database.
+func (s *SqliteStore) Close() error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.Close()
+}
+
+// Atomic runs fn with a store bound to a single database transaction, the writes of fn are committed together.
+// the store shares one connection, fn must not use another store of the same database.
+func (s *SqliteStore) Atomic(spaceID SpaceID, fn func(store Store) error) error {
+	return s.db.Transaction(func(db *gorm.DB) error {
+		return fn(&SqliteStore{GormStore: GormStore{db: db}})
+	})
+}
+
+// descendantsQuery walks down from a block, the page blocks are returned but not expanded.
+// each row carries the path of (index key, id) pairs from the root, ordering by path
+// gives the depth first order of the tree with children ordered by index bytes.
+// the path is the cursor of the block, the blocks before the start cursor are not expanded
+// unless the start cursor is below them.
+const descendantsQuery = `
+WITH RECURSIVE descendants(id, type, path, depth) AS (
+	SELECT id, type, '', 0 FROM blocks WHERE space_id = @space AND id = @id
+	UNION ALL
+	SELECT b.id, b.type, d.path || b.frac_index || '.' || b.id || '/', d.depth + 1
+	FROM blocks b JOIN descendants d ON b.parent_id = d.id
+	WHERE b.space_id = @space AND (d.type != 'page' OR d.id = @id)
+		AND (@max_depth <= 0 OR d.depth < @max_depth)
+		AND d.path >= substr(@start, 1, length(d.path))
+)
+SELECT blocks.*, descendants.path FROM blocks JOIN descendants ON blocks.id = descendants.id
+WHERE descendants.path >= @start
+ORDER BY descendants.path
+LIMIT @limit`
+
+func (s *SqliteStore) GetDescendantBlocks(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	page, err := s.QueryDescendantBlocks(spaceID, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return page.Blocks, nil
+}
+
+// QueryDescendantBlocks runs the descendants query with the depth, the start cursor and the limit of the query.
+func (s *SqliteStore) QueryDescendantBlocks(spaceID *SpaceID, id BlockID, query *BlockQuery) (*BlockPage, error) {
+	pager, err := newBlockPager(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// a negative limit is no limit in sqlite
+	limit := pager.fetch()
+	if limit == 0 {
+		limit = -1
+	}
+
+	return s.findDescendantsPage(spaceID, id, s.db.Raw(descendantsQuery, map[string]interface{}{
+		"space":     spaceID,
+		"id":        id,
+		"max_depth": pager.query.MaxDepth,
+		"start":     string(pager.query.Start),
+		"limit":     limit,
+	}), pager)
+}
+
+// ancestorsQuery walks up from the blocks until the space block is reached.
+const ancestorsQuery = `
+WITH RECURSIVE ancestors(id, parent_id) AS (
+	SELECT id, parent_id FROM blocks WHERE space_id = @space AND id IN @ids
+	UNION
+	SELECT b.id, b.parent_id
+	FROM blocks b JOIN ancestors a ON b.id = a.parent_id
+	WHERE b.space_id = @space AND a.parent_id != @space
+)
+SELECT id, parent_id FROM ancestors WHERE parent_id != @root`
+
+func (s *SqliteStore) GetAncestorEdges(spaceID *SpaceID, ids []BlockID) ([]blockEdge, error) {
+	edges := make([]blockEdge, 0)
+	if len(ids) == 0 {
+		return edges, nil
+	}
+
+	var found []BlockID
+	uniqueIDs := NewSet(ids...).ToSlice()
+	err := s.db.Model(&gormBlock{}).
+		Where("space_id = ? AND id IN (?)", spaceID, uniqueIDs).
+		Pluck("id", &found).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(found) != len(uniqueIDs) {
+		stored := NewSet(found...)
+		for _, id := range uniqueIDs {
+			if !stored.Contains(id) {
+				return nil, fmt.Errorf("non space block has no parent: %w", ErrBlockNotFound{ID: id})
+			}
+		}
+	}
+
+	var rows []*gormBlock
+	err = s.db.Raw(ancestorsQuery, map[string]interface{}{
+		"space": spaceID,
+		"ids":   uniqueIDs,
+		"root":  RootBlockID,
+	}).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		edges = append(edges, blockEdge{parentID: row.ParentID, childID: row.ID})
+	}
+
+	return edges, nil
+}
+
+// parentWithNextQuery returns the parent, the block and the next sibling in that order.
+const parentWithNextQuery = `
+WITH target AS (
+	SELECT id, parent_id, frac_index FROM blocks WHERE space_id = @space AND id = @id
+)
+SELECT * FROM (
+	SELECT b.*, 0 AS position FROM blocks b JOIN target t ON b.id = t.parent_id AND b.space_id = @space
+	UNION ALL
+	SELECT b.*, 1 AS position FROM blocks b JOIN target t ON b.id = t.id
+	UNION ALL
+	SELECT * FROM (
+		SELECT b.*, 2 AS position FROM blocks b JOIN target t ON b.parent_id = t.parent_id
+		WHERE b.space_id = @space
+			AND (b.frac_index > t.frac_index OR (b.frac_index = t.frac_index AND b.id > t.id))
+		ORDER BY b.frac_index ASC, b.id ASC
+		LIMIT 1
+	)
+)
+ORDER BY position`
+
+func (s *SqliteStore) GetParentWithNextBlock(spaceID *SpaceID, id BlockID) ([]*Block, error) {
+	blocks, err := s.findBlocks(s.db.Raw(parentWithNextQuery, map[string]interface{}{
+		"space": spaceID,
+		"id":    id,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(blocks) < 2 || blocks[1].ID != id {
+		return nil, fmt.Errorf("parent block for %v: %w", id, ErrNotFound)
+	}
+
+	return blocks, nil
+}
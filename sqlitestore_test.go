@@ -0,0 +1,95 @@
+package blocktree
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqliteStore_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocktree.db")
+	store, err := NewSqliteStore(path)
+	assert.NoError(t, err)
+
+	err = createSpace(store, s1)
+	assert.NoError(t, err)
+	applyTransaction(t, store, createTx(s1,
+		insertOp(b1, "p1", s1, PositionEnd),
+		insertOp(b2, "p2", b1, PositionEnd),
+	))
+	assert.NoError(t, store.Close())
+
+	store, err = NewSqliteStore(path)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	blocks, err := store.GetDescendantBlocks(&s1, s1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(blocks))
+
+	txs, err := store.GetNextTransactions(&s1, uuid.Nil, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(txs))
+}
+
+func TestSqliteStore_DescendantOrder(t *testing.T) {
+	store, err := NewSqliteStore(filepath.Join(t.TempDir(), "blocktree.db"))
+	assert.NoError(t, err)
+	defer store.Close()
+	memStore := NewMemStore()
+
+	for _, s := range []Store{store, memStore} {
+		err = createSpace(s, s1)
+		assert.NoError(t, err)
+	}
+
+	// repeated inserts at the same spot grow the index bytes
+	txs := []*Transaction{
+		createTx(s1, insertOp(b1, "p1", s1, PositionEnd), insertOp(b2, "p2", s1, PositionEnd)),
+	}
+	prev := b1
+	for i := 0; i < 40; i++ {
+		id := uuid.New()
+		txs = append(txs, createTx(s1, insertOp(id, "p", prev, PositionAfter)))
+		if i%3 == 0 {
+			txs = append(txs, createTx(s1, insertOp(uuid.New(), "p", id, PositionStart)))
+		}
+		prev = id
+	}
+	txs = append(txs, createTx(s1, insertOp(b3, "page", b2, PositionStart), insertOp(b4, "p4", b3, PositionEnd)))
+
+	for _, tx := range txs {
+		applyTransaction(t, store, tx)
+		applyTransaction(t, memStore, tx)
+	}
+
+	expected, err := memStore.GetDescendantBlocks(&s1, s1)
+	assert.NoError(t, err)
+	actual, err := store.GetDescendantBlocks(&s1, s1)
+	assert.NoError(t, err)
+	assert.Equal(t, len(expected), len(actual))
+	for i := range expected {
+		assert.Equal(t, expected[i].ID, actual[i].ID)
+	}
+
+	edges, err := store.GetAncestorEdges(&s1, []BlockID{b4, prev})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []blockEdge{
+		{parentID: b3, childID: b4},
+		{parentID: b2, childID: b3},
+		{parentID: s1, childID: b2},
+		{parentID: s1, childID: prev},
+	}, edges)
+
+	blocks, err := store.GetParentWithNextBlock(&s1, b1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(blocks))
+	assert.Equal(t, s1, blocks[0].ID)
+	assert.Equal(t, b1, blocks[1].ID)
+
+	memBlocks, err := memStore.GetParentWithNextBlock(&s1, b1)
+	assert.NoError(t, err)
+	assert.Equal(t, memBlocks[2].ID, blocks[2].ID)
+}
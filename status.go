@@ -0,0 +1,256 @@
+package blocktree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is the domain of the ErrorInfo details attached to the gRPC errors of the blocktree service
+const ErrorDomain = "blocktree"
+
+// the reasons of the ErrorInfo details, clients branch on them or get the typed error back with ErrorFromStatus
+const (
+	ReasonSpaceNotFound       = "SPACE_NOT_FOUND"
+	ReasonBlockNotFound       = "BLOCK_NOT_FOUND"
+	ReasonTransactionNotFound = "TRANSACTION_NOT_FOUND"
+	ReasonNotFound            = "NOT_FOUND"
+	ReasonBlockExists         = "BLOCK_EXISTS"
+	ReasonSpaceExists         = "SPACE_EXISTS"
+	ReasonDuplicate           = "DUPLICATE"
+	ReasonInvalidPosition     = "INVALID_POSITION"
+	ReasonInvalidOp           = "INVALID_OP"
+	ReasonSchemaViolation     = "SCHEMA_VIOLATION"
+	ReasonCycle               = "CYCLE"
+	ReasonConflict            = "CONFLICT"
+	ReasonSnapshotRequired    = "SNAPSHOT_REQUIRED"
+	ReasonNotSupported        = "NOT_SUPPORTED"
+	ReasonInternal            = "INTERNAL"
+)
+
+// errorInfo is the status code and the ErrorInfo detail of an error
+type errorInfo struct {
+	code     codes.Code
+	reason   string
+	metadata map[string]string
+}
+
+// infoOf returns the status code, the reason and the ids of the error.
+// the typed errors are checked before the sentinel errors they wrap.
+func infoOf(err error) errorInfo {
+	var (
+		spaceNotFound ErrSpaceNotFound
+		blockNotFound ErrBlockNotFound
+		txNotFound    ErrTransactionNotFound
+		blockExists   ErrBlockExists
+		spaceExists   ErrSpaceExists
+		conflict      *ConflictError
+		schemaErr     *SchemaError
+	)
+
+	switch {
+	case errors.As(err, &spaceNotFound):
+		return errorInfo{codes.NotFound, ReasonSpaceNotFound, map[string]string{"space_id": spaceNotFound.ID.String()}}
+	case errors.As(err, &blockNotFound):
+		return errorInfo{codes.NotFound, ReasonBlockNotFound, map[string]string{"block_id": blockNotFound.ID.String()}}
+	case errors.As(err, &txNotFound):
+		return errorInfo{codes.NotFound, ReasonTransactionNotFound, map[string]string{"transaction_id": txNotFound.ID.String()}}
+	case errors.As(err, &blockExists):
+		return errorInfo{codes.AlreadyExists, ReasonBlockExists, map[string]string{"block_id": blockExists.ID.String()}}
+	case errors.As(err, &spaceExists):
+		return errorInfo{codes.AlreadyExists, ReasonSpaceExists, map[string]string{"space_id": spaceExists.ID.String()}}
+	case errors.As(err, &conflict):
+		ids := make([]string, 0, len(conflict.BlockIDs))
+		for _, id := range conflict.BlockIDs {
+			ids = append(ids, id.String())
+		}
+		return errorInfo{codes.Aborted, ReasonConflict, map[string]string{
+			"transaction_id": conflict.TransactionID.String(),
+			"seq":            strconv.FormatUint(conflict.Seq, 10),
+			"block_ids":      strings.Join(ids, ","),
+		}}
+	case errors.As(err, &schemaErr):
+		return errorInfo{codes.InvalidArgument, ReasonSchemaViolation, map[string]string{
+			"block_id": schemaErr.BlockID.String(),
+			"type":     schemaErr.Type,
+			"reason":   schemaErr.Reason,
+		}}
+	case errors.Is(err, ErrNotFound):
+		return errorInfo{codes.NotFound, ReasonNotFound, nil}
+	case errors.Is(err, ErrDuplicate):
+		return errorInfo{codes.AlreadyExists, ReasonDuplicate, nil}
+	case errors.Is(err, ErrInvalidPosition):
+		return errorInfo{codes.InvalidArgument, ReasonInvalidPosition, nil}
+	case errors.Is(err, ErrInvalidOp):
+		return errorInfo{codes.InvalidArgument, ReasonInvalidOp, nil}
+	case errors.Is(err, ErrCreatesCycle) || errors.Is(err, ErrDetectedCycle):
+		return errorInfo{codes.FailedPrecondition, ReasonCycle, nil}
+	case errors.Is(err, ErrSnapshotRequired):
+		// the client re-downloads the space with GetSnapshot
+		return errorInfo{codes.FailedPrecondition, ReasonSnapshotRequired, nil}
+	case errors.Is(err, ErrSubscribeNotSupported):
+		return errorInfo{codes.Unimplemented, ReasonNotSupported, nil}
+	default:
+		return errorInfo{codes.Internal, ReasonInternal, nil}
+	}
+}
+
+// grpcError turns the error into a gRPC status with an ErrorInfo detail.
+// the errors that already are a status and the context errors keep their code.
+func grpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	info := infoOf(err)
+	st, derr := status.New(info.code, err.Error()).WithDetails(&errdetails.ErrorInfo{
+		Reason:   info.reason,
+		Domain:   ErrorDomain,
+		Metadata: info.metadata,
+	})
+	if derr != nil {
+		return status.Error(info.code, err.Error())
+	}
+
+	return st.Err()
+}
+
+// invalidArgument returns an InvalidArgument status with the field violation of the request
+func invalidArgument(field string, err error) error {
+	st, derr := status.New(codes.InvalidArgument, fmt.Sprintf("invalid %s: %v", field, err)).WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: field, Description: err.Error()},
+		},
+	})
+	if derr != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return st.Err()
+}
+
+// parseID parses the id in the field of a request
+func parseID(field, value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, invalidArgument(field, err)
+	}
+
+	return id, nil
+}
+
+// parsePageToken parses the page token of a request into the start cursor of the query
+func parsePageToken(token string) (BlockCursor, error) {
+	cursor, err := ParseBlockCursor(token)
+	if err != nil {
+		return "", invalidArgument("page_token", err)
+	}
+
+	return cursor, nil
+}
+
+// statusError is an error received from the blocktree service, errors.Is matches the sentinel error of its reason
+type statusError struct {
+	status *status.Status
+	err    error
+}
+
+func (e *statusError) Error() string {
+	return e.status.Message()
+}
+
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+func (e *statusError) GRPCStatus() *status.Status {
+	return e.status
+}
+
+// ErrorFromStatus returns the typed error of a gRPC error returned by the blocktree service.
+// errors.Is matches the sentinel errors and errors.As gives the ErrBlockNotFound, ConflictError etc. with their ids,
+// the errors without a blocktree ErrorInfo detail are returned as they are.
+func ErrorFromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st == nil {
+		return err
+	}
+
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetDomain() != ErrorDomain {
+			continue
+		}
+
+		meta := info.GetMetadata()
+		switch info.GetReason() {
+		case ReasonSpaceNotFound:
+			return ErrSpaceNotFound{ID: metadataID(meta, "space_id")}
+		case ReasonBlockNotFound:
+			return ErrBlockNotFound{ID: metadataID(meta, "block_id")}
+		case ReasonTransactionNotFound:
+			return ErrTransactionNotFound{ID: metadataID(meta, "transaction_id")}
+		case ReasonBlockExists:
+			return ErrBlockExists{ID: metadataID(meta, "block_id")}
+		case ReasonSpaceExists:
+			return ErrSpaceExists{ID: metadataID(meta, "space_id")}
+		case ReasonConflict:
+			return conflictFromMetadata(meta)
+		case ReasonSchemaViolation:
+			return &SchemaError{BlockID: metadataID(meta, "block_id"), Type: meta["type"], Reason: meta["reason"]}
+		case ReasonNotFound:
+			return &statusError{status: st, err: ErrNotFound}
+		case ReasonDuplicate:
+			return &statusError{status: st, err: ErrDuplicate}
+		case ReasonInvalidPosition:
+			return &statusError{status: st, err: ErrInvalidPosition}
+		case ReasonInvalidOp:
+			return &statusError{status: st, err: ErrInvalidOp}
+		case ReasonCycle:
+			return &statusError{status: st, err: ErrCreatesCycle}
+		case ReasonSnapshotRequired:
+			return &statusError{status: st, err: ErrSnapshotRequired}
+		case ReasonNotSupported:
+			return &statusError{status: st, err: ErrSubscribeNotSupported}
+		}
+	}
+
+	return err
+}
+
+// conflictFromMetadata returns the ConflictError of the ErrorInfo metadata
+func conflictFromMetadata(meta map[string]string) *ConflictError {
+	conflict := &ConflictError{}
+	conflict.TransactionID = metadataID(meta, "transaction_id")
+	conflict.Seq, _ = strconv.ParseUint(meta["seq"], 10, 64)
+	if meta["block_ids"] != "" {
+		for _, id := range strings.Split(meta["block_ids"], ",") {
+			blockID, err := uuid.Parse(id)
+			if err != nil {
+				continue
+			}
+			conflict.BlockIDs = append(conflict.BlockIDs, blockID)
+		}
+	}
+
+	return conflict
+}
+
+// metadataID returns the id under the key of the ErrorInfo metadata, uuid.Nil when it is missing
+func metadataID(meta map[string]string, key string) uuid.UUID {
+	id, _ := uuid.Parse(meta[key])
+	return id
+}
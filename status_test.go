@@ -0,0 +1,137 @@
+package blocktree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	v1 "github.com/emrgen/blocktree/apis/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGrpcError(t *testing.T) {
+	tests := []struct {
+		err    error
+		code   codes.Code
+		reason string
+	}{
+		{ErrSpaceNotFound{ID: s1}, codes.NotFound, ReasonSpaceNotFound},
+		{fmt.Errorf("move block: %w", ErrBlockNotFound{ID: b1}), codes.NotFound, ReasonBlockNotFound},
+		{ErrTransactionNotFound{ID: b1}, codes.NotFound, ReasonTransactionNotFound},
+		{ErrBlockExists{ID: b1}, codes.AlreadyExists, ReasonBlockExists},
+		{ErrSpaceExists{ID: s1}, codes.AlreadyExists, ReasonSpaceExists},
+		{fmt.Errorf("%w: cannot move inside a block", ErrInvalidPosition), codes.InvalidArgument, ReasonInvalidPosition},
+		{fmt.Errorf("%w: transaction has no ops", ErrInvalidOp), codes.InvalidArgument, ReasonInvalidOp},
+		{ErrCreatesCycle, codes.FailedPrecondition, ReasonCycle},
+		{fmt.Errorf("%w: seq 1", ErrSnapshotRequired), codes.FailedPrecondition, ReasonSnapshotRequired},
+		{&ConflictError{TransactionID: b1, Seq: 2, BlockIDs: []BlockID{b2}}, codes.Aborted, ReasonConflict},
+		{&SchemaError{BlockID: b1, Type: "para", Reason: "cannot be linked"}, codes.InvalidArgument, ReasonSchemaViolation},
+		{ErrSubscribeNotSupported, codes.Unimplemented, ReasonNotSupported},
+		{errors.New("disk is full"), codes.Internal, ReasonInternal},
+	}
+
+	for _, tt := range tests {
+		st, ok := status.FromError(grpcError(tt.err))
+		require.True(t, ok)
+		assert.Equal(t, tt.code, st.Code(), tt.err.Error())
+		assert.Equal(t, tt.err.Error(), st.Message())
+
+		require.Len(t, st.Details(), 1)
+		info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		assert.Equal(t, ErrorDomain, info.GetDomain())
+		assert.Equal(t, tt.reason, info.GetReason())
+	}
+
+	// the status errors and the context errors keep their code
+	st, _ := status.FromError(grpcError(status.Error(codes.InvalidArgument, "bad request")))
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	st, _ = status.FromError(grpcError(context.Canceled))
+	assert.Equal(t, codes.Canceled, st.Code())
+	assert.NoError(t, grpcError(nil))
+}
+
+func TestErrorFromStatus(t *testing.T) {
+	err := ErrorFromStatus(grpcError(fmt.Errorf("insert block: %w", ErrBlockNotFound{ID: b1})))
+	assert.ErrorIs(t, err, ErrNotFound)
+	var notFound ErrBlockNotFound
+	require.True(t, errors.As(err, &notFound))
+	assert.Equal(t, b1, notFound.ID)
+
+	err = ErrorFromStatus(grpcError(&ConflictError{TransactionID: b1, Seq: 2, BlockIDs: []BlockID{b2, b3}}))
+	var conflict *ConflictError
+	require.True(t, errors.As(err, &conflict))
+	assert.Equal(t, &ConflictError{TransactionID: b1, Seq: 2, BlockIDs: []BlockID{b2, b3}}, conflict)
+
+	err = ErrorFromStatus(grpcError(&SchemaError{BlockID: b1, Type: "para", Reason: "cannot be linked"}))
+	var schemaErr *SchemaError
+	require.True(t, errors.As(err, &schemaErr))
+	assert.Equal(t, &SchemaError{BlockID: b1, Type: "para", Reason: "cannot be linked"}, schemaErr)
+
+	err = ErrorFromStatus(grpcError(fmt.Errorf("%w: seq 1", ErrSnapshotRequired)))
+	assert.ErrorIs(t, err, ErrSnapshotRequired)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// the errors of other services are kept
+	other := status.Error(codes.Unavailable, "connection refused")
+	assert.Equal(t, other, ErrorFromStatus(other))
+}
+
+func TestGrpcApi_Errors(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	require.NoError(t, err)
+	server := newGrpcApi(api)
+
+	_, err = server.GetBlock(context.Background(), &v1.GetBlockRequest{BlockId: b1.String()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	var notFound ErrBlockNotFound
+	require.True(t, errors.As(ErrorFromStatus(err), &notFound))
+	assert.Equal(t, b1, notFound.ID)
+
+	_, err = server.GetBlock(context.Background(), &v1.GetBlockRequest{BlockId: "b1"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.GetUpdates(context.Background(), &v1.GetUpdatesRequest{SpaceId: s2.String()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	var spaceNotFound ErrSpaceNotFound
+	require.True(t, errors.As(ErrorFromStatus(err), &spaceNotFound))
+	assert.Equal(t, s2, spaceNotFound.ID)
+
+	_, err = server.CreateSpace(context.Background(), &v1.CreateSpaceRequest{SpaceId: s1.String(), Name: "test-1"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	var spaceExists ErrSpaceExists
+	require.True(t, errors.As(ErrorFromStatus(err), &spaceExists))
+	assert.Equal(t, s1, spaceExists.ID)
+}
+
+func TestApi_TypedErrors(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	require.NoError(t, err)
+
+	// the parent b9 does not exist
+	_, err = api.Apply(createTx(s1, insertOp(b1, "p1", b9, PositionEnd)))
+	var notFound ErrBlockNotFound
+	require.True(t, errors.As(err, &notFound))
+	assert.Equal(t, b9, notFound.ID)
+
+	_, err = api.Apply(createTx(s1, insertOp(b1, "p1", s1, PositionEnd)))
+	require.NoError(t, err)
+	_, err = api.Apply(createTx(s1, insertOp(b1, "p1", s1, PositionEnd)))
+	var exists ErrBlockExists
+	require.True(t, errors.As(err, &exists))
+	assert.Equal(t, b1, exists.ID)
+
+	_, err = api.Apply(createTx(s1))
+	assert.ErrorIs(t, err, ErrInvalidOp)
+
+	_, err = api.GetBlock(s1, b2)
+	require.True(t, errors.As(err, &notFound))
+	assert.Equal(t, b2, notFound.ID)
+}
@@ -1,5 +1,7 @@
 package blocktree
 
+import "encoding/json"
+
 type storeChange struct {
 	blockChange   *blockChange
 	jsonDocChange []*JsonPatch
@@ -32,11 +34,14 @@ func (sc *storeChange) intoSyncBlocks() *SyncBlocks {
 
 // SyncBlocks is a set of blocks that have been updated and need to be synced with the clients
 type SyncBlocks struct {
-	children *Set[BlockID]
-	inserted *Set[BlockID]
-	patched  *Set[BlockID]
-	updated  *Set[BlockID]
-	props    *Set[BlockID]
+	// spaceID and transactionID are set on the published changes of a single transaction
+	spaceID       SpaceID
+	transactionID TransactionID
+	children      *Set[BlockID]
+	inserted      *Set[BlockID]
+	patched       *Set[BlockID]
+	updated       *Set[BlockID]
+	props         *Set[BlockID]
 }
 
 // NewSyncBlocks creates a new SyncBlocks object
@@ -50,6 +55,42 @@ func NewSyncBlocks() *SyncBlocks {
 	}
 }
 
+// syncBlocksJSON is the stored form of SyncBlocks.
+type syncBlocksJSON struct {
+	Children []BlockID `json:"children"`
+	Inserted []BlockID `json:"inserted"`
+	Patched  []BlockID `json:"patched"`
+	Updated  []BlockID `json:"updated"`
+	Props    []BlockID `json:"props"`
+}
+
+func (sb *SyncBlocks) MarshalJSON() ([]byte, error) {
+	return json.Marshal(syncBlocksJSON{
+		Children: sb.children.ToSlice(),
+		Inserted: sb.inserted.ToSlice(),
+		Patched:  sb.patched.ToSlice(),
+		Updated:  sb.updated.ToSlice(),
+		Props:    sb.props.ToSlice(),
+	})
+}
+
+func (sb *SyncBlocks) UnmarshalJSON(data []byte) error {
+	var stored syncBlocksJSON
+	err := json.Unmarshal(data, &stored)
+	if err != nil {
+		return err
+	}
+
+	*sb = *NewSyncBlocks()
+	sb.children.Extend(stored.Children)
+	sb.inserted.Extend(stored.Inserted)
+	sb.patched.Extend(stored.Patched)
+	sb.updated.Extend(stored.Updated)
+	sb.props.Extend(stored.Props)
+
+	return nil
+}
+
 func (sb *SyncBlocks) extend(other *SyncBlocks) {
 	sb.children.Extend(other.children.ToSlice())
 	sb.patched.Extend(other.patched.ToSlice())
@@ -70,7 +111,7 @@ func (sb *SyncBlocks) dirty() *Set[BlockID] {
 }
 
 func (sb *SyncBlocks) IsEmpty() bool {
-	return sb.children.Size() == 0 && sb.patched.Size() == 0 && sb.updated.Size() == 0 && sb.props.Size() == 0
+	return sb.children.Size() == 0 && sb.inserted.Size() == 0 && sb.patched.Size() == 0 && sb.updated.Size() == 0 && sb.props.Size() == 0
 }
 
 // BlockStore is a store for blocks
@@ -93,6 +134,10 @@ type BlockStore interface {
 	GetBackLinks(spaceID *SpaceID, id BlockID) ([]*Block, error)
 	// GetDescendantBlocks returns the descendants of the block with the given id
 	GetDescendantBlocks(spaceID *SpaceID, id BlockID) ([]*Block, error)
+	// QueryChildrenBlocks returns a page of the children of the block with the given id, the max depth is ignored
+	QueryChildrenBlocks(spaceID *SpaceID, id BlockID, query *BlockQuery) (*BlockPage, error)
+	// QueryDescendantBlocks returns a page of the block and its descendants in depth first order
+	QueryDescendantBlocks(spaceID *SpaceID, id BlockID, query *BlockQuery) (*BlockPage, error)
 	// GetParentBlock returns the parent of the block with the given id
 	GetParentBlock(spaceID *SpaceID, id BlockID) (*Block, error)
 	// GetBlocks returns the blocks with the given ids
@@ -118,6 +163,12 @@ type TransactionStore interface {
 	PutTransaction(spaceID *SpaceID, tx *Transaction) error
 	// GetNextTransactions returns the next transactions in the store
 	GetNextTransactions(spaceID *SpaceID, id TransactionID, start, limit int) ([]*Transaction, error)
+	// GetTransactionsSince returns at most limit transactions with a seq after the given seq, in seq order
+	GetTransactionsSince(spaceID *SpaceID, seq uint64, limit int) ([]*Transaction, error)
+	// GetSnapshot returns the snapshot the transaction log starts at, nil when the log was never compacted
+	GetSnapshot(spaceID *SpaceID) (*Snapshot, error)
+	// PutSnapshot stores the snapshot and removes the transactions before the snapshot transaction from the log
+	PutSnapshot(snapshot *Snapshot) error
 }
 
 // JsonDocStore is a store for JSON documents
@@ -129,6 +180,11 @@ type Store interface {
 	TransactionStore
 	JsonDocStore
 
-	// Apply applies blocktree change to db in one transaction
-	Apply(tx *Transaction, change *storeChange) error
+	// Apply applies blocktree change to db in one transaction and returns the applied change,
+	// a store that prepares the change again returns the change it applied
+	Apply(tx *Transaction, change *storeChange) (*storeChange, error)
+
+	// Atomic runs fn with a store whose writes are committed together, they are rolled back when fn fails.
+	// the other writers of the space wait until fn returns
+	Atomic(spaceID SpaceID, fn func(store Store) error) error
 }
@@ -1,11 +0,0 @@
-package blocktree
-
-import "testing"
-
-func StoreCreateSpaceTest(store, t *testing.T) {
-
-}
-
-func StoreCreateBlockTest(store, t *testing.T) {
-
-}
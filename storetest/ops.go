@@ -0,0 +1,91 @@
+package storetest
+
+import (
+	"time"
+
+	"github.com/emrgen/blocktree"
+	"github.com/google/uuid"
+)
+
+func tx(spaceID blocktree.SpaceID, ops ...blocktree.Op) *blocktree.Transaction {
+	return &blocktree.Transaction{
+		ID:      uuid.New(),
+		SpaceID: spaceID,
+		UserID:  uuid.Nil,
+		Time:    time.Now(),
+		Ops:     ops,
+	}
+}
+
+func op(opType blocktree.OpType, blockID blocktree.BlockID) blocktree.Op {
+	return blocktree.Op{
+		Table:   "block",
+		Type:    opType,
+		BlockID: blockID,
+	}
+}
+
+func insertOp(blockID blocktree.BlockID, object string, refID blocktree.BlockID, pos blocktree.PointerPosition) blocktree.Op {
+	return blocktree.Op{
+		Table:   "block",
+		Type:    blocktree.OpTypeInsert,
+		Object:  object,
+		BlockID: blockID,
+		At: &blocktree.Pointer{
+			BlockID:  refID,
+			Position: pos,
+		},
+	}
+}
+
+func linkInsertOp(blockID blocktree.BlockID, object string, refID blocktree.BlockID) blocktree.Op {
+	insert := insertOp(blockID, object, refID, blocktree.PositionInside)
+	insert.Linked = true
+	return insert
+}
+
+func moveOp(blockID blocktree.BlockID, from, refID blocktree.BlockID, pos blocktree.PointerPosition) blocktree.Op {
+	return blocktree.Op{
+		Table:    "block",
+		Type:     blocktree.OpTypeMove,
+		BlockID:  blockID,
+		ParentID: &from,
+		At: &blocktree.Pointer{
+			BlockID:  refID,
+			Position: pos,
+		},
+	}
+}
+
+func updateOp(blockID blocktree.BlockID, props string) blocktree.Op {
+	update := op(blocktree.OpTypeUpdate, blockID)
+	update.Props = []byte(props)
+	return update
+}
+
+func patchOp(blockID blocktree.BlockID, patch string) blocktree.Op {
+	p := op(blocktree.OpTypePatch, blockID)
+	p.Patch = []byte(patch)
+	return p
+}
+
+func deleteOp(blockID blocktree.BlockID) blocktree.Op {
+	return op(blocktree.OpTypeDelete, blockID)
+}
+
+func eraseOp(blockID blocktree.BlockID) blocktree.Op {
+	return op(blocktree.OpTypeErase, blockID)
+}
+
+func linkOp(blockID blocktree.BlockID, refID blocktree.BlockID) blocktree.Op {
+	link := op(blocktree.OpTypeLink, blockID)
+	link.At = &blocktree.Pointer{
+		BlockID:  refID,
+		Position: blocktree.PositionInside,
+	}
+	return link
+}
+
+func unlinkOp(blockID blocktree.BlockID) blocktree.Op {
+	return op(blocktree.OpTypeUnlink, blockID)
+}
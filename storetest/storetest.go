@@ -0,0 +1,768 @@
+// Package storetest is a conformance test suite for blocktree.Store implementations.
+//
+// A store implementation is expected to behave like the blocktree.MemStore,
+// Run checks the store methods one by one and replays transaction sequences
+// through the blocktree.Api on both stores, comparing the resulting trees.
+package storetest
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/emrgen/blocktree"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NewStore returns a new empty store for a single test.
+type NewStore func() blocktree.Store
+
+var (
+	s1 = uuid.MustParse("00000000-0000-0000-0002-000000000001")
+	s2 = uuid.MustParse("00000000-0000-0000-0002-000000000002")
+	b1 = uuid.MustParse("00000000-0000-0000-0003-000000000001")
+	b2 = uuid.MustParse("00000000-0000-0000-0003-000000000002")
+	b3 = uuid.MustParse("00000000-0000-0000-0003-000000000003")
+	b4 = uuid.MustParse("00000000-0000-0000-0003-000000000004")
+	b5 = uuid.MustParse("00000000-0000-0000-0003-000000000005")
+	b6 = uuid.MustParse("00000000-0000-0000-0003-000000000006")
+	b7 = uuid.MustParse("00000000-0000-0000-0003-000000000007")
+)
+
+// Run runs the conformance suite against the stores created by newStore.
+func Run(t *testing.T, newStore NewStore) {
+	t.Run("CreateSpace", func(t *testing.T) { testCreateSpace(t, newStore()) })
+	t.Run("CreateBlock", func(t *testing.T) { testCreateBlock(t, newStore()) })
+	t.Run("Children", func(t *testing.T) { testChildren(t, newStore()) })
+	t.Run("Siblings", func(t *testing.T) { testSiblings(t, newStore()) })
+	t.Run("Descendants", func(t *testing.T) { testDescendants(t, newStore()) })
+	t.Run("Queries", func(t *testing.T) { testQueries(t, newStore()) })
+	t.Run("AncestorEdges", func(t *testing.T) { testAncestorEdges(t, newStore()) })
+	t.Run("Links", func(t *testing.T) { testLinks(t, newStore()) })
+	t.Run("Transactions", func(t *testing.T) { testTransactions(t, newStore()) })
+	t.Run("Compaction", func(t *testing.T) { testCompaction(t, newStore()) })
+	t.Run("Atomic", func(t *testing.T) { testAtomic(t, newStore()) })
+
+	for _, sequence := range sequences {
+		sequence := sequence
+		t.Run("Sequence/"+sequence.name, func(t *testing.T) {
+			testSequence(t, newStore(), sequence.txs())
+		})
+	}
+}
+
+func testCreateSpace(t *testing.T, store blocktree.Store) {
+	err := store.CreateSpace(&blocktree.Space{ID: s1, Name: "s1"})
+	require.NoError(t, err)
+
+	err = store.CreateSpace(&blocktree.Space{ID: s1, Name: "s1"})
+	assert.ErrorIs(t, err, blocktree.ErrDuplicate, "space should not be created twice")
+	assert.ErrorAs(t, err, &blocktree.ErrSpaceExists{})
+
+	block, err := store.GetBlock(&s1, s1)
+	require.NoError(t, err)
+	assert.Equal(t, "space", block.Type)
+	assert.Equal(t, blocktree.RootBlockID, block.ParentID)
+
+	spaceID, err := store.GetBlockSpaceID(&s1)
+	require.NoError(t, err)
+	assert.Equal(t, s1, *spaceID)
+
+	_, err = store.GetLatestTransaction(&s1)
+	assert.NoError(t, err)
+}
+
+func testCreateBlock(t *testing.T, store blocktree.Store) {
+	createSpaces(t, store, s1, s2)
+
+	block := blocktree.NewBlock(b1, s1, "p1")
+	block.Props = blocktree.NewJsonDoc([]byte(`{"title":"b1"}`))
+	block.Json = blocktree.NewJsonDoc([]byte(`{"text":"hello"}`))
+	err := store.CreateBlock(&s1, block)
+	require.NoError(t, err)
+
+	got, err := store.GetBlock(&s1, b1)
+	require.NoError(t, err)
+	assertSameBlocks(t, []*blocktree.Block{block}, []*blocktree.Block{got})
+
+	spaceID, err := store.GetBlockSpaceID(&b1)
+	require.NoError(t, err)
+	assert.Equal(t, s1, *spaceID)
+
+	_, err = store.GetBlock(&s1, b2)
+	assert.Equal(t, blocktree.ErrBlockNotFound{ID: b2}, err, "missing block should not be found")
+
+	_, err = store.GetBlock(&s2, b1)
+	assert.ErrorIs(t, err, blocktree.ErrNotFound, "block should not be found in another space")
+
+	_, err = store.GetBlockSpaceID(&b2)
+	assert.Equal(t, blocktree.ErrBlockNotFound{ID: b2}, err, "missing block should not have a space")
+
+	blocks, err := store.GetBlocks(&s1, []blocktree.BlockID{b1, b2})
+	require.NoError(t, err)
+	assertSameBlocks(t, []*blocktree.Block{block}, blocks)
+}
+
+func testChildren(t *testing.T, store blocktree.Store) {
+	api := blocktree.NewApi(store)
+	createSpaces(t, store, s1)
+	apply(t, api,
+		tx(s1, insertOp(b1, "p1", s1, blocktree.PositionEnd)),
+		tx(s1, insertOp(b2, "p2", s1, blocktree.PositionStart)),
+		tx(s1, insertOp(b3, "p3", b2, blocktree.PositionAfter)),
+		tx(s1, linkInsertOp(b4, "l1", b1)),
+		tx(s1, insertOp(b5, "p5", b1, blocktree.PositionEnd)),
+	)
+
+	blocks, err := store.GetChildrenBlocks(&s1, s1)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{b2, b3, b1}, blockIDs(blocks), "children should be in index order")
+
+	blocks, err = store.GetChildrenBlocks(&s1, b1)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{b5}, blockIDs(blocks), "children should not include linked blocks")
+
+	ids, err := store.GetChildrenBlockIDs(&s1, b1)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []blocktree.BlockID{b4, b5}, ids, "children ids should include linked blocks")
+
+	blocks, err = store.GetLinkedBlocks(&s1, b1)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{b4}, blockIDs(blocks))
+
+	blocks, err = store.GetChildrenBlocks(&s1, b3)
+	require.NoError(t, err)
+	assert.Empty(t, blocks)
+
+	ids, err = store.GetChildrenBlockIDs(&s1, b3)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+
+	parent, err := store.GetParentBlock(&s1, b3)
+	require.NoError(t, err)
+	assert.Equal(t, s1, parent.ID)
+
+	_, err = store.GetParentBlock(&s1, s1)
+	assert.Error(t, err, "space block should not have a parent block")
+}
+
+func testSiblings(t *testing.T, store blocktree.Store) {
+	api := blocktree.NewApi(store)
+	createSpaces(t, store, s1)
+	apply(t, api, tx(s1,
+		insertOp(b1, "p1", s1, blocktree.PositionEnd),
+		insertOp(b2, "p2", s1, blocktree.PositionEnd),
+		insertOp(b3, "p3", s1, blocktree.PositionEnd),
+	))
+
+	blocks, err := store.GetWithFirstChildBlock(&s1, s1)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{s1, b1}, blockIDs(blocks))
+
+	blocks, err = store.GetWithLastChildBlock(&s1, s1)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{s1, b3}, blockIDs(blocks))
+
+	blocks, err = store.GetWithFirstChildBlock(&s1, b1)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{b1}, blockIDs(blocks))
+
+	blocks, err = store.GetWithLastChildBlock(&s1, b1)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{b1}, blockIDs(blocks))
+
+	blocks, err = store.GetParentWithNextBlock(&s1, b2)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{s1, b2, b3}, blockIDs(blocks))
+
+	blocks, err = store.GetParentWithNextBlock(&s1, b3)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{s1, b3}, blockIDs(blocks))
+
+	blocks, err = store.GetParentWithPrevBlock(&s1, b2)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{s1, b2, b1}, blockIDs(blocks))
+
+	blocks, err = store.GetParentWithPrevBlock(&s1, b1)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{s1, b1}, blockIDs(blocks))
+
+	_, err = store.GetParentWithNextBlock(&s1, b4)
+	assert.Error(t, err, "missing block should not have siblings")
+
+	_, err = store.GetWithFirstChildBlock(&s1, b4)
+	assert.Error(t, err, "missing block should not have children")
+}
+
+func testDescendants(t *testing.T, store blocktree.Store) {
+	api := blocktree.NewApi(store)
+	createSpaces(t, store, s1)
+	apply(t, api, tx(s1,
+		insertOp(b1, "p1", s1, blocktree.PositionEnd),
+		insertOp(b2, "p2", b1, blocktree.PositionEnd),
+		insertOp(b3, "page", b1, blocktree.PositionEnd),
+		insertOp(b4, "p4", b3, blocktree.PositionEnd),
+		insertOp(b5, "p5", s1, blocktree.PositionEnd),
+	))
+
+	blocks, err := store.GetDescendantBlocks(&s1, b1)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{b1, b2, b3}, blockIDs(blocks), "descendants should stop at page blocks")
+
+	blocks, err = store.GetDescendantBlocks(&s1, b3)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{b3, b4}, blockIDs(blocks))
+
+	blocks, err = store.GetDescendantBlocks(&s1, s1)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{s1, b1, b2, b3, b5}, blockIDs(blocks))
+
+	_, err = store.GetDescendantBlocks(&s1, b6)
+	assert.ErrorAs(t, err, &blocktree.ErrBlockNotFound{})
+}
+
+func testQueries(t *testing.T, store blocktree.Store) {
+	api := blocktree.NewApi(store)
+	createSpaces(t, store, s1)
+	apply(t, api, tx(s1,
+		insertOp(b1, "p1", s1, blocktree.PositionEnd),
+		insertOp(b2, "p2", b1, blocktree.PositionEnd),
+		insertOp(b3, "p3", b2, blocktree.PositionEnd),
+		insertOp(b4, "page", b1, blocktree.PositionEnd),
+		insertOp(b5, "p5", b4, blocktree.PositionEnd),
+		insertOp(b6, "p6", s1, blocktree.PositionEnd),
+	))
+
+	descendants := func(query blocktree.BlockQuery) [][]blocktree.BlockID {
+		return queryPages(t, query, func(query *blocktree.BlockQuery) (*blocktree.BlockPage, error) {
+			return store.QueryDescendantBlocks(&s1, s1, query)
+		})
+	}
+
+	assert.Equal(t, [][]blocktree.BlockID{{s1, b1, b2, b3, b4, b6}}, descendants(blocktree.BlockQuery{}))
+	assert.Equal(t, [][]blocktree.BlockID{{s1, b1}, {b2, b3}, {b4, b6}}, descendants(blocktree.BlockQuery{Limit: 2}))
+	assert.Equal(t, [][]blocktree.BlockID{{s1, b1, b6}}, descendants(blocktree.BlockQuery{MaxDepth: 1}))
+	assert.Equal(t, [][]blocktree.BlockID{{s1, b1, b2, b4}, {b6}}, descendants(blocktree.BlockQuery{MaxDepth: 2, Limit: 4}))
+
+	// the page block is expanded when it is the query root
+	page, err := store.QueryDescendantBlocks(&s1, b4, &blocktree.BlockQuery{Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{b4}, blockIDs(page.Blocks))
+	page, err = store.QueryDescendantBlocks(&s1, b4, &blocktree.BlockQuery{Start: page.Next})
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{b5}, blockIDs(page.Blocks))
+	assert.Empty(t, page.Next)
+
+	// the next page starts at the next block even when the block at the cursor is gone
+	page, err = store.QueryDescendantBlocks(&s1, s1, &blocktree.BlockQuery{Limit: 2})
+	require.NoError(t, err)
+	apply(t, api, tx(s1, moveOp(b2, b1, b6, blocktree.PositionEnd)))
+	page, err = store.QueryDescendantBlocks(&s1, s1, &blocktree.BlockQuery{Start: page.Next})
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{b4, b6, b2, b3}, blockIDs(page.Blocks))
+
+	children := queryPages(t, blocktree.BlockQuery{Limit: 1}, func(query *blocktree.BlockQuery) (*blocktree.BlockPage, error) {
+		return store.QueryChildrenBlocks(&s1, b1, query)
+	})
+	assert.Equal(t, [][]blocktree.BlockID{{b4}}, children)
+	children = queryPages(t, blocktree.BlockQuery{Limit: 1}, func(query *blocktree.BlockQuery) (*blocktree.BlockPage, error) {
+		return store.QueryChildrenBlocks(&s1, s1, query)
+	})
+	assert.Equal(t, [][]blocktree.BlockID{{b1}, {b6}}, children)
+
+	_, err = store.QueryChildrenBlocks(&s1, s1, &blocktree.BlockQuery{Start: "not a cursor"})
+	assert.Error(t, err)
+}
+
+func testAncestorEdges(t *testing.T, store blocktree.Store) {
+	api := blocktree.NewApi(store)
+	createSpaces(t, store, s1)
+	apply(t, api, tx(s1,
+		insertOp(b1, "p1", s1, blocktree.PositionEnd),
+		insertOp(b2, "p2", b1, blocktree.PositionEnd),
+		insertOp(b3, "p3", b2, blocktree.PositionEnd),
+		insertOp(b4, "p4", s1, blocktree.PositionEnd),
+	))
+
+	edges, err := store.GetAncestorEdges(&s1, []blocktree.BlockID{b3})
+	require.NoError(t, err)
+	assert.Len(t, edges, 3)
+
+	edges, err = store.GetAncestorEdges(&s1, []blocktree.BlockID{b3, b4})
+	require.NoError(t, err)
+	assert.Len(t, edges, 4)
+
+	edges, err = store.GetAncestorEdges(&s1, []blocktree.BlockID{s1})
+	require.NoError(t, err)
+	assert.Empty(t, edges)
+
+	_, err = store.GetAncestorEdges(&s1, []blocktree.BlockID{b5})
+	assert.ErrorIs(t, err, blocktree.ErrNotFound, "missing block should not have ancestors")
+	var notFound blocktree.ErrBlockNotFound
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, b5, notFound.ID)
+}
+
+func testLinks(t *testing.T, store blocktree.Store) {
+	api := blocktree.NewApi(store)
+	createSpaces(t, store, s1)
+	apply(t, api,
+		tx(s1,
+			insertOp(b1, "p1", s1, blocktree.PositionEnd),
+			insertOp(b2, "p2", s1, blocktree.PositionEnd),
+		),
+		tx(s1, linkInsertOp(b3, "l1", b1)),
+		tx(s1, linkOp(b3, b2)),
+	)
+
+	blocks, err := store.GetLinkedBlocks(&s1, b2)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{b3}, blockIDs(blocks))
+
+	blocks, err = store.GetLinkedBlocks(&s1, b1)
+	require.NoError(t, err)
+	assert.Empty(t, blocks)
+
+	blocks, err = store.GetBackLinks(&s1, b3)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{b2}, blockIDs(blocks))
+
+	apply(t, api, tx(s1, unlinkOp(b3)))
+
+	blocks, err = store.GetBackLinks(&s1, b3)
+	require.NoError(t, err)
+	assert.Empty(t, blocks)
+
+	blocks, err = store.GetLinkedBlocks(&s1, b2)
+	require.NoError(t, err)
+	assert.Empty(t, blocks)
+}
+
+func testTransactions(t *testing.T, store blocktree.Store) {
+	api := blocktree.NewApi(store)
+	createSpaces(t, store, s1)
+
+	tx1 := tx(s1, insertOp(b1, "p1", s1, blocktree.PositionEnd))
+	tx2 := tx(s1, insertOp(b2, "p2", s1, blocktree.PositionEnd))
+	tx2.Jitter = []byte{0x01, 0x02}
+	tx3 := tx(s1, moveOp(b1, s1, b2, blocktree.PositionStart))
+	apply(t, api, tx1, tx2, tx3)
+
+	got, err := store.GetTransaction(&s1, tx2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, tx2.ID, got.ID)
+	assert.Equal(t, tx2.Ops, got.Ops)
+	assert.Equal(t, tx2.Jitter, got.Jitter)
+
+	missing := uuid.New()
+	_, err = store.GetTransaction(&s1, missing)
+	assert.Equal(t, blocktree.ErrTransactionNotFound{ID: missing}, err, "missing transaction should not be found")
+
+	latest, err := store.GetLatestTransaction(&s1)
+	require.NoError(t, err)
+	assert.Equal(t, tx3.ID, latest.ID)
+
+	// the blocks take the seq of the last transaction that changed them or their children
+	for id, version := range map[blocktree.BlockID]uint64{s1: 3, b1: 1, b2: 3} {
+		block, err := store.GetBlock(&s1, id)
+		require.NoError(t, err)
+		assert.Equal(t, version, block.Version, id)
+	}
+
+	txs, err := store.GetNextTransactions(&s1, uuid.Nil, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{tx1.ID, tx2.ID, tx3.ID}, transactionIDs(txs))
+
+	txs, err = store.GetNextTransactions(&s1, tx1.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{tx2.ID, tx3.ID}, transactionIDs(txs))
+
+	txs, err = store.GetNextTransactions(&s1, uuid.Nil, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{tx2.ID}, transactionIDs(txs))
+
+	txs, err = store.GetNextTransactions(&s1, tx3.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, txs)
+
+	// the transactions are numbered in the order they are logged, the initial transaction is zero
+	txs, err = store.GetTransactionsSince(&s1, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{tx1.ID, tx2.ID, tx3.ID}, transactionIDs(txs))
+	assert.Equal(t, []uint64{1, 2, 3}, []uint64{txs[0].Seq, txs[1].Seq, txs[2].Seq})
+	assert.NotZero(t, txs[0].HLC)
+	assert.Less(t, txs[0].HLC, txs[1].HLC)
+	assert.Less(t, txs[1].HLC, txs[2].HLC)
+
+	txs, err = store.GetTransactionsSince(&s1, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{tx2.ID}, transactionIDs(txs))
+
+	txs, err = store.GetTransactionsSince(&s1, 3, 10)
+	require.NoError(t, err)
+	assert.Empty(t, txs)
+
+	// applying a transaction twice is a no-op
+	apply(t, api, tx2)
+	txs, err = store.GetNextTransactions(&s1, uuid.Nil, 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, txs, 3)
+
+	updates, err := api.GetUpdates(s1, tx1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{b1}, updates.Children[b2])
+	assert.Equal(t, []blocktree.BlockID{b2}, updates.Children[s1])
+	assert.Contains(t, updates.Blocks, b2)
+
+	// the inverse ops are stored with the transaction
+	inverse, err := api.Invert(s1, tx3.ID)
+	require.NoError(t, err)
+	require.Len(t, inverse.Ops, 1)
+	assert.Equal(t, blocktree.OpTypeMove, inverse.Ops[0].Type)
+	assert.Equal(t, &blocktree.Pointer{BlockID: s1, Position: blocktree.PositionStart}, inverse.Ops[0].At)
+
+	apply(t, api, inverse)
+	children, err := store.GetChildrenBlockIDs(&s1, s1)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{b1, b2}, children)
+}
+
+func testAtomic(t *testing.T, store blocktree.Store) {
+	api := blocktree.NewApi(store)
+	createSpaces(t, store, s1)
+	tx1 := tx(s1, insertOp(b1, "p1", s1, blocktree.PositionEnd))
+	apply(t, api, tx1)
+
+	// the writes of a failed fn are rolled back with the logged transaction
+	failed := errors.New("failed")
+	err := store.Atomic(s1, func(store blocktree.Store) error {
+		_, err := blocktree.NewApi(store).Apply(tx(s1, insertOp(b2, "p2", s1, blocktree.PositionEnd)))
+		require.NoError(t, err)
+		return failed
+	})
+	assert.ErrorIs(t, err, failed)
+
+	_, err = store.GetBlock(&s1, b2)
+	assert.ErrorAs(t, err, &blocktree.ErrBlockNotFound{})
+	children, err := store.GetChildrenBlockIDs(&s1, s1)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{b1}, children)
+	latest, err := store.GetLatestTransaction(&s1)
+	require.NoError(t, err)
+	assert.Equal(t, tx1.ID, latest.ID)
+
+	tx2 := tx(s1, insertOp(b2, "p2", s1, blocktree.PositionEnd))
+	err = store.Atomic(s1, func(store blocktree.Store) error {
+		_, err := blocktree.NewApi(store).Apply(tx2)
+		return err
+	})
+	require.NoError(t, err)
+	children, err = store.GetChildrenBlockIDs(&s1, s1)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{b1, b2}, children)
+
+	// a late move that fails leaves the undone later moves in place
+	tx3 := tx(s1, moveOp(b2, s1, b1, blocktree.PositionEnd))
+	tx3.Clock = 10
+	apply(t, api, tx3)
+	late := tx(s1, moveOp(b1, s1, b2, blocktree.PositionEnd), insertOp(b1, "p1", s1, blocktree.PositionEnd))
+	late.Clock = 5
+	_, err = api.Apply(late)
+	assert.Error(t, err)
+
+	children, err = store.GetChildrenBlockIDs(&s1, b1)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{b2}, children)
+	latest, err = store.GetLatestTransaction(&s1)
+	require.NoError(t, err)
+	assert.Equal(t, tx3.ID, latest.ID)
+	assert.Equal(t, uint64(3), latest.Seq)
+}
+
+func testCompaction(t *testing.T, store blocktree.Store) {
+	api := blocktree.NewApi(store)
+	createSpaces(t, store, s1)
+
+	snapshot, err := store.GetSnapshot(&s1)
+	require.NoError(t, err)
+	assert.Nil(t, snapshot, "a new space has no snapshot")
+
+	tx1 := tx(s1, insertOp(b1, "p1", s1, blocktree.PositionEnd))
+	tx2 := tx(s1, insertOp(b2, "p2", b1, blocktree.PositionEnd))
+	tx3 := tx(s1, insertOp(b3, "p3", s1, blocktree.PositionEnd))
+	apply(t, api, tx1, tx2, tx3)
+
+	snapshot, err = api.SnapshotAt(s1, tx2.ID)
+	require.NoError(t, err)
+	require.NoError(t, store.PutSnapshot(snapshot))
+
+	got, err := store.GetSnapshot(&s1)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, tx2.ID, got.TransactionID)
+	assertSameBlocks(t, snapshot.Blocks, got.Blocks)
+
+	// the transactions before the snapshot are dropped
+	_, err = store.GetTransaction(&s1, tx1.ID)
+	assert.Error(t, err)
+	txs, err := store.GetNextTransactions(&s1, tx2.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{tx3.ID}, transactionIDs(txs))
+
+	_, err = api.GetUpdates(s1, tx1.ID)
+	assert.ErrorIs(t, err, blocktree.ErrSnapshotRequired)
+	_, err = api.GetUpdatesSince(s1, 1)
+	assert.ErrorIs(t, err, blocktree.ErrSnapshotRequired)
+
+	updates, err := api.GetUpdatesSince(s1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, tx3.ID, updates.TransactionID)
+	assert.Equal(t, uint64(3), updates.Seq)
+
+	blocks, err := api.GetDescendantsAt(s1, s1, tx3.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []blocktree.BlockID{s1, b1, b2, b3}, blockIDs(blocks))
+}
+
+// testSequence applies the transactions to the store and a reference MemStore
+// and checks that both end up with the same tree.
+func testSequence(t *testing.T, store blocktree.Store, txs []*blocktree.Transaction) {
+	reference := blocktree.NewMemStore()
+	createSpaces(t, reference, s1)
+	createSpaces(t, store, s1)
+
+	refApi := blocktree.NewApi(reference)
+	api := blocktree.NewApi(store)
+	for _, tx := range txs {
+		_, refErr := refApi.Apply(tx)
+		_, err := api.Apply(tx)
+		assert.Equal(t, refErr != nil, err != nil, "transaction %v: reference error %v, store error %v", tx.ID, refErr, err)
+	}
+
+	expected, err := reference.GetDescendantBlocks(&s1, s1)
+	require.NoError(t, err)
+	actual, err := store.GetDescendantBlocks(&s1, s1)
+	require.NoError(t, err)
+	assertSameBlocks(t, expected, actual)
+
+	for _, block := range expected {
+		refChildren, err := reference.GetChildrenBlocks(&s1, block.ID)
+		require.NoError(t, err)
+		children, err := store.GetChildrenBlocks(&s1, block.ID)
+		require.NoError(t, err)
+		assert.Equal(t, blockIDs(refChildren), blockIDs(children), "children of %v", block.ID)
+	}
+
+	// the pages of the descendants follow the order of the reference tree
+	pages := queryPages(t, blocktree.BlockQuery{Limit: 2}, func(query *blocktree.BlockQuery) (*blocktree.BlockPage, error) {
+		return store.QueryDescendantBlocks(&s1, s1, query)
+	})
+	ids := make([]blocktree.BlockID, 0, len(expected))
+	for _, page := range pages {
+		ids = append(ids, page...)
+	}
+	assert.Equal(t, blockIDs(expected), ids)
+}
+
+type sequence struct {
+	name string
+	txs  func() []*blocktree.Transaction
+}
+
+var sequences = []sequence{
+	{
+		name: "InsertPositions",
+		txs: func() []*blocktree.Transaction {
+			return []*blocktree.Transaction{
+				tx(s1,
+					insertOp(b1, "page", s1, blocktree.PositionStart),
+					insertOp(b2, "title", b1, blocktree.PositionStart),
+					insertOp(b3, "p1", b2, blocktree.PositionAfter),
+					insertOp(b4, "p2", b3, blocktree.PositionBefore),
+				),
+				tx(s1, insertOp(b5, "p3", b1, blocktree.PositionEnd)),
+				tx(s1, insertOp(b6, "p4", b2, blocktree.PositionBefore)),
+				tx(s1, insertOp(b7, "p5", b6, blocktree.PositionAfter)),
+			}
+		},
+	},
+	{
+		name: "Moves",
+		txs: func() []*blocktree.Transaction {
+			return append(fiveBlocks(),
+				tx(s1,
+					moveOp(b1, s1, b2, blocktree.PositionStart),
+					moveOp(b3, s1, b2, blocktree.PositionEnd),
+				),
+				tx(s1,
+					moveOp(b4, s1, b1, blocktree.PositionAfter),
+					moveOp(b5, s1, b3, blocktree.PositionBefore),
+				),
+				tx(s1, moveOp(b1, b2, b2, blocktree.PositionAfter)),
+			)
+		},
+	},
+	{
+		name: "Cycles",
+		txs: func() []*blocktree.Transaction {
+			return append(fiveBlocks(),
+				tx(s1, moveOp(b1, s1, b2, blocktree.PositionStart)),
+				tx(s1, moveOp(b2, s1, b1, blocktree.PositionStart)),
+				tx(s1,
+					moveOp(b3, s1, b4, blocktree.PositionStart),
+					moveOp(b4, s1, b3, blocktree.PositionStart),
+				),
+			)
+		},
+	},
+	{
+		name: "PropsAndPatches",
+		txs: func() []*blocktree.Transaction {
+			return append(fiveBlocks(),
+				tx(s1, updateOp(b1, `[{"op":"add","path":"/name","value":"John Doe"}]`)),
+				tx(s1,
+					updateOp(b1, `[{"op":"replace","path":"/name","value":"Jane Doe"}]`),
+					updateOp(b2, `[{"op":"add","path":"/age","value":20}]`),
+				),
+				tx(s1, patchOp(b3, `[{"op":"add","path":"/text","value":"hello"}]`)),
+				tx(s1, patchOp(b3, `[{"op":"add","path":"/marks","value":[1,2]}]`)),
+			)
+		},
+	},
+	{
+		name: "DeleteAndErase",
+		txs: func() []*blocktree.Transaction {
+			return append(fiveBlocks(),
+				tx(s1, deleteOp(b1), eraseOp(b2)),
+				tx(s1, deleteOp(b3)),
+				tx(s1, op(blocktree.OpTypeUndelete, b3), op(blocktree.OpTypeRestore, b2)),
+			)
+		},
+	},
+	{
+		name: "Links",
+		txs: func() []*blocktree.Transaction {
+			return append(fiveBlocks(),
+				tx(s1,
+					linkInsertOp(b6, "l1", b2),
+					insertOp(b7, "p7", b6, blocktree.PositionEnd),
+				),
+				tx(s1, linkOp(b6, b3)),
+				tx(s1, unlinkOp(b6)),
+			)
+		},
+	},
+	{
+		name: "InvalidTransaction",
+		txs: func() []*blocktree.Transaction {
+			return append(fiveBlocks(),
+				tx(s1, insertOp(b6, "p6", b7, blocktree.PositionAfter)),
+				tx(s1, moveOp(b7, s1, b1, blocktree.PositionEnd)),
+				tx(s1, insertOp(b6, "p6", b1, blocktree.PositionEnd)),
+			)
+		},
+	},
+}
+
+func fiveBlocks() []*blocktree.Transaction {
+	return []*blocktree.Transaction{
+		tx(s1,
+			insertOp(b1, "p1", s1, blocktree.PositionEnd),
+			insertOp(b2, "p2", s1, blocktree.PositionEnd),
+			insertOp(b3, "p3", s1, blocktree.PositionEnd),
+			insertOp(b4, "p4", s1, blocktree.PositionEnd),
+			insertOp(b5, "p5", s1, blocktree.PositionEnd),
+		),
+	}
+}
+
+func createSpaces(t *testing.T, store blocktree.Store, spaceIDs ...blocktree.SpaceID) {
+	for _, spaceID := range spaceIDs {
+		err := store.CreateSpace(&blocktree.Space{ID: spaceID, Name: spaceID.String()})
+		require.NoError(t, err)
+	}
+}
+
+func apply(t *testing.T, api *blocktree.Api, txs ...*blocktree.Transaction) {
+	for _, tx := range txs {
+		_, err := api.Apply(tx)
+		require.NoError(t, err)
+	}
+}
+
+// blockState is the comparable state of a block.
+type blockState struct {
+	ID       blocktree.BlockID
+	ParentID blocktree.ParentID
+	Type     string
+	Index    []byte
+	Props    string
+	Json     string
+	Deleted  bool
+	Erased   bool
+	Linked   bool
+}
+
+func assertSameBlocks(t *testing.T, expected, actual []*blocktree.Block) {
+	assert.Equal(t, blockStates(expected), blockStates(actual))
+}
+
+func blockStates(blocks []*blocktree.Block) []blockState {
+	states := make([]blockState, 0, len(blocks))
+	for _, block := range blocks {
+		state := blockState{
+			ID:       block.ID,
+			ParentID: block.ParentID,
+			Type:     block.Type,
+			Props:    block.Props.String(),
+			Json:     block.Json.String(),
+			Deleted:  block.Deleted,
+			Erased:   block.Erased,
+			Linked:   block.Linked,
+		}
+		if block.Index != nil {
+			state.Index = block.Index.Bytes()
+		}
+		states = append(states, state)
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		return bytes.Compare(states[i].ID[:], states[j].ID[:]) < 0
+	})
+
+	return states
+}
+
+// queryPages follows the next cursors of the query and returns the block ids of each page
+func queryPages(t *testing.T, query blocktree.BlockQuery, fn func(query *blocktree.BlockQuery) (*blocktree.BlockPage, error)) [][]blocktree.BlockID {
+	pages := make([][]blocktree.BlockID, 0)
+	for {
+		page, err := fn(&query)
+		require.NoError(t, err)
+		pages = append(pages, blockIDs(page.Blocks))
+		if page.Next == "" {
+			return pages
+		}
+		require.Less(t, len(pages), 100, "the pages should end")
+		query.Start = page.Next
+	}
+}
+
+func blockIDs(blocks []*blocktree.Block) []blocktree.BlockID {
+	ids := make([]blocktree.BlockID, len(blocks))
+	for i, block := range blocks {
+		ids[i] = block.ID
+	}
+	return ids
+}
+
+func transactionIDs(txs []*blocktree.Transaction) []uuid.UUID {
+	ids := make([]uuid.UUID, len(txs))
+	for i, tx := range txs {
+		ids[i] = tx.ID
+	}
+	return ids
+}
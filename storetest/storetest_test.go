@@ -0,0 +1,64 @@
+package storetest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/emrgen/blocktree"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMemStore(t *testing.T) {
+	Run(t, func() blocktree.Store {
+		return blocktree.NewMemStore()
+	})
+}
+
+func TestGormStore(t *testing.T) {
+	Run(t, func() blocktree.Store {
+		db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "blocktree.db")), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		store := blocktree.NewGormStore(db)
+		err = store.Migrate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return store
+	})
+}
+
+func TestSqliteStore(t *testing.T) {
+	Run(t, func() blocktree.Store {
+		store, err := blocktree.NewSqliteStore(filepath.Join(t.TempDir(), "blocktree.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			_ = store.Close()
+		})
+
+		return store
+	})
+}
+
+func TestBadgerStore(t *testing.T) {
+	Run(t, func() blocktree.Store {
+		store, err := blocktree.NewBadgerStore(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			_ = store.Close()
+		})
+
+		return store
+	})
+}
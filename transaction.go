@@ -1,6 +1,7 @@
 package blocktree
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"time"
@@ -10,12 +11,6 @@ import (
 	"github.com/google/uuid"
 )
 
-var (
-	ErrCreatesCycle    = fmt.Errorf("operation creates cycle")
-	ErrDetectedCycle   = fmt.Errorf("existing cycle detected")
-	ErrFailedToPublish = fmt.Errorf("failed to publish sync blocks")
-)
-
 type TransactionID = uuid.UUID
 
 // Transaction is a collection of Ops that are applied to a Space.
@@ -24,8 +19,41 @@ type Transaction struct {
 	SpaceID SpaceID
 	UserID  uuid.UUID
 	Time    time.Time
+	// Clock is the Lamport clock of the transaction, the moves are ordered by clock and then by ID.
+	// the api stamps the transactions without a clock with the next clock of the space.
+	Clock uint64
+	// Seq is the position of the transaction in the space log, the store assigns it when the transaction is logged.
+	// the seq of the initial transaction of a space is zero.
+	Seq uint64
+	// HLC is the hybrid logical clock timestamp the api stamps the transaction with when it is applied
+	HLC HLC
+	// Precondition is the state of the space the transaction is based on, the api rejects the transaction
+	// with a ConflictError once the state changed
+	Precondition *Precondition
+	// Jitter is the suffix of the indices of the blocks the transaction places, nil places them without one.
+	// the api that jitters the indices sets it from the end of the transaction id, it is logged with the
+	// transaction so the replays and the replicas place the blocks at the same indices.
+	Jitter  []byte
 	Ops     []Op
 	changes *SyncBlocks
+	// inverse holds the ops that revert the transaction, recorded when it is applied
+	inverse []Op
+	// skip holds the indexes of the move ops that are skipped because they create a cycle
+	skip map[int]bool
+	// unlogged transactions change the blocks without being stored in the transaction log
+	unlogged bool
+	// schemas are the block schemas the ops are checked against, nil skips the check
+	schemas *SchemaRegistry
+}
+
+// jitterLength is the number of transaction id bytes that suffix the jittered indices
+const jitterLength = 4
+
+// stampJitter sets the jitter of a transaction without one from the end of its id
+func (tx *Transaction) stampJitter() {
+	if tx.Jitter == nil {
+		tx.Jitter = bytes.Clone(tx.ID[len(tx.ID)-jitterLength:])
+	}
 }
 
 // prepare prepares the transaction for application to the store.
@@ -41,26 +69,32 @@ func (tx *Transaction) prepare(store Store) (*storeChange, error) {
 			tx:            tx,
 		}, nil
 	}
-	//transaction, err := store.GetLatestTransaction(&tx.SpaceID)
-	//if err != nil {
-	//	return nil, err
-	//}
-	//if transaction != nil && transaction.Version >= tx.Version {
-	//	return nil, fmt.Errorf("transaction already applied")
-	//}
-
 	//check if transaction is valid (no cycles, etc)
 
 	if tx.Ops == nil || len(tx.Ops) == 0 {
-		return nil, errors.New("transaction has no ops")
+		return nil, fmt.Errorf("%w: transaction has no ops", ErrInvalidOp)
+	}
+	if len(tx.Jitter) > jitterLength {
+		return nil, fmt.Errorf("%w: transaction jitter is longer than %d bytes", ErrInvalidOp, jitterLength)
+	}
+
+	// a transaction with every move skipped is stored without changes
+	if len(tx.ops()) == 0 {
+		return &storeChange{
+			blockChange:   newBlockChange(),
+			jsonDocChange: nil,
+			tx:            tx,
+		}, nil
 	}
 
 	// load the referenced blocks
-	existingBlockIDs, _ := tx.relevantBlockIDs()
-	if cycle, err := tx.createsCycles(store, existingBlockIDs); err != nil {
+	existingBlockIDs, insertedBlockIDs := tx.relevantBlockIDs()
+	if err := tx.createsCycles(store, existingBlockIDs); err != nil {
+		return nil, err
+	}
+
+	if err := tx.checkInserted(store, insertedBlockIDs); err != nil {
 		return nil, err
-	} else if cycle {
-		return nil, fmt.Errorf("transaction creates cycles")
 	}
 
 	relevantBlocks, err := store.GetBlocks(&tx.SpaceID, existingBlockIDs.ToSlice())
@@ -70,7 +104,16 @@ func (tx *Transaction) prepare(store Store) (*storeChange, error) {
 
 	if len(relevantBlocks) != existingBlockIDs.Size() {
 		logrus.Infof("relevant blocks: %v", existingBlockIDs.ToSlice())
-		return nil, fmt.Errorf("cannot find all referenced blocks")
+		found := NewSet[BlockID]()
+		for _, block := range relevantBlocks {
+			found.Add(block.ID)
+		}
+		for _, id := range existingBlockIDs.ToSlice() {
+			if !found.Contains(id) {
+				return nil, fmt.Errorf("cannot find all referenced blocks: %w", ErrBlockNotFound{ID: id})
+			}
+		}
+		return nil, fmt.Errorf("%w: cannot find all referenced blocks", ErrNotFound)
 	}
 	stage := newStageTable()
 	//logrus.Infof("relevant blocks: %v", existingBlockIDs.ToSlice())
@@ -79,16 +122,16 @@ func (tx *Transaction) prepare(store Store) (*storeChange, error) {
 	}
 
 	// check and load relevant blocks from the store to the stage
-	for _, op := range tx.Ops {
+	for _, op := range tx.ops() {
 		switch {
 		case op.Type == OpTypeInsert:
 			if op.At == nil {
-				return nil, fmt.Errorf("invalid create op without at: %v", op)
+				return nil, fmt.Errorf("%w: invalid create op without at: %v", ErrInvalidPosition, op)
 			}
 
 			//check if block has type prop
 			if op.Object == "" {
-				return nil, fmt.Errorf("invalid create op without type: %v", op)
+				return nil, fmt.Errorf("%w: create op without type: %v", ErrInvalidOp, op)
 			}
 
 			switch {
@@ -106,7 +149,7 @@ func (tx *Transaction) prepare(store Store) (*storeChange, error) {
 						return nil, err
 					}
 					if len(blocks) < 2 {
-						return nil, fmt.Errorf("cannot find referenced block for insert after/before: %v", op)
+						return nil, fmt.Errorf("referenced block for insert after/before: %w", ErrBlockNotFound{ID: op.At.BlockID})
 					}
 					for _, block := range blocks {
 						stage.add(block)
@@ -132,7 +175,7 @@ func (tx *Transaction) prepare(store Store) (*storeChange, error) {
 						return nil, err
 					}
 					if len(blocks) < 1 {
-						return nil, fmt.Errorf("cannot find referenced block for insert start/end: %v", op)
+						return nil, fmt.Errorf("referenced block for insert start/end: %w", ErrBlockNotFound{ID: op.At.BlockID})
 					}
 					for _, block := range blocks {
 						stage.add(block)
@@ -150,31 +193,41 @@ func (tx *Transaction) prepare(store Store) (*storeChange, error) {
 						return nil, err
 					}
 					if len(blocks) < 1 {
-						return nil, fmt.Errorf("cannot find referenced block for linking: %v", op)
+						return nil, fmt.Errorf("referenced block for linking: %w", ErrBlockNotFound{ID: op.At.BlockID})
 					}
 					for _, block := range blocks {
 						stage.add(block)
 					}
+					// the linked block is placed after the last child
+					if _, ok := stage.parked(op.At.BlockID); !ok {
+						blocks, err = store.GetWithLastChildBlock(&tx.SpaceID, op.At.BlockID)
+						if err != nil {
+							return nil, err
+						}
+						for _, block := range blocks {
+							stage.add(block)
+						}
+					}
 					block, err := op.IntoBlock(op.At.BlockID)
 					if err != nil {
 						return nil, err
 					}
 					stage.park(block)
 				} else {
-					return nil, fmt.Errorf("cannot insert inside a block: %v", op)
+					return nil, fmt.Errorf("%w: cannot insert inside a block: %v", ErrInvalidPosition, op)
 				}
 			}
 		case op.Type == OpTypeMove:
 			if op.ParentID == nil {
-				return nil, fmt.Errorf("invalid move op without parent id: %v", op)
+				return nil, fmt.Errorf("%w: move op without parent id: %v", ErrInvalidOp, op)
 			}
 
 			if op.At == nil {
-				return nil, fmt.Errorf("invalid move op without at: %v", op)
+				return nil, fmt.Errorf("%w: invalid move op without at: %v", ErrInvalidPosition, op)
 			}
 
 			if op.At.BlockID == op.BlockID {
-				return nil, fmt.Errorf("invalid move op with same block id: %v", op)
+				return nil, fmt.Errorf("%w: move op with same block id: %v", ErrInvalidOp, op)
 			}
 
 			// load blocks old parent
@@ -185,7 +238,7 @@ func (tx *Transaction) prepare(store Store) (*storeChange, error) {
 			var parent *Block
 			if ok {
 				if parked.ParentID == uuid.Nil {
-					return nil, fmt.Errorf("newly inserted block has no parent id set: %v", op)
+					return nil, fmt.Errorf("%w: newly inserted block has no parent id set: %v", ErrInvalidOp, op)
 				}
 				parkedParent, ok := stage.parked(parked.ParentID)
 				if ok {
@@ -205,6 +258,19 @@ func (tx *Transaction) prepare(store Store) (*storeChange, error) {
 
 			stage.add(parent)
 
+			// the inverse move puts a stored block back after its previous sibling
+			if !ok && !stage.hasPrevSibling(op.BlockID) {
+				blocks, err := store.GetParentWithPrevBlock(&tx.SpaceID, op.BlockID)
+				if err != nil {
+					return nil, err
+				}
+				var prev *Block
+				if len(blocks) > 2 {
+					prev = blocks[2]
+				}
+				stage.setPrevSibling(op.BlockID, prev)
+			}
+
 			if _, ok := stage.parked(op.At.BlockID); ok {
 				continue
 			}
@@ -217,14 +283,15 @@ func (tx *Transaction) prepare(store Store) (*storeChange, error) {
 				}
 
 				if len(blocks) < 2 {
-					return nil, fmt.Errorf("cannot find referenced block for move after/before: %v", op)
+					return nil, fmt.Errorf("referenced block for move after/before: %w", ErrBlockNotFound{ID: op.At.BlockID})
 				}
 				for _, block := range blocks {
 					stage.add(block)
 				}
 
-				_, ok := stage.parked(op.BlockID)
-				if ok {
+				// the moved block is already loaded with the referenced blocks,
+				// a stub would hide its index and flags
+				if stage.contains(op.BlockID) {
 					continue
 				}
 
@@ -237,23 +304,39 @@ func (tx *Transaction) prepare(store Store) (*storeChange, error) {
 				}
 
 				if len(blocks) < 1 {
-					return nil, fmt.Errorf("cannot find referenced block for move start/end: %v", op)
+					return nil, fmt.Errorf("referenced block for move start/end: %w", ErrBlockNotFound{ID: op.At.BlockID})
 				}
 				for _, block := range blocks {
 					stage.add(block)
 				}
 
-				_, ok := stage.parked(op.BlockID)
-				if ok {
+				// the moved block is already loaded with the referenced blocks,
+				// a stub would hide its index and flags
+				if stage.contains(op.BlockID) {
 					continue
 				}
 
 				block := NewBlock(op.BlockID, parent.ID, "")
 				stage.add(block)
 			case op.At.Position == PositionInside:
-				return nil, fmt.Errorf("cannot move inside a block: %v", op)
+				if op.Index == nil {
+					return nil, fmt.Errorf("%w: cannot move inside a block: %v", ErrInvalidPosition, op)
+				}
+				if !validIndex(op.Index) {
+					return nil, fmt.Errorf("%w: invalid index for move inside: %v", ErrInvalidOp, op)
+				}
+
+				// the block is reindexed among its siblings, the siblings keep their index
+				if stage.contains(op.BlockID) {
+					continue
+				}
+				block, err := store.GetBlock(&tx.SpaceID, op.BlockID)
+				if err != nil {
+					return nil, err
+				}
+				stage.add(block)
 			}
-		case op.Type == OpTypeUpdate || op.Type == OpTypePatch || op.Type == OpTypeDelete || op.Type == OpTypeErase || op.Type == OpTypeUndelete || op.Type == OpTypeRestore:
+		case op.Type == OpTypeUpdate || op.Type == OpTypePatch || op.Type == OpTypeText || op.Type == OpTypeDelete || op.Type == OpTypeErase || op.Type == OpTypeUndelete || op.Type == OpTypeRestore:
 			if ok := stage.contains(op.BlockID); ok {
 				continue
 			}
@@ -262,7 +345,7 @@ func (tx *Transaction) prepare(store Store) (*storeChange, error) {
 				return nil, err
 			}
 			if len(blocks) < 1 {
-				return nil, fmt.Errorf("cannot find referenced block for update: %v", op)
+				return nil, fmt.Errorf("referenced block for update: %w", ErrBlockNotFound{ID: op.BlockID})
 			}
 			for _, block := range blocks {
 				stage.add(block)
@@ -284,6 +367,9 @@ func (tx *Transaction) prepare(store Store) (*storeChange, error) {
 				return nil, err
 			}
 			stage.add(block)
+		case op.Type == opTypePlace:
+			// the placed block and its new parent are loaded with the relevant blocks
+			continue
 		}
 	}
 
@@ -293,6 +379,13 @@ func (tx *Transaction) prepare(store Store) (*storeChange, error) {
 		return nil, err
 	}
 
+	if tx.schemas != nil {
+		err = tx.schemas.check(store, tx, stage)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &storeChange{
 		blockChange:   change,
 		jsonDocChange: nil,
@@ -305,7 +398,7 @@ func (tx *Transaction) relevantBlockIDs() (*Set[BlockID], *Set[BlockID]) {
 	relevant := NewSet[uuid.UUID]()
 	inserted := NewSet[uuid.UUID]()
 
-	for _, op := range tx.Ops {
+	for _, op := range tx.ops() {
 		logrus.Debugf("op: %v", op)
 		if op.Type == OpTypeInsert {
 			if op.At != nil {
@@ -330,8 +423,50 @@ func (tx *Transaction) relevantBlockIDs() (*Set[BlockID], *Set[BlockID]) {
 	return relevant, inserted
 }
 
+// checkInserted returns ErrDuplicate when a block inserted by the transaction exists or is inserted twice
+func (tx *Transaction) checkInserted(store Store, insertedBlockIDs *Set[BlockID]) error {
+	inserts := 0
+	for _, op := range tx.ops() {
+		if op.Type == OpTypeInsert {
+			inserts++
+		}
+	}
+	if inserts != insertedBlockIDs.Size() {
+		return fmt.Errorf("%w: transaction %v inserts a block twice", ErrDuplicate, tx.ID)
+	}
+	if inserts == 0 {
+		return nil
+	}
+
+	blocks, err := store.GetBlocks(&tx.SpaceID, insertedBlockIDs.ToSlice())
+	if err != nil {
+		return err
+	}
+	if len(blocks) > 0 {
+		return ErrBlockExists{ID: blocks[0].ID}
+	}
+
+	return nil
+}
+
+// ops returns the ops applied to the blocks, the skipped moves are left out
+func (tx *Transaction) ops() []Op {
+	if len(tx.skip) == 0 {
+		return tx.Ops
+	}
+
+	ops := make([]Op, 0, len(tx.Ops))
+	for i, op := range tx.Ops {
+		if !tx.skip[i] {
+			ops = append(ops, op)
+		}
+	}
+
+	return ops
+}
+
 func (tx *Transaction) moves() bool {
-	for _, op := range tx.Ops {
+	for _, op := range tx.ops() {
 		if op.Type == OpTypeMove {
 			return true
 		}
@@ -340,80 +475,83 @@ func (tx *Transaction) moves() bool {
 	return false
 }
 
-// createsCycles returns true if the transaction creates cycles in the blocktree
-func (tx *Transaction) createsCycles(store Store, blockIDs *Set[BlockID]) (bool, error) {
+// reindexes reports whether the transaction only changes the indices of blocks among their siblings
+func (tx *Transaction) reindexes() bool {
+	for _, op := range tx.Ops {
+		if op.Type != OpTypeMove || op.Index == nil {
+			return false
+		}
+	}
+
+	return len(tx.Ops) > 0
+}
+
+// createsCycles returns an error if the transaction creates cycles in the blocktree
+func (tx *Transaction) createsCycles(store Store, blockIDs *Set[BlockID]) error {
 	if !tx.moves() {
-		return false, nil
+		return nil
 	}
 
-	// load all relevant blocks
-	blockEdges, err := store.GetAncestorEdges(&tx.SpaceID, blockIDs.ToSlice())
+	moveTree, err := tx.moveTree(store, blockIDs)
 	if err != nil {
-		return false, err
+		return err
 	}
 
-	moveTree := newMoveTree(tx.SpaceID)
-	for _, edge := range blockEdges {
-		moveTree.addEdge(edge.childID, edge.parentID)
+	for _, op := range tx.ops() {
+		err := moveTree.apply(&op)
+		if err != nil {
+			return err
+		}
 	}
 
-	for _, op := range tx.Ops {
-		switch {
-		case op.Type == OpTypeInsert:
-			if op.At == nil {
-				return false, fmt.Errorf("invalid create op without at: %v", op)
-			}
-			switch {
-			case op.At.Position == PositionAfter || op.At.Position == PositionBefore:
-				parentID, ok := moveTree.getParent(op.At.BlockID)
-				if !ok {
-					return false, fmt.Errorf("cannot find parent for insert after/before: %v", op)
-				}
-				moveTree.addEdge(op.BlockID, *parentID)
-			case op.At.Position == PositionStart || op.At.Position == PositionEnd:
-				if !moveTree.contains(op.At.BlockID) {
-					return true, nil
-				}
-				moveTree.addEdge(op.BlockID, op.At.BlockID)
-			case op.At.Position == PositionInside:
-				return false, fmt.Errorf("cannot insert inside a block: %v", op)
-			}
-		case op.Type == OpTypeMove:
-			if op.At == nil {
-				return false, fmt.Errorf("invalid move op without at: %v", op)
-			}
-			if op.At.BlockID == op.BlockID {
-				return false, fmt.Errorf("invalid move op with same block id: %v", op)
-			}
+	return nil
+}
 
-			switch {
-			case op.At.Position == PositionAfter || op.At.Position == PositionBefore:
-				parentID, ok := moveTree.getParent(op.At.BlockID)
-				if !ok {
-					return false, fmt.Errorf("cannot find parent for move after/before: %v", op)
-				}
-				err := moveTree.move(op.BlockID, *parentID)
-				if err != nil {
-					if errors.Is(ErrDetectedCycle, err) {
-						return true, nil
-					}
-					return false, err
-				}
-			case op.At.Position == PositionStart || op.At.Position == PositionEnd:
-				err := moveTree.move(op.BlockID, op.At.BlockID)
-				if err != nil {
-					if errors.Is(ErrDetectedCycle, err) {
-						return true, nil
-					}
-					return false, err
-				}
-			case op.At.Position == PositionInside:
-				continue
-			}
+// cyclicMoves returns the indexes of the move ops that create a cycle when the ops are applied in order,
+// the reindex moves of a block that is no longer a child of the At block are skipped too.
+// a skipped move leaves the tree as it is for the ops after it.
+func (tx *Transaction) cyclicMoves(store Store) (map[int]bool, error) {
+	tx.skip = nil
+	skip := make(map[int]bool)
+	if !tx.moves() {
+		return skip, nil
+	}
+
+	blockIDs, _ := tx.relevantBlockIDs()
+	moveTree, err := tx.moveTree(store, blockIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range tx.Ops {
+		op := &tx.Ops[i]
+		err := moveTree.apply(op)
+		if op.Type == OpTypeMove && (errors.Is(err, ErrCreatesCycle) || errors.Is(err, ErrDetectedCycle) || errors.Is(err, errMovedAway)) {
+			logrus.Debugf("skipped move of block %v in transaction %v: %v", op.BlockID, tx.ID, err)
+			skip[i] = true
+			continue
+		}
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	return false, nil
+	return skip, nil
+}
+
+// moveTree loads the blocks with their ancestors into a new move tree
+func (tx *Transaction) moveTree(store Store, blockIDs *Set[BlockID]) (*moveTree, error) {
+	blockEdges, err := store.GetAncestorEdges(&tx.SpaceID, blockIDs.ToSlice())
+	if err != nil {
+		return nil, err
+	}
+
+	moveTree := newMoveTree(tx.SpaceID)
+	for _, edge := range blockEdges {
+		moveTree.addEdge(edge.childID, edge.parentID)
+	}
+
+	return moveTree, nil
 }
 
 func (tx *Transaction) loadRelevantBlocks(store Store, op *Op) ([]*Block, error) {
@@ -464,9 +602,62 @@ func (tx *Transaction) loadRelevantBlocks(store Store, op *Op) ([]*Block, error)
 		relevantBlocks = append(relevantBlocks, block)
 	}
 
+	if (op.Type == OpTypeInsert || op.Type == OpTypeMove) && op.At.Position != PositionInside {
+		blocks, err := tx.loadMovedNeighbours(store, op, relevantBlocks)
+		if err != nil {
+			return nil, err
+		}
+		relevantBlocks = append(relevantBlocks, blocks...)
+	}
+
 	return relevantBlocks, nil
 }
 
+// loadMovedNeighbours loads the blocks past the neighbours moved away by the transaction.
+// the new index is computed from the neighbour that is still next to the position when the op is applied.
+func (tx *Transaction) loadMovedNeighbours(store Store, op *Op, blocks []*Block) ([]*Block, error) {
+	var neighbour *Block
+	switch op.At.Position {
+	case PositionStart, PositionEnd:
+		if len(blocks) > 1 {
+			neighbour = blocks[1]
+		}
+	case PositionBefore, PositionAfter:
+		if len(blocks) > 2 {
+			neighbour = blocks[2]
+		}
+	}
+
+	moved := NewSet[BlockID]()
+	for _, op := range tx.ops() {
+		if op.Type == OpTypeMove {
+			moved.Add(op.BlockID)
+		}
+	}
+
+	loaded := make([]*Block, 0)
+	for neighbour != nil && moved.Contains(neighbour.ID) {
+		var next []*Block
+		var err error
+		if op.At.Position == PositionStart || op.At.Position == PositionAfter {
+			next, err = store.GetParentWithNextBlock(&tx.SpaceID, neighbour.ID)
+		} else {
+			next, err = store.GetParentWithPrevBlock(&tx.SpaceID, neighbour.ID)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		neighbour = nil
+		if len(next) > 2 {
+			neighbour = next[2]
+			loaded = append(loaded, neighbour)
+		}
+	}
+
+	return loaded, nil
+}
+
 // OpType is the type of operation
 type OpType string
 
@@ -475,12 +666,15 @@ const (
 	OpTypeMove     OpType = "move"
 	OpTypeUpdate   OpType = "update" // update properties of a block
 	OpTypePatch    OpType = "patch"  // patch json document of a block
+	OpTypeText     OpType = "text"   // edit a collaborative text in the json document of a block, the patch is a TextPatch
 	OpTypeLink     OpType = "link"
 	OpTypeUnlink   OpType = "unlink"
 	OpTypeDelete   OpType = "delete"
 	OpTypeUndelete OpType = "undelete"
 	OpTypeErase    OpType = "erase"
 	OpTypeRestore  OpType = "restore"
+	// opTypePlace puts a block back at its exact parent and index to undo its moves, it is never stored
+	opTypePlace OpType = "place"
 )
 
 type PointerPosition string
@@ -519,21 +713,24 @@ type Op struct {
 	At       *Pointer `json:"at"`
 	Props    []byte   `json:"props"`
 	Patch    []byte   `json:"patch"`
+	// Index is the exact index of a block moved inside the At block, the block must already be a child of the At block.
+	// the rebalance moves only change the order keys of the children, see Api.RebalanceChildren
+	Index []byte `json:"index,omitempty"`
 }
 
 // IntoBlock converts the operation into a block object
 func (op *Op) IntoBlock(parentID ParentID) (*Block, error) {
 	if op.Type != OpTypeInsert {
-		return nil, fmt.Errorf("op is not a insert op")
+		return nil, fmt.Errorf("%w: op is not a insert op", ErrInvalidOp)
 	}
 
 	// insert op must have a block object
 	if op.Object == "" {
-		return nil, fmt.Errorf("insert op is missing block object type")
+		return nil, fmt.Errorf("%w: insert op is missing block object type", ErrInvalidOp)
 	}
 
 	if op.Table == "" {
-		return nil, fmt.Errorf("insert op is missing table")
+		return nil, fmt.Errorf("%w: insert op is missing table", ErrInvalidOp)
 	}
 
 	jsonDoc := DefaultJsonDoc()
@@ -126,7 +126,7 @@ func createTx(spaceID uuid.UUID, ops ...Op) *Transaction {
 	}
 }
 
-func createSpace(store *MemStore, spaceID uuid.UUID) error {
+func createSpace(store Store, spaceID uuid.UUID) error {
 	space := &Space{
 		ID:   spaceID,
 		Name: "test-space",
@@ -157,7 +157,7 @@ func TestInsertOp(t *testing.T) {
 	})
 
 	// apply the change to the store
-	err = store.Apply(tx, changes)
+	_, err = store.Apply(tx, changes)
 	assert.NoError(t, err)
 
 	block, err := store.GetBlock(&s1, b1)
@@ -257,7 +257,7 @@ func TestInsertAfterOp(t *testing.T) {
 	//store.Print(&s1)
 }
 
-func prepareSpace(store *MemStore, spaceID uuid.UUID) error {
+func prepareSpace(store Store, spaceID uuid.UUID) error {
 	var err error
 	err = createSpace(store, s1)
 	if err != nil {
@@ -277,11 +277,11 @@ func prepareSpace(store *MemStore, spaceID uuid.UUID) error {
 	}
 
 	changes, _ := tx.prepare(store)
-	err = store.Apply(tx, changes)
+	_, err = store.Apply(tx, changes)
 	return err
 }
 
-func applyTransaction(t *testing.T, store *MemStore, tx *Transaction) {
+func applyTransaction(t *testing.T, store Store, tx *Transaction) {
 	changes, err := tx.prepare(store)
 	assert.NoError(t, err)
 
@@ -289,7 +289,7 @@ func applyTransaction(t *testing.T, store *MemStore, tx *Transaction) {
 		return changes != nil
 	})
 
-	err = store.Apply(tx, changes)
+	_, err = store.Apply(tx, changes)
 	assert.NoError(t, err)
 }
 
@@ -402,7 +402,7 @@ func TestBlockLink(t *testing.T) {
 	change, err := tx.prepare(store)
 	assert.NoError(t, err)
 
-	err = store.Apply(tx, change)
+	_, err = store.Apply(tx, change)
 	assert.NoError(t, err)
 
 	space, err := store.getSpace(&s1)
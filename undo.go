@@ -0,0 +1,195 @@
+package blocktree
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNothingToUndo is returned when the user has no transaction to undo in the space
+	ErrNothingToUndo = fmt.Errorf("nothing to undo")
+	// ErrNothingToRedo is returned when the user has no undone transaction to redo in the space
+	ErrNothingToRedo = fmt.Errorf("nothing to redo")
+	// ErrNotInvertible is returned when the transaction was stored without its inverse ops
+	ErrNotInvertible = fmt.Errorf("transaction is not invertible")
+)
+
+// historyKey identifies the undo history of a user in a space
+type historyKey struct {
+	spaceID SpaceID
+	userID  uuid.UUID
+}
+
+// historyDepth is the most transactions a user can undo or redo in a space, the oldest ones are dropped
+const historyDepth = 100
+
+// history keeps the undo and redo stacks of the users, the stacks hold transaction ids.
+type history struct {
+	mu    sync.Mutex
+	undos map[historyKey][]TransactionID
+	redos map[historyKey][]TransactionID
+}
+
+func newHistory() *history {
+	return &history{
+		undos: make(map[historyKey][]TransactionID),
+		redos: make(map[historyKey][]TransactionID),
+	}
+}
+
+// record pushes a new transaction of the user, the redo stack is cleared.
+func (h *history) record(tx *Transaction) {
+	if tx.UserID == uuid.Nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := historyKey{spaceID: tx.SpaceID, userID: tx.UserID}
+	h.undos[key] = capped(append(h.undos[key], tx.ID))
+	delete(h.redos, key)
+}
+
+func (h *history) push(stacks map[historyKey][]TransactionID, key historyKey, txID TransactionID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stacks[key] = capped(append(stacks[key], txID))
+}
+
+// prune drops the transactions of the space that are no longer kept, e.g. the ones removed by a compaction
+func (h *history) prune(spaceID SpaceID, kept func(txID TransactionID) bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, stacks := range []map[historyKey][]TransactionID{h.undos, h.redos} {
+		for key, stack := range stacks {
+			if key.spaceID != spaceID {
+				continue
+			}
+
+			pruned := make([]TransactionID, 0, len(stack))
+			for _, txID := range stack {
+				if kept(txID) {
+					pruned = append(pruned, txID)
+				}
+			}
+			if len(pruned) == 0 {
+				delete(stacks, key)
+			} else {
+				stacks[key] = pruned
+			}
+		}
+	}
+}
+
+// capped drops the oldest transactions of the stack above the history depth
+func capped(stack []TransactionID) []TransactionID {
+	if len(stack) <= historyDepth {
+		return stack
+	}
+
+	return append([]TransactionID{}, stack[len(stack)-historyDepth:]...)
+}
+
+func (h *history) pop(stacks map[historyKey][]TransactionID, key historyKey) (TransactionID, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stack := stacks[key]
+	if len(stack) == 0 {
+		return uuid.Nil, false
+	}
+
+	txID := stack[len(stack)-1]
+	stacks[key] = stack[:len(stack)-1]
+	return txID, true
+}
+
+// Invert returns a new transaction that reverts the stored transaction.
+// the inverse is recorded when the transaction is applied, the genesis transaction has none.
+func (a *Api) Invert(spaceID SpaceID, txID TransactionID) (*Transaction, error) {
+	tx, err := a.store.GetTransaction(&spaceID, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tx.inverse) == 0 {
+		return nil, fmt.Errorf("%w: %v", ErrNotInvertible, txID)
+	}
+
+	ops := make([]Op, len(tx.inverse))
+	copy(ops, tx.inverse)
+
+	return &Transaction{
+		ID:      uuid.New(),
+		SpaceID: spaceID,
+		UserID:  tx.UserID,
+		Time:    time.Now(),
+		Ops:     ops,
+	}, nil
+}
+
+// Undo reverts the last transaction of the user in the space and returns the applied inverse transaction.
+func (a *Api) Undo(spaceID SpaceID, userID uuid.UUID) (*Transaction, error) {
+	key := historyKey{spaceID: spaceID, userID: userID}
+	txID, ok := a.history.pop(a.history.undos, key)
+	if !ok {
+		return nil, ErrNothingToUndo
+	}
+
+	inverse, err := a.applyInverse(spaceID, txID)
+	if err != nil {
+		a.history.push(a.history.undos, key, txID)
+		return nil, err
+	}
+
+	a.history.push(a.history.redos, key, inverse.ID)
+	return inverse, nil
+}
+
+// Redo applies again the last transaction undone by the user in the space.
+func (a *Api) Redo(spaceID SpaceID, userID uuid.UUID) (*Transaction, error) {
+	key := historyKey{spaceID: spaceID, userID: userID}
+	txID, ok := a.history.pop(a.history.redos, key)
+	if !ok {
+		return nil, ErrNothingToRedo
+	}
+
+	inverse, err := a.applyInverse(spaceID, txID)
+	if err != nil {
+		a.history.push(a.history.redos, key, txID)
+		return nil, err
+	}
+
+	a.history.push(a.history.undos, key, inverse.ID)
+	return inverse, nil
+}
+
+// applyInverse applies the inverse of the transaction without recording it in the history.
+func (a *Api) applyInverse(spaceID SpaceID, txID TransactionID) (*Transaction, error) {
+	inverse, err := a.Invert(spaceID, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	// apply skips the moves that create cycles, an inverse that cannot put every block back is not applied
+	skip, err := inverse.cyclicMoves(a.store)
+	if err != nil {
+		return nil, err
+	}
+	if len(skip) > 0 {
+		return nil, fmt.Errorf("%w: inverse of %v", ErrCreatesCycle, txID)
+	}
+
+	_, err = a.apply(false, inverse)
+	if err != nil {
+		return nil, err
+	}
+
+	return inverse, nil
+}
@@ -0,0 +1,250 @@
+package blocktree
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func userTx(spaceID, userID uuid.UUID, ops ...Op) *Transaction {
+	tx := createTx(spaceID, ops...)
+	tx.UserID = userID
+	return tx
+}
+
+func childIDs(t *testing.T, api *Api, spaceID, parentID uuid.UUID) []uuid.UUID {
+	blocks, err := api.GetChildrenBlocks(spaceID, parentID)
+	assert.NoError(t, err)
+
+	ids := make([]uuid.UUID, 0, len(blocks))
+	for _, block := range blocks {
+		ids = append(ids, block.ID)
+	}
+	return ids
+}
+
+func TestApi_InvertInsert(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	tx := createTx(s1, insertOp(b1, "p1", s1, PositionEnd), insertOp(b2, "p2", b1, PositionEnd))
+	_, err = api.Apply(tx)
+	assert.NoError(t, err)
+
+	inverse, err := api.Invert(s1, tx.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []Op{
+		{Table: "block", Type: OpTypeErase, BlockID: b2},
+		{Table: "block", Type: OpTypeErase, BlockID: b1},
+	}, inverse.Ops)
+
+	_, err = api.Apply(inverse)
+	assert.NoError(t, err)
+
+	block, err := api.GetBlock(s1, b1)
+	assert.NoError(t, err)
+	assert.True(t, block.Erased)
+}
+
+func TestApi_InvertMove(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	_, err = api.Apply(createTx(s1,
+		insertOp(b1, "p1", s1, PositionEnd),
+		insertOp(b2, "p2", s1, PositionEnd),
+		insertOp(b3, "p3", s1, PositionEnd),
+		insertOp(b4, "p4", s1, PositionEnd),
+	))
+	assert.NoError(t, err)
+
+	tx := createTx(s1, moveOp(b3, s1, b4, PositionStart), moveOp(b1, s1, b3, PositionAfter))
+	_, err = api.Apply(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{b2, b4}, childIDs(t, api, s1, s1))
+	assert.Equal(t, []uuid.UUID{b3, b1}, childIDs(t, api, s1, b4))
+
+	inverse, err := api.Invert(s1, tx.ID)
+	assert.NoError(t, err)
+	_, err = api.Apply(inverse)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []uuid.UUID{b1, b2, b3, b4}, childIDs(t, api, s1, s1))
+	assert.Empty(t, childIDs(t, api, s1, b4))
+}
+
+func TestApi_InvertUpdateAndPatch(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	_, err = api.Apply(createTx(s1,
+		insertOp(b1, "p1", s1, PositionEnd),
+		updateOp(b1, []byte(`[{"op":"add","path":"/name","value":"John Doe"}]`)),
+		patchOp(b1, []byte(`[{"op":"add","path":"/text","value":"hello"}]`)),
+	))
+	assert.NoError(t, err)
+
+	tx := createTx(s1,
+		updateOp(b1, []byte(`[{"op":"replace","path":"/name","value":"Jane Doe"},{"op":"add","path":"/age","value":20}]`)),
+		patchOp(b1, []byte(`[{"op":"replace","path":"/text","value":"world"}]`)),
+		deleteOp(b1),
+	)
+	_, err = api.Apply(tx)
+	assert.NoError(t, err)
+
+	inverse, err := api.Invert(s1, tx.ID)
+	assert.NoError(t, err)
+	_, err = api.Apply(inverse)
+	assert.NoError(t, err)
+
+	block, err := api.GetBlock(s1, b1)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"John Doe"}`, block.Props.String())
+	assert.Equal(t, `{"text":"hello"}`, string(block.Json.Content))
+	assert.False(t, block.Deleted)
+}
+
+func TestApi_InvertLink(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	_, err = api.Apply(createTx(s1,
+		insertOp(b1, "p1", s1, PositionEnd),
+		insertOp(b2, "p2", s1, PositionEnd),
+	))
+	assert.NoError(t, err)
+
+	tx := createTx(s1, linkOp(b2, b1))
+	_, err = api.Apply(tx)
+	assert.NoError(t, err)
+
+	inverse, err := api.Invert(s1, tx.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []Op{{Table: "block", Type: OpTypeUnlink, BlockID: b2}}, inverse.Ops)
+
+	_, err = api.Apply(inverse)
+	assert.NoError(t, err)
+
+	links, err := api.GetLinkedBlocks(s1, b1)
+	assert.NoError(t, err)
+	assert.Empty(t, links)
+}
+
+func TestApi_InvertGenesis(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	_, err = api.Invert(s1, uuid.Nil)
+	assert.ErrorIs(t, err, ErrNotInvertible)
+}
+
+func TestApi_UndoRedo(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	u1 := uuid.New()
+	u2 := uuid.New()
+
+	_, err = api.Apply(userTx(s1, u1, insertOp(b1, "p1", s1, PositionEnd)))
+	assert.NoError(t, err)
+	_, err = api.Apply(userTx(s1, u2, insertOp(b2, "p2", s1, PositionEnd)))
+	assert.NoError(t, err)
+	_, err = api.Apply(userTx(s1, u1, updateOp(b1, []byte(`[{"op":"add","path":"/name","value":"John Doe"}]`))))
+	assert.NoError(t, err)
+
+	// the undo of a user does not touch the changes of the other users
+	_, err = api.Undo(s1, u1)
+	assert.NoError(t, err)
+	block, err := api.GetBlock(s1, b1)
+	assert.NoError(t, err)
+	assert.Equal(t, `{}`, block.Props.String())
+
+	_, err = api.Undo(s1, u1)
+	assert.NoError(t, err)
+	block, err = api.GetBlock(s1, b1)
+	assert.NoError(t, err)
+	assert.True(t, block.Erased)
+	block, err = api.GetBlock(s1, b2)
+	assert.NoError(t, err)
+	assert.False(t, block.Erased)
+
+	_, err = api.Undo(s1, u1)
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+
+	_, err = api.Redo(s1, u1)
+	assert.NoError(t, err)
+	_, err = api.Redo(s1, u1)
+	assert.NoError(t, err)
+	block, err = api.GetBlock(s1, b1)
+	assert.NoError(t, err)
+	assert.False(t, block.Erased)
+	assert.Equal(t, `{"name":"John Doe"}`, block.Props.String())
+
+	_, err = api.Redo(s1, u1)
+	assert.ErrorIs(t, err, ErrNothingToRedo)
+
+	// a new change clears the redo stack
+	_, err = api.Undo(s1, u1)
+	assert.NoError(t, err)
+	_, err = api.Apply(userTx(s1, u1, deleteOp(b1)))
+	assert.NoError(t, err)
+	_, err = api.Redo(s1, u1)
+	assert.ErrorIs(t, err, ErrNothingToRedo)
+}
+
+func TestApi_UndoHistoryDepth(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	u1 := uuid.New()
+	_, err = api.Apply(userTx(s1, u1, insertOp(b1, "p1", s1, PositionEnd)))
+	assert.NoError(t, err)
+	for i := 0; i < historyDepth+10; i++ {
+		_, err = api.Apply(userTx(s1, u1, updateOp(b1, []byte(fmt.Sprintf(`[{"op":"add","path":"/n","value":%d}]`, i)))))
+		assert.NoError(t, err)
+	}
+
+	// only the latest changes of the user can be undone
+	for i := 0; i < historyDepth; i++ {
+		_, err = api.Undo(s1, u1)
+		assert.NoError(t, err)
+	}
+	_, err = api.Undo(s1, u1)
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+
+	block, err := api.GetBlock(s1, b1)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"n":9}`, block.Props.String())
+}
+
+func TestApi_UndoAfterCompact(t *testing.T) {
+	api := NewApi(NewMemStore())
+	err := api.CreateSpace(s1, "test-1")
+	assert.NoError(t, err)
+
+	u1 := uuid.New()
+	_, err = api.Apply(userTx(s1, u1, insertOp(b1, "p1", s1, PositionEnd)))
+	assert.NoError(t, err)
+	_, err = api.Apply(userTx(s1, u1, insertOp(b2, "p2", s1, PositionEnd)))
+	assert.NoError(t, err)
+	_, err = api.Compact(s1)
+	assert.NoError(t, err)
+
+	// the snapshot transaction stays in the log, the compacted one is dropped from the history
+	_, err = api.Undo(s1, u1)
+	assert.NoError(t, err)
+	block, err := api.GetBlock(s1, b2)
+	assert.NoError(t, err)
+	assert.True(t, block.Erased)
+	_, err = api.Undo(s1, u1)
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+}